@@ -9,16 +9,22 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
+	"github.com/wabisaby/wabisaby-node/internal/config"
 	"github.com/wabisaby/wabisaby-node/internal/container"
 	"go.uber.org/fx"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "upgrade" {
+		os.Exit(runUpgrade(os.Args[2:]))
+	}
+
+	var cfg *config.NodeConfig
 	app := fx.New(
 		fx.NopLogger,
 		container.NodeModule,
+		fx.Populate(&cfg),
 	)
 
 	if err := app.Err(); err != nil {
@@ -36,9 +42,14 @@ func main() {
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-	<-sigChan
+	// app.Done also fires when shutdown is requested through the admin API.
+	select {
+	case <-sigChan:
+	case <-app.Done():
+	}
 
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Leaves room for the agent to drain in-flight pins (shutdown.drain_timeout) and deregister.
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), container.ShutdownTimeout(cfg))
 	defer shutdownCancel()
 	if err := app.Stop(shutdownCtx); err != nil {
 		fmt.Fprintln(os.Stderr, "[node] shutdown error:", err)
@@ -0,0 +1,54 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/wabisaby/wabisaby-node/internal/config"
+	"github.com/wabisaby/wabisaby-node/internal/container"
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+)
+
+// runUpgrade implements `wabisaby-node upgrade`, which swaps the kubo binary used by the node for a
+// verified new one. The node must be stopped first. It returns the process exit code.
+func runUpgrade(args []string) int {
+	fs := flag.NewFlagSet("upgrade", flag.ContinueOnError)
+	binary := fs.String("binary", "", "path to the new kubo (ipfs) binary")
+	checksum := fs.String("sha256", "", "expected SHA-256 checksum of the new binary (hex)")
+	version := fs.String("version", "", "expected kubo version of the new binary, e.g. 0.30.0 (optional)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: wabisaby-node upgrade -binary <path> -sha256 <hex> [-version <x.y.z>]")
+		fmt.Fprintln(fs.Output(), "Stop the node before upgrading; its shutdown drains in-flight pins.")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *binary == "" || *checksum == "" {
+		fs.Usage()
+		return 2
+	}
+
+	cfg := config.LoadNodeConfig()
+	logger := container.ProvideNodeLogger(cfg)
+	manager := container.ProvideIPFSManager(cfg, logger)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := manager.Upgrade(ctx, ipfs.UpgradeOptions{
+		NewBinary:       *binary,
+		SHA256:          *checksum,
+		ExpectedVersion: *version,
+	}); err != nil {
+		fmt.Fprintln(os.Stderr, "[node] upgrade failed:", err)
+		return 1
+	}
+	return 0
+}
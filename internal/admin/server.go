@@ -0,0 +1,140 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package admin serves the node's admin HTTP API. Every endpoint requires the configured admin token
+// as a bearer token.
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/wabisaby/wabisaby-node/internal/httpserver"
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	"github.com/wabisaby/wabisaby-node/internal/stats"
+)
+
+// Config configures the admin API server.
+type Config struct {
+	ListenAddr string              // Address to listen on, e.g. "127.0.0.1:9102"
+	Token      string              // Bearer token required on every request
+	Timeouts   httpserver.Timeouts // Server timeouts
+	TLS        *tls.Config         // Serve HTTPS with this config when non-nil
+	Logger     *slog.Logger
+
+	// Stats is the source of GET /stats (the node's runtime statistics); the endpoint is not served if nil.
+	Stats func() stats.Snapshot
+
+	// Pins is the source of GET /pins?name=<prefix>: the inventory records whose pin name starts with the
+	// prefix, and false while the inventory is not loaded yet. The endpoint is not served if nil.
+	Pins func(namePrefix string) ([]inventory.Record, bool)
+}
+
+// Server is the admin API server.
+type Server struct {
+	cfg      Config
+	srv      *http.Server
+	shutdown func() error // Starts the node's graceful shutdown, as SIGTERM does
+	once     sync.Once    // Guards shutdown so concurrent requests trigger it once
+}
+
+// New returns an admin server. shutdown is called, at most once, by POST /shutdown; it must only
+// start the shutdown and return, not wait for it.
+func New(cfg Config, shutdown func() error) *Server {
+	s := &Server{cfg: cfg, shutdown: shutdown}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /shutdown", s.handleShutdown)
+	if cfg.Stats != nil {
+		mux.HandleFunc("GET /stats", s.handleStats)
+	}
+	if cfg.Pins != nil {
+		mux.HandleFunc("GET /pins", s.handlePins)
+	}
+	s.srv = httpserver.New(cfg.ListenAddr, s.authorize(mux), cfg.Timeouts)
+	return s
+}
+
+// Start listens on the configured address and serves in the background.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		return err
+	}
+	if s.cfg.TLS != nil {
+		ln = tls.NewListener(ln, s.cfg.TLS)
+	}
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.cfg.Logger.Error("admin API server stopped", "error", err)
+		}
+	}()
+	s.cfg.Logger.Info("admin API listening", "addr", ln.Addr().String(), "tls", s.cfg.TLS != nil)
+	return nil
+}
+
+// Stop gracefully shuts the server down, letting in-flight requests finish until ctx expires.
+func (s *Server) Stop(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+// authorize rejects requests that do not carry the admin token.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.Token)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handleStats writes the node's runtime statistics as JSON.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.cfg.Stats()); err != nil {
+		s.cfg.Logger.Warn("failed to write stats response", "error", err)
+	}
+}
+
+// handlePins writes the pinned content whose pin name starts with the name query parameter as a JSON
+// array; without name it lists every named pin.
+func (s *Server) handlePins(w http.ResponseWriter, r *http.Request) {
+	records, ok := s.cfg.Pins(r.URL.Query().Get("name"))
+	if !ok {
+		http.Error(w, "pin inventory not loaded yet", http.StatusServiceUnavailable)
+		return
+	}
+	if records == nil {
+		records = []inventory.Record{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(records); err != nil {
+		s.cfg.Logger.Warn("failed to write pins response", "error", err)
+	}
+}
+
+// handleShutdown starts the node's graceful shutdown. The response is written and flushed first, so
+// the caller gets it before teardown begins; repeated requests are answered the same way without
+// starting a second shutdown.
+func (s *Server) handleShutdown(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]string{"status": "shutting down"})
+	_ = http.NewResponseController(w).Flush()
+
+	s.once.Do(func() {
+		s.cfg.Logger.Info("shutdown requested via admin API", "remote", r.RemoteAddr)
+		if err := s.shutdown(); err != nil {
+			s.cfg.Logger.Error("failed to start shutdown", "error", err)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package admin
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	"github.com/wabisaby/wabisaby-node/internal/stats"
+)
+
+func TestStatsEndpoint(t *testing.T) {
+	collector := stats.NewCollector()
+	collector.TaskReceived()
+	collector.SetPeersByRegion(map[string]int{"eu-west": 2})
+	collector.PauseTasks("insufficient swarm peers")
+	s := New(Config{
+		Token:  "secret",
+		Stats:  collector.Snapshot,
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func() error { return nil })
+	srv := httptest.NewServer(s.srv.Handler)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/stats")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("without token: status %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/stats", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var snap stats.Snapshot
+	if err := json.NewDecoder(resp.Body).Decode(&snap); err != nil {
+		t.Fatal(err)
+	}
+	if snap.TasksReceived != 1 || snap.PeersByRegion["eu-west"] != 2 || !snap.TasksPaused {
+		t.Errorf("unexpected snapshot: %+v", snap)
+	}
+}
+
+func TestPinsEndpoint(t *testing.T) {
+	inv, err := inventory.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range []inventory.Record{
+		{CID: "bafy3", Name: "album/2"},
+		{CID: "bafy1", Name: "album/1"},
+		{CID: "bafy2", Name: "single"},
+		{CID: "bafy4"},
+	} {
+		if err := inv.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	loaded := false
+	s := New(Config{
+		Token: "secret",
+		Pins: func(prefix string) ([]inventory.Record, bool) {
+			if !loaded {
+				return nil, false
+			}
+			return inv.Named(prefix), true
+		},
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func() error { return nil })
+	srv := httptest.NewServer(s.srv.Handler)
+	defer srv.Close()
+
+	get := func(query string) (int, []string) {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/pins"+query, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return resp.StatusCode, nil
+		}
+		var records []inventory.Record
+		if err := json.NewDecoder(resp.Body).Decode(&records); err != nil {
+			t.Fatal(err)
+		}
+		cids := []string{}
+		for _, rec := range records {
+			cids = append(cids, rec.CID)
+		}
+		return resp.StatusCode, cids
+	}
+
+	if status, _ := get("?name=album"); status != http.StatusServiceUnavailable {
+		t.Fatalf("before the inventory is loaded: status %d, want %d", status, http.StatusServiceUnavailable)
+	}
+	loaded = true
+	for _, tt := range []struct {
+		query string
+		want  []string
+	}{
+		{"?name=album/", []string{"bafy1", "bafy3"}},
+		{"?name=single", []string{"bafy2"}},
+		{"", []string{"bafy1", "bafy2", "bafy3"}},
+		{"?name=nothing", []string{}},
+	} {
+		status, cids := get(tt.query)
+		if status != http.StatusOK || !slices.Equal(cids, tt.want) {
+			t.Errorf("GET /pins%s = %d %v, want %d %v", tt.query, status, cids, http.StatusOK, tt.want)
+		}
+	}
+}
+
+func TestShutdownEndpoint(t *testing.T) {
+	var shutdowns atomic.Int32
+	s := New(Config{
+		Token:  "secret",
+		Logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func() error {
+		shutdowns.Add(1)
+		return nil
+	})
+	srv := httptest.NewServer(s.srv.Handler)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/shutdown", "", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || shutdowns.Load() != 0 {
+		t.Fatalf("without token: status %d, %d shutdowns; want %d and none", resp.StatusCode, shutdowns.Load(), http.StatusUnauthorized)
+	}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodPost, srv.URL+"/shutdown", nil)
+			req.Header.Set("Authorization", "Bearer secret")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusAccepted {
+				t.Errorf("status %d, want %d", resp.StatusCode, http.StatusAccepted)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := shutdowns.Load(); n != 1 {
+		t.Fatalf("shutdown started %d times by concurrent requests, want once", n)
+	}
+}
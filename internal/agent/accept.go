@@ -0,0 +1,30 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"fmt"
+	"slices"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// declineReason returns why task does not match the configured acceptance rules, or "" if the node
+// accepts it. Tasks without a stated size are not subject to the size limit.
+func (a *Agent) declineReason(task *nodepb.PinTask) string {
+	if limit := a.config.AcceptMaxSizeBytes; limit > 0 && task.SizeBytes > limit {
+		return fmt.Sprintf("content size %d bytes exceeds the node's limit of %d bytes", task.SizeBytes, limit)
+	}
+	for _, label := range a.config.AcceptRequiredLabels {
+		if !slices.Contains(task.Labels, label) {
+			return fmt.Sprintf("task lacks required label %q", label)
+		}
+	}
+	for _, label := range a.config.AcceptExcludedLabels {
+		if slices.Contains(task.Labels, label) {
+			return fmt.Sprintf("task carries excluded label %q", label)
+		}
+	}
+	return ""
+}
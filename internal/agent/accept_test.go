@@ -0,0 +1,61 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+func TestDeclineReasonMaxSize(t *testing.T) {
+	a := &Agent{config: AgentConfig{AcceptMaxSizeBytes: 1000}}
+	tests := []struct {
+		size    int64
+		decline bool
+	}{
+		{0, false}, // Size not stated
+		{999, false},
+		{1000, false},
+		{1001, true},
+	}
+	for _, tt := range tests {
+		reason := a.declineReason(&nodepb.PinTask{TaskId: "t", SizeBytes: tt.size})
+		if (reason != "") != tt.decline {
+			t.Errorf("size %d: declineReason = %q, want decline %v", tt.size, reason, tt.decline)
+		}
+	}
+	if reason := (&Agent{}).declineReason(&nodepb.PinTask{SizeBytes: 1 << 50}); reason != "" {
+		t.Errorf("no size limit: declineReason = %q, want accepted", reason)
+	}
+}
+
+func TestDeclineReasonRequiredLabels(t *testing.T) {
+	a := &Agent{config: AgentConfig{AcceptRequiredLabels: []string{"music", "public"}}}
+	tests := []struct {
+		labels []string
+		want   string // Substring of the reason; empty means accepted
+	}{
+		{[]string{"music", "public", "extra"}, ""},
+		{[]string{"music"}, `required label "public"`},
+		{nil, `required label "music"`},
+	}
+	for _, tt := range tests {
+		reason := a.declineReason(&nodepb.PinTask{Labels: tt.labels})
+		if tt.want == "" && reason != "" || tt.want != "" && !strings.Contains(reason, tt.want) {
+			t.Errorf("labels %v: declineReason = %q, want %q", tt.labels, reason, tt.want)
+		}
+	}
+}
+
+func TestDeclineReasonExcludedLabels(t *testing.T) {
+	a := &Agent{config: AgentConfig{AcceptExcludedLabels: []string{"video"}}}
+	if reason := a.declineReason(&nodepb.PinTask{Labels: []string{"music"}}); reason != "" {
+		t.Errorf("declineReason = %q, want accepted", reason)
+	}
+	if reason := a.declineReason(&nodepb.PinTask{Labels: []string{"music", "video"}}); !strings.Contains(reason, `excluded label "video"`) {
+		t.Errorf("declineReason = %q, want declined for the excluded label", reason)
+	}
+}
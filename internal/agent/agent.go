@@ -4,68 +4,206 @@
 package agent
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
-	"math"
 	"net/http"
 	"net/url"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/wabisaby/wabisaby-node/internal/blocklist"
+	"github.com/wabisaby/wabisaby-node/internal/events"
+	"github.com/wabisaby/wabisaby-node/internal/health"
+	"github.com/wabisaby/wabisaby-node/internal/identity"
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
 	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+	"github.com/wabisaby/wabisaby-node/internal/logging"
+	"github.com/wabisaby/wabisaby-node/internal/stats"
+	"github.com/wabisaby/wabisaby-node/internal/taskstate"
 	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // Agent manages the communication and coordination between a storage node and the network coordinator.
 // It handles node registration, periodic heartbeats, polling and execution of pinning tasks,
 // and maintains status reporting logic.
 type Agent struct {
-	nodeID       string                       // Unique ID assigned by coordinator after registration
-	peerID       string                       // IPFS peer ID of this node
-	config       AgentConfig                  // Configuration for the Agent
-	client       nodepb.NodeCoordinatorClient // gRPC client for NodeCoordinator API
-	conn         *grpc.ClientConn             // Underlying gRPC connection
-	logger       *slog.Logger                 // Logger for agent events
-	ipfs         *ipfs.Client                 // Client for local IPFS API
-	ipfsManager  *ipfs.IPFSManager            // IPFS lifecycle manager
-	startTime    time.Time                    // Time when the agent started (for uptime tracking)
-	tokenMu      sync.RWMutex                 // protects currentToken and refreshToken
-	currentToken string                       // current JWT access token (refreshed in background when refresh is configured)
-	refreshToken string                       // Keycloak refresh token (updated when we get a new one from refresh)
+	nodeID        atomic.Pointer[string]       // Unique ID assigned by coordinator after registration; read with NodeID
+	peerID        string                       // IPFS peer ID of this node
+	config        AgentConfig                  // Configuration for the Agent
+	client        nodepb.NodeCoordinatorClient // gRPC client for NodeCoordinator API
+	conn          coordinatorConn              // Underlying gRPC connection, replaced on reconnect
+	advertised    atomic.Pointer[[]string]     // Multiaddrs sent at the last registration, reused when re-registering
+	reconnecting  atomic.Bool                  // A reconnect loop is running
+	reconnects    reconnectHistory             // Recent reconnect start times, for flap detection
+	logger        *slog.Logger                 // Logger for agent events
+	ipfs          *ipfs.Client                 // Client for local IPFS API
+	ipfsManager   *ipfs.IPFSManager            // IPFS lifecycle manager
+	events        *events.Notifier             // Webhook notifier for significant events
+	signer        *identity.Signer             // Signs pin status reports; nil when no identity key is configured
+	blocklist     *blocklist.Blocklist         // CIDs the node refuses to pin
+	inventory     *inventory.Inventory         // Pinned content with pin times and retention deadlines
+	invLoaded     chan struct{}                // Closed once inventory is loaded, for readers outside the agent (NamedPins)
+	taskState     taskstate.Store              // Last state of each pin task, kept across restarts
+	restoredPins  sync.Map                     // Task ID -> struct{}: content found pinned at startup, not pinned again
+	startTime     time.Time                    // Time when the agent started (for uptime tracking)
+	tokenMu       sync.RWMutex                 // protects currentToken, refreshToken, tokenExpires and tokenTTL
+	currentToken  string                       // current JWT access token (refreshed in background when refresh is configured)
+	refreshToken  string                       // Keycloak refresh token (updated when we get a new one from refresh)
+	tokenExpires  time.Time                    // When currentToken expires; zero for a static token
+	tokenTTL      time.Duration                // Lifetime of currentToken as issued
+	stats         *stats.Collector             // Runtime statistics shared with status/metrics readers
+	health        *health.Tracker              // Per-component health reported in heartbeats and on /readyz
+	capacityBytes atomic.Int64                 // Advertised storage capacity; updated by capacity re-detection
+	hbMinimal     atomic.Bool                  // Send only core heartbeat fields (configured or negotiated)
+	pinProgress   atomic.Bool                  // The coordinator accepts PIN_STATUS_PINNING progress reports
+	features      featureFlags                 // Coordinator-provided feature flags
+	compressReq   atomic.Bool                  // Gzip-compress coordinator requests (configured and accepted by the coordinator)
+	noRcmgr       bool                         // IPFS resource manager stats are not exposed; only accessed by heartbeatLoop
+	noAssignments atomic.Bool                  // The coordinator does not implement GetAssignedPins
+	unassigned    map[string]bool              // CIDs the last assignment reconciliation found unassigned; only accessed by reconcileAssignments
+	gatewayHTTP   *http.Client                 // HTTP client for gateway self-checks
+	ctx           context.Context              // Agent lifetime; canceled at the end of shutdown or when Start's context is canceled
+	cancel        context.CancelFunc           // Cancels ctx
+	taskLoops     *loopGroup                   // Task polling, reconcile and capacity loops; stopped first on shutdown
+	pins          *loopGroup                   // In-flight pin tasks; drained on shutdown
+	inflight      pinTracker                   // In-flight pins subject to the preemption policy
+	pinQueue      *pinQueue                    // Bounds concurrent pins and deduplicates redelivered task IDs
+	ipfsRestart   sync.RWMutex                 // Read-held by running pins; write-held while the IPFS daemon restarts
+	restartQueued atomic.Bool                  // An IPFS daemon restart is waiting for ipfsRestart or running
+	heartbeats    *loopGroup                   // Heartbeat loop; stopped after deregistration
+	hbMu          sync.Mutex                   // Serializes replacing the heartbeat loop
+	hbStop        func()                       // Stops the current heartbeat loop and waits for it; nil before the first start
+	lastBeat      atomic.Int64                 // Unix nanoseconds of the last heartbeat attempt
+	pollStarted   atomic.Int64                 // Unix nanoseconds when taskLoop first started polling; 0 before
+	gcRunning     atomic.Bool                  // A repo GC started by maybeCollectGarbage is running
+	lastGC        atomic.Int64                 // Unix nanoseconds of the last repo GC start
+	firstTask     sync.Once                    // Records time-to-first-task once per process
+	draining      atomic.Bool                  // Set when shutdown begins; newly polled tasks are released instead of started
+	stopOnce      sync.Once                    // Ensures the shutdown sequence runs once
+	stopErr       error                        // Result of the shutdown sequence
 }
 
 // AgentConfig encapsulates the configuration settings used to initialize an Agent.
 type AgentConfig struct {
-	CoordinatorAddr   string        // Network address of the coordinator gRPC endpoint
-	AuthToken         string        // JWT access token (optional if RefreshToken + KeycloakTokenURL are set)
-	RefreshToken      string        // Keycloak refresh token for automatic token refresh
-	KeycloakTokenURL  string        // Keycloak token endpoint for refresh
-	KeycloakClientID  string        // OIDC client id for refresh
-	IPFSAPIURL        string        // HTTP API base URL for local IPFS node
-	IPFSDataDir       string        // IPFS data directory
-	NodeName          string        // Human-readable name for this node
-	Region            string        // Region identifier for this node
-	WalletAddress     string        // Associated wallet address
-	CapacityBytes     int64         // Storage capacity of the node (in bytes)
-	HeartbeatInterval time.Duration // How often heartbeats are sent to coordinator
-	PollInterval      time.Duration // How often to poll for new tasks
+	CoordinatorAddr         string        // Network address of the coordinator gRPC endpoint
+	DialTimeout             time.Duration // Max time to wait for the coordinator connection at startup
+	LazyDial                bool          // If true, skip the eager connection check and connect on first RPC
+	TLSEnabled              bool          // Use TLS for the coordinator connection
+	TLSMinVersion           string        // Minimum TLS version ("1.2" or "1.3")
+	TLSCipherSuites         []string      // Optional cipher suite allowlist
+	TLSCAFile               string        // PEM CA bundle verifying the coordinator (system roots if empty)
+	TLSClientCertFile       string        // PEM client certificate for mutual TLS (optional)
+	TLSClientKeyFile        string        // PEM key for TLSClientCertFile
+	TLSServerName           string        // Overrides the host name the coordinator certificate is verified against
+	AuthToken               string        // JWT access token (optional if RefreshToken + KeycloakTokenURL are set)
+	RefreshToken            string        // Keycloak refresh token for automatic token refresh
+	KeycloakTokenURL        string        // Keycloak token endpoint for refresh
+	KeycloakClientID        string        // OIDC client id for refresh
+	IPFSAPIURL              string        // HTTP API base URL for local IPFS node
+	IPFSDataDir             string        // IPFS data directory
+	IPFSGatewayURL          string        // Optional read-only gateway URL advertised for retrieval routing
+	IPFSBreakerThreshold    int           // Consecutive failures that open an IPFS endpoint's circuit breaker (0 disables)
+	IPFSBreakerCooldown     time.Duration // How long an open IPFS circuit breaker rejects calls before probing
+	NodeName                string        // Human-readable name for this node (empty: derive from the IPFS peer ID)
+	NameSuffix              string        // Stable suffix strategy appended to NodeName at registration (none, peer_id, identity_key)
+	Region                  string        // Region identifier for this node
+	Group                   string        // Optional logical node group for coordinator placement policies
+	WalletAddress           string        // Associated wallet address
+	MaxMultiaddrs           int           // Cap on advertised multiaddrs (0 = unlimited)
+	RequireReachable        bool          // Refuse to register when AutoNAT reports the node as unreachable
+	ReachabilityTimeout     time.Duration // Max wait for AutoNAT to determine reachability before registering
+	MaxPeers                int           // Max coordinator peers to connect to (0 = all)
+	PreferSameRegion        bool          // Connect to peers in this node's region first
+	MinPeersForTasks        int           // Pause task polling while fewer swarm peers are connected (0 disables)
+	DiagnosePinFailures     bool          // Gather IPFS diagnostics when a pin fails
+	DiagnoseFindProviders   bool          // Include a (slow) DHT provider lookup in pin failure diagnostics
+	ReverifyAfter           time.Duration // Re-check a reported pin after this delay and correct the report if it vanished (0 disables)
+	IdentityKeyFile         string        // Ed25519 key for signing pin reports (signing disabled if empty)
+	CapacityBytes           int64         // Storage capacity of the node (in bytes)
+	StorageMedia            string        // Storage media type (ssd, hdd, nvme, network); empty if unknown
+	CapacityAutoDetect      bool          // Capacity was auto-detected and should be re-detected while running
+	CapacityRecheckInterval time.Duration // How often to re-detect capacity
+	CapacityChangeThreshold float64       // Relative change required before advertising a new capacity
+	GCHighWatermark         float64       // Run repo GC when the repo exceeds this fraction of its storage limit (0 disables)
+	GCMinInterval           time.Duration // Minimum time between repo GCs started by the watermark
+	HeartbeatInterval       time.Duration // How often heartbeats are sent to coordinator
+	HeartbeatGrace          time.Duration // Skip the immediate heartbeat of a (re)started loop if one was sent this recently
+	MaxHeartbeatBackoff     time.Duration // Cap on the heartbeat interval while heartbeats keep failing (0 = fixed interval)
+	ReconnectBaseDelay      time.Duration // First delay between coordinator reconnect attempts; doubles per attempt
+	ReconnectMaxDelay       time.Duration // Cap on the delay between coordinator reconnect attempts
+	KeepaliveTime           time.Duration // Ping the coordinator after this long without activity (0 disables)
+	KeepaliveTimeout        time.Duration // Close the coordinator connection when a ping is not answered within this
+	PollInterval            time.Duration // How often to poll for new tasks
+	MaxPollBackoff          time.Duration // Cap on coordinator-requested poll back-off (0 = uncapped)
+	MinimalHeartbeat        bool          // Send only the core heartbeat fields (node ID, storage, uptime)
+	RegisterFirst           bool          // Register before IPFS is up; tasks are accepted only once IPFS is ready
+	AckTasks                bool          // Acknowledge tasks on receipt so the coordinator suppresses redelivery
+	Compression             string        // Coordinator RPC compression: none or gzip
+	DisabledFeatures        []string      // Feature flags forced off regardless of the coordinator
+	MaxTaskAttempts         int           // Failed pin attempts per CID before reporting it abandoned (0 = unlimited)
+	MaxPinRetries           int           // Retries of a transiently failed pin, with backoff, before reporting it failed
+	MaxPinDuration          time.Duration // Preempt a pin running longer than this (0 disables)
+	PinTimeout              time.Duration // Fail a single pin attempt running longer than this; retried (0 = no limit)
+	MaxInflightPinTime      time.Duration // Preempt the oldest pins while in-flight pin time adds up to more than this (0 disables)
+	MaxConcurrentPins       int           // Pins run at once; further tasks wait for a free slot (0 = unlimited)
+	PinNames                bool          // Name IPFS pins after their task or coordinator-provided pin name
+	VerifyPins              bool          // Check the whole DAG is stored locally before reporting a pin as pinned
+	AcceptMaxSizeBytes      int64         // Decline tasks for content larger than this (0 = no limit)
+	AcceptRequiredLabels    []string      // Decline tasks missing any of these labels
+	AcceptExcludedLabels    []string      // Decline tasks carrying any of these labels
+	MinRegisterInterval     time.Duration // Minimum spacing between registration attempts across restarts
+	RegisterStateFile       string        // File recording the last registration attempt
+	PeerCache               bool          // Persist coordinator peers and fall back to them while the coordinator is unreachable
+	PeerCacheFile           string        // File holding the last peer list received from the coordinator
+	BlocklistFile           string        // Local CID blocklist file (optional)
+	BlocklistURL            string        // Remote CID blocklist URL (optional)
+	BlocklistRefresh        time.Duration // How often the blocklist is reloaded
+	InventoryFile           string        // File persisting the pin inventory (in-memory only if empty)
+	TaskStateFile           string        // File persisting pin task state across restarts (in-memory only if empty)
+	AlwaysPin               []string      // Operator-pinned CIDs exempt from retention expiry
+	ReconcileInterval       time.Duration // How often the pin inventory is reconciled (expired pins swept, assignments checked)
+	PeerRefreshInterval     time.Duration // How often coordinator peers are re-fetched and connected (0 disables)
+	ReconcileBatchSize      int           // Pins per batch of the pin scan
+	ReconcileBatchPause     time.Duration // Pause between pin scan batches
+	ReconcileVerifyPins     int           // Random inventory pins checked block by block per reconcile interval (0 disables)
+	ShutdownDrainTimeout    time.Duration // Max time to wait for in-flight pins during shutdown
+	DeregisterOnShutdown    bool          // Deregister from the coordinator during shutdown
 }
 
 // NewAgent creates a new storage node agent with the provided configuration and logger.
 // It does not perform any network operations or side effects.
-func NewAgent(cfg AgentConfig, ipfsManager *ipfs.IPFSManager, logger *slog.Logger) *Agent {
-	return &Agent{
+func NewAgent(cfg AgentConfig, ipfsManager *ipfs.IPFSManager, notifier *events.Notifier, collector *stats.Collector, logger *slog.Logger) *Agent {
+	a := &Agent{
 		config:      cfg,
 		ipfsManager: ipfsManager,
+		events:      notifier,
+		stats:       collector,
+		health:      health.NewTracker(),
+		pinQueue:    newPinQueue(cfg.MaxConcurrentPins),
 		logger:      logger,
+		gatewayHTTP: &http.Client{},
+		invLoaded:   make(chan struct{}),
 	}
+	a.capacityBytes.Store(cfg.CapacityBytes)
+	a.ctx, a.cancel = context.WithCancel(context.Background())
+	// The loop groups are independent of a.ctx so that shutdown can stop them one at a time.
+	a.taskLoops = newLoopGroup(context.Background())
+	a.pins = newLoopGroup(context.Background())
+	a.heartbeats = newLoopGroup(context.Background())
+	ipfsManager.SetDaemonCallbacks(a.onDaemonCrash, a.onDaemonRestart)
+	return a
 }
 
 // getAuthToken returns the current access token (thread-safe).
@@ -75,14 +213,28 @@ func (a *Agent) getAuthToken() string {
 	return a.currentToken
 }
 
-// setTokens updates current and optionally refresh token (thread-safe).
-func (a *Agent) setTokens(access, refresh string) {
+// setTokens updates current and optionally refresh token (thread-safe). expiresIn is the access token's
+// lifetime in seconds, 0 for a token that does not expire.
+func (a *Agent) setTokens(access, refresh string, expiresIn int) {
 	a.tokenMu.Lock()
 	defer a.tokenMu.Unlock()
 	a.currentToken = access
 	if refresh != "" {
 		a.refreshToken = refresh
 	}
+	a.tokenTTL = time.Duration(expiresIn) * time.Second
+	a.tokenExpires = time.Time{}
+	if expiresIn > 0 {
+		a.tokenExpires = time.Now().Add(a.tokenTTL)
+	}
+}
+
+// authContext returns ctx with the current access token attached as outgoing gRPC metadata.
+func (a *Agent) authContext(ctx context.Context) context.Context {
+	md := metadata.New(map[string]string{
+		"authorization": "Bearer " + a.getAuthToken(),
+	})
+	return metadata.NewOutgoingContext(ctx, md)
 }
 
 // tokenResponse is the JSON response from Keycloak token endpoint.
@@ -140,114 +292,367 @@ func (a *Agent) resolveInitialToken(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("fetch token: %w", err)
 		}
-		a.setTokens(access, newRefresh)
+		// Keycloak only returns a refresh token when it rotates them; keep using the configured one otherwise.
+		a.setTokens(access, cmp.Or(newRefresh, a.config.RefreshToken), expiresIn)
 		a.logger.Info("token obtained", "expires_in_sec", expiresIn)
 		return nil
 	}
 	if a.config.AuthToken != "" {
-		a.setTokens(a.config.AuthToken, "")
+		a.setTokens(a.config.AuthToken, "", 0)
 		return nil
 	}
 	return fmt.Errorf("auth token is required: set WABISABY_NODE_AUTH_TOKEN or auth.token, or use auth.refresh_token with auth.keycloak_token_url for automatic refresh")
 }
 
+// tokenRefreshLead is how long before the access token expires it is refreshed; tokens that live less
+// than twice as long are refreshed halfway through their lifetime.
+const tokenRefreshLead = time.Minute
+
+// Delays before retrying a failed token refresh, doubling per failure.
+const (
+	minTokenRetry = 5 * time.Second
+	maxTokenRetry = time.Minute
+)
+
 // startRefreshLoop starts a goroutine that refreshes the access token before it expires.
 func (a *Agent) startRefreshLoop(ctx context.Context) {
 	if a.config.KeycloakTokenURL == "" || a.config.RefreshToken == "" {
 		return
 	}
-	refreshInterval := 4 * time.Minute
-	go func() {
-		ticker := time.NewTicker(refreshInterval)
-		defer ticker.Stop()
-		for {
-			select {
-			case <-ctx.Done():
+	go a.refreshLoop(ctx)
+}
+
+// refreshLoop refreshes the access token tokenRefreshLead before it expires. A failed refresh is retried
+// with backoff; the current token stays in use meanwhile, and an error is logged once it has expired.
+func (a *Agent) refreshLoop(ctx context.Context) {
+	retry := minTokenRetry
+	timer := time.NewTimer(a.nextTokenRefresh())
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		a.tokenMu.RLock()
+		rt, expires := a.refreshToken, a.tokenExpires
+		a.tokenMu.RUnlock()
+		access, newRefresh, expiresIn, err := a.fetchTokenWithRefresh(ctx, rt)
+		if err != nil {
+			if ctx.Err() != nil {
 				return
-			case <-ticker.C:
-				a.tokenMu.RLock()
-				rt := a.refreshToken
-				a.tokenMu.RUnlock()
-				if rt == "" {
-					continue
-				}
-				access, newRefresh, _, err := a.fetchTokenWithRefresh(ctx, rt)
-				if err != nil {
-					a.logger.Warn("token refresh failed", "error", err)
-					continue
-				}
-				a.setTokens(access, newRefresh)
-				a.logger.Info("token refreshed successfully")
 			}
+			if !expires.IsZero() && time.Now().After(expires) {
+				a.logger.Error("token refresh failed and the access token has expired; coordinator calls will be rejected until refresh succeeds",
+					"error", err, "retry_in", retry)
+			} else {
+				a.logger.Warn("token refresh failed, keeping the current token", "error", err,
+					"token_expires_in", time.Until(expires).Round(time.Second), "retry_in", retry)
+			}
+			timer.Reset(retry)
+			retry = min(retry*2, maxTokenRetry)
+			continue
 		}
-	}()
+		a.setTokens(access, newRefresh, expiresIn)
+		a.logger.Info("token refreshed successfully", "expires_in_sec", expiresIn)
+		retry = minTokenRetry
+		timer.Reset(a.nextTokenRefresh())
+	}
+}
+
+// nextTokenRefresh returns how long to wait before refreshing the current access token.
+func (a *Agent) nextTokenRefresh() time.Duration {
+	a.tokenMu.RLock()
+	defer a.tokenMu.RUnlock()
+	lead := min(tokenRefreshLead, a.tokenTTL/2)
+	return max(time.Until(a.tokenExpires)-lead, minTokenRetry)
 }
 
 // Start begins the main lifecycle of the agent. It connects to the coordinator, registers the node,
 // and launches background goroutines for periodic heartbeats and pinning task polling.
-// This call is blocking until the context is canceled, at which time it closes the gRPC connection.
+// This call blocks until Stop completes or the context is canceled, in which case Start runs the shutdown
+// sequence itself.
 func (a *Agent) Start(ctx context.Context) error {
+	stopPropagation := context.AfterFunc(ctx, a.cancel)
+	defer stopPropagation()
+	ctx = a.ctx
+
 	if err := a.resolveInitialToken(ctx); err != nil {
 		return err
 	}
 	a.startRefreshLoop(ctx)
+	a.warnUnknownDisabledFeatures()
+
+	signer, err := identity.LoadSigner(a.config.IdentityKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load identity key: %w", err)
+	}
+	a.signer = signer
+
+	a.blocklist = blocklist.New(blocklist.Config{
+		File:            a.config.BlocklistFile,
+		URL:             a.config.BlocklistURL,
+		RefreshInterval: a.config.BlocklistRefresh,
+		Logger:          a.logger,
+	})
+	if a.blocklist.Enabled() {
+		if err := a.blocklist.Load(ctx); err != nil {
+			return fmt.Errorf("failed to load CID blocklist: %w", err)
+		}
+		go a.blocklist.RefreshLoop(ctx)
+	}
+
+	inv, err := inventory.Open(a.config.InventoryFile)
+	if err != nil {
+		return fmt.Errorf("failed to load pin inventory: %w", err)
+	}
+	a.inventory = inv
+	close(a.invLoaded)
+
+	state, err := taskstate.Open(a.config.TaskStateFile)
+	if err != nil {
+		return fmt.Errorf("failed to load task state: %w", err)
+	}
+	a.taskState = state
+
+	if a.config.RegisterFirst {
+		if err := a.startRegisterFirst(ctx); err != nil {
+			return err
+		}
+	} else {
+		multiaddrs, err := a.bringUpIPFS(ctx)
+		if err != nil {
+			return err
+		}
+		if err := a.retryWhileUnreachable(ctx, "connect", a.connectCoordinator); err != nil {
+			return err
+		}
+		if err := a.retryWhileUnreachable(ctx, "pre-flight", a.preflight); err != nil {
+			return err
+		}
+		if a.config.RequireReachable {
+			if err := a.checkRequiredReachability(ctx, a.config.ReachabilityTimeout); err != nil {
+				return err
+			}
+		}
+		err = a.retryWhileUnreachable(ctx, "register", func(ctx context.Context) error {
+			return a.registerAndAnnounce(ctx, multiaddrs)
+		})
+		if err != nil {
+			return err
+		}
+		if err := a.connectToPeers(ctx); err != nil {
+			a.logger.Warn("failed to connect to some peers", "error", err)
+		}
+		a.startHeartbeats()
+		a.restoreTaskState(ctx)
+		a.taskLoops.Go(a.taskLoop)
+		a.taskLoops.Go(a.reconcileLoop)
+		a.taskLoops.Go(a.preemptLoop)
+		a.taskLoops.Go(a.peerRefreshLoop)
+	}
+	a.taskLoops.Go(a.capacityLoop)
+
+	<-ctx.Done()
+
+	// Returns immediately with Stop's result if shutdown was started by Stop.
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), a.config.ShutdownDrainTimeout+10*time.Second)
+	defer cancel()
+	return a.Stop(shutdownCtx)
+}
 
+// startRegisterFirst registers with the coordinator before IPFS is up so the coordinator knows the node is
+// coming online, starts heartbeats, then brings up IPFS and re-registers with the peer ID and addresses.
+// Task polling only starts once IPFS is ready.
+func (a *Agent) startRegisterFirst(ctx context.Context) error {
+	if err := a.connectCoordinator(ctx); err != nil {
+		return err
+	}
+	if err := a.preflight(ctx); err != nil {
+		return err
+	}
+	// The peer ID is not known until the IPFS daemon is running; it is sent on re-registration.
+	if err := a.registerAndAnnounce(ctx, nil); err != nil {
+		return err
+	}
+	a.startHeartbeats()
+
+	multiaddrs, err := a.bringUpIPFS(ctx)
+	if err != nil {
+		return err
+	}
+	if a.config.RequireReachable {
+		if err := a.checkRequiredReachability(ctx, a.config.ReachabilityTimeout); err != nil {
+			return err
+		}
+	}
+	if err := a.registerAndAnnounce(ctx, multiaddrs); err != nil {
+		return err
+	}
+	// Replace the loop so the next heartbeat carries the IPFS state right after re-registration.
+	a.startHeartbeats()
+	if err := a.connectToPeers(ctx); err != nil {
+		a.logger.Warn("failed to connect to some peers", "error", err)
+	}
+	a.restoreTaskState(ctx)
+	a.taskLoops.Go(a.taskLoop)
+	a.taskLoops.Go(a.reconcileLoop)
+	a.taskLoops.Go(a.preemptLoop)
+	a.taskLoops.Go(a.peerRefreshLoop)
+	return nil
+}
+
+// bringUpIPFS sets up and starts IPFS, records the local peer ID and returns the multiaddrs to advertise.
+func (a *Agent) bringUpIPFS(ctx context.Context) ([]string, error) {
 	if err := a.setupIPFS(ctx); err != nil {
 		a.logger.Error("IPFS setup failed", "error", err)
-		return fmt.Errorf("failed to setup IPFS: %w", err)
+		return nil, fmt.Errorf("failed to setup IPFS: %w", err)
 	}
 
 	a.logger.Info("getting peer info from IPFS")
 	peerID, multiaddrs, err := a.ipfsManager.GetPeerInfo(ctx)
 	if err != nil {
 		a.logger.Error("get peer info failed", "error", err)
-		return fmt.Errorf("failed to get peer info: %w", err)
+		return nil, fmt.Errorf("failed to get peer info: %w", err)
 	}
 	a.peerID = peerID
+	a.refineCapacity(ctx)
+	multiaddrs, dropped := limitMultiaddrs(multiaddrs, a.config.MaxMultiaddrs)
+	if dropped > 0 {
+		a.logger.Warn("too many multiaddrs, advertising only the most reachable ones",
+			"advertised", len(multiaddrs), "dropped", dropped, "max", a.config.MaxMultiaddrs)
+	}
+	return multiaddrs, nil
+}
 
+// connectCoordinator creates the gRPC connection to the coordinator and, unless lazy dialing is configured,
+// waits until it is established.
+func (a *Agent) connectCoordinator(ctx context.Context) error {
 	a.logger.Info("connecting to coordinator", "addr", a.config.CoordinatorAddr)
-	conn, err := grpc.NewClient(
-		a.config.CoordinatorAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	conn, err := a.dialCoordinator(ctx, !a.config.LazyDial)
+	if err != nil {
+		return err
+	}
+	a.conn.cur.Store(conn)
+	a.client = nodepb.NewNodeCoordinatorClient(&a.conn)
+	a.ipfs = ipfs.NewClient(a.config.IPFSAPIURL, a.ipfsClientOptions()...)
+	return nil
+}
+
+// dialCoordinator creates a gRPC connection to the coordinator. With await, it waits up to DialTimeout
+// for the connection to be established and closes it on failure.
+func (a *Agent) dialCoordinator(ctx context.Context, await bool) (*grpc.ClientConn, error) {
+	creds, err := a.transportCredentials()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure coordinator TLS: %w", err)
+	}
+	opts := append([]grpc.DialOption{grpc.WithTransportCredentials(creds)}, a.compressionDialOptions()...)
+	if a.config.KeepaliveTime > 0 {
+		// Keep the connection warm between heartbeats and polls so NATs and load balancers don't reap it.
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                a.config.KeepaliveTime,
+			Timeout:             a.config.KeepaliveTimeout,
+			PermitWithoutStream: true,
+		}))
+	}
+	conn, err := grpc.NewClient(a.config.CoordinatorAddr, opts...)
 	if err != nil {
 		a.logger.Error("coordinator connection failed", "addr", a.config.CoordinatorAddr, "error", err)
-		return fmt.Errorf("failed to connect to coordinator: %w", err)
+		return nil, fmt.Errorf("failed to connect to coordinator: %w", err)
+	}
+
+	if await && a.config.DialTimeout > 0 {
+		if err := awaitConnected(ctx, conn, a.config.DialTimeout); err != nil {
+			a.logger.Error("coordinator connection failed", "addr", a.config.CoordinatorAddr, "error", err)
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to connect to coordinator at %s: %w", a.config.CoordinatorAddr, err)
+		}
+		a.logger.Info("connected to coordinator", "addr", a.config.CoordinatorAddr)
 	}
-	a.conn = conn
-	a.client = nodepb.NewNodeCoordinatorClient(conn)
-	a.ipfs = ipfs.NewClient(a.config.IPFSAPIURL)
+	return conn, nil
+}
+
+// ipfsClientOptions returns the options for the agent's IPFS API client: the manager's (request tracing)
+// plus the circuit breaker, which only the agent's client uses.
+func (a *Agent) ipfsClientOptions() []ipfs.ClientOption {
+	opts := a.ipfsManager.ClientOptions()
+	if a.config.IPFSBreakerThreshold > 0 {
+		opts = append(opts, ipfs.WithCircuitBreaker(ipfs.BreakerConfig{
+			FailureThreshold: a.config.IPFSBreakerThreshold,
+			Cooldown:         a.config.IPFSBreakerCooldown,
+			OnStateChange:    a.breakerChanged,
+		}))
+	}
+	return opts
+}
 
-	a.logger.Info("registering node with coordinator")
+// registerAndAnnounce registers the node and records the resulting identity in stats and events.
+func (a *Agent) registerAndAnnounce(ctx context.Context, multiaddrs []string) error {
+	// Only the first registration of a process is rate limited; re-registration follows deliberately.
+	if a.NodeID() == "" {
+		if err := a.awaitRegisterSlot(ctx); err != nil {
+			return err
+		}
+	}
+	a.logger.Info("registering node with coordinator", "peer_id", a.peerID)
+	a.advertised.Store(&multiaddrs)
 	if err := a.register(ctx, multiaddrs); err != nil {
+		err = a.classifyCoordinatorError(err)
 		a.logger.Error("node registration failed", "error", err)
 		return fmt.Errorf("initial registration failed: %w", err)
 	}
 
-	a.startTime = time.Now()
-	a.logger.Info("node agent started and registered", "node_id", a.nodeID, "peer_id", a.peerID)
-
-	if err := a.connectToPeers(ctx); err != nil {
-		a.logger.Warn("failed to connect to some peers", "error", err)
+	if a.startTime.IsZero() {
+		a.startTime = time.Now()
 	}
+	a.stats.SetIdentity(a.NodeID(), a.peerID, a.startTime)
+	a.stats.SetGroup(a.config.Group)
+	logging.SetNodeID(a.logger, a.NodeID())
+	a.logger.Info("node agent started and registered", "peer_id", a.peerID)
+	a.events.SetNodeID(a.NodeID())
+	a.events.Notify(events.Registered, "node registered with coordinator", map[string]any{"peer_id": a.peerID})
+	return nil
+}
 
-	go a.heartbeatLoop(ctx)
-	go a.taskLoop(ctx)
-
-	<-ctx.Done()
+// installProgressInterval is how often progress is logged while waiting for a slow setup step.
+const installProgressInterval = 15 * time.Second
 
-	if err := a.ipfsManager.StopDaemon(ctx); err != nil {
-		a.logger.Warn("failed to stop IPFS daemon", "error", err)
+// logProgress logs msg with the elapsed time every installProgressInterval until the returned stop
+// function is called or ctx is done, so a long-running step (e.g. downloading kubo) does not look hung.
+func (a *Agent) logProgress(ctx context.Context, msg string) (stop func()) {
+	start := time.Now()
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(installProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				a.logger.Info(msg, "elapsed", time.Since(start).Round(time.Second))
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
 	}
-
-	return a.conn.Close()
 }
 
 // setupIPFS initializes IPFS: installs, initializes repo, configures private network, and starts daemon.
 func (a *Agent) setupIPFS(ctx context.Context) error {
 	a.logger.Info("setting up IPFS")
 
-	if err := a.ipfsManager.EnsureInstalled(ctx); err != nil {
+	stopProgress := a.logProgress(ctx, "still waiting for IPFS installation")
+	err := a.ipfsManager.EnsureInstalled(ctx)
+	stopProgress()
+	if err != nil {
 		return fmt.Errorf("failed to ensure IPFS is installed: %w", err)
 	}
 
@@ -255,12 +660,24 @@ func (a *Agent) setupIPFS(ctx context.Context) error {
 		return fmt.Errorf("failed to initialize IPFS repository: %w", err)
 	}
 
-	// Configure private network (swarm key and bootstrap peers)
-	// For now, use empty swarm key and bootstrap peers - can be enhanced later
+	if err := a.ipfsManager.ConfigureOverlay(ctx); err != nil {
+		return fmt.Errorf("failed to apply IPFS config overlay: %w", err)
+	}
+
+	// Configure private network: the manager applies the configured swarm key (or generates one) and
+	// strips the public bootstrap peers
 	if err := a.ipfsManager.ConfigurePrivateNetwork(ctx, "", []string{}); err != nil {
 		return fmt.Errorf("failed to configure private network: %w", err)
 	}
 
+	if err := a.ipfsManager.ConfigureExperimental(ctx); err != nil {
+		return fmt.Errorf("failed to configure IPFS experimental features: %w", err)
+	}
+
+	if err := a.ipfsManager.ConfigureServePriority(ctx); err != nil {
+		return fmt.Errorf("failed to configure IPFS serve priority: %w", err)
+	}
+
 	if err := a.ipfsManager.StartDaemon(ctx); err != nil {
 		return fmt.Errorf("failed to start IPFS daemon: %w", err)
 	}
@@ -269,163 +686,451 @@ func (a *Agent) setupIPFS(ctx context.Context) error {
 	return nil
 }
 
+// NodeID returns the ID the coordinator assigned at the last registration, or "" before the node has
+// registered.
+func (a *Agent) NodeID() string {
+	if id := a.nodeID.Load(); id != nil {
+		return *id
+	}
+	return ""
+}
+
+// NamedPins returns the inventory records whose IPFS pin name starts with prefix, sorted by CID; an
+// empty prefix returns every named pin. ok is false until Start has loaded the inventory.
+func (a *Agent) NamedPins(prefix string) (records []inventory.Record, ok bool) {
+	select {
+	case <-a.invLoaded:
+		return a.inventory.Named(prefix), true
+	default:
+		return nil, false
+	}
+}
+
 // register performs a registration with the network coordinator, exchanging node information and
 // receiving a node ID which is persisted in the Agent instance.
 // Returns an error if registration is unsuccessful or coordinator rejects the operation.
 func (a *Agent) register(ctx context.Context, multiaddrs []string) error {
-	md := metadata.New(map[string]string{
-		"authorization": "Bearer " + a.getAuthToken(),
-	})
-	ctx = metadata.NewOutgoingContext(ctx, md)
-
-	resp, err := a.client.Register(ctx, &nodepb.RegisterRequest{
+	resp, err := a.client.Register(a.authContext(ctx), &nodepb.RegisterRequest{
 		PeerId:               a.peerID,
-		Name:                 a.config.NodeName,
+		Name:                 a.registrationName(),
 		Region:               a.config.Region,
+		Group:                a.config.Group,
 		IpfsMultiaddrs:       multiaddrs,
-		StorageCapacityBytes: a.config.CapacityBytes,
+		Transports:           transportSummary(multiaddrs),
+		StorageCapacityBytes: a.capacityBytes.Load(),
 		WalletAddress:        a.config.WalletAddress,
 		AuthToken:            a.getAuthToken(),
+		GatewayUrl:           a.config.IPFSGatewayURL,
+		StorageMedia:         a.config.StorageMedia,
+		State:                a.registrationState(),
+		ProtocolVersion:      protocolVersion,
 	})
 	if err != nil {
+		switch status.Code(err) {
+		case codes.AlreadyExists:
+			a.logger.Warn("coordinator reports the node name is already taken; set a unique node.name or node.name_suffix",
+				"name", a.registrationName())
+		case codes.FailedPrecondition:
+			// Coordinators enforcing their own compatibility window reject outdated nodes this way.
+			return fmt.Errorf("%w: %v; upgrade the node to a newer release", errProtocolIncompatible, err)
+		}
 		return err
 	}
 	if !resp.Success {
 		return fmt.Errorf("coordinator rejected registration: %s", resp.Error)
 	}
+	if err := a.negotiateProtocol(resp.ProtocolVersion, resp.MinNodeProtocolVersion); err != nil {
+		return err
+	}
 
-	a.nodeID = resp.NodeId
+	// Background loops read the node ID concurrently with re-registration (e.g. register-first mode).
+	nodeID := resp.NodeId
+	a.nodeID.Store(&nodeID)
+	a.negotiateHeartbeat(resp.Capabilities)
+	a.negotiatePinProgress(resp.Capabilities)
+	a.applyFeatureFlags(resp.FeatureFlags)
 	return nil
 }
 
-// connectToPeers connects to peers returned by the coordinator.
-func (a *Agent) connectToPeers(ctx context.Context) error {
-	md := metadata.New(map[string]string{
-		"authorization": "Bearer " + a.getAuthToken(),
-	})
-	ctx = metadata.NewOutgoingContext(ctx, md)
-
-	resp, err := a.client.GetPeers(ctx, &nodepb.GetPeersRequest{
-		NodeId: a.nodeID,
+// startHeartbeats starts the heartbeat loop, first stopping and waiting for a loop started earlier (e.g.
+// before a re-registration), so only one heartbeat loop runs at a time.
+func (a *Agent) startHeartbeats() {
+	a.hbMu.Lock()
+	defer a.hbMu.Unlock()
+	if a.hbStop != nil {
+		a.hbStop()
+	}
+	ctx, cancel := context.WithCancel(a.heartbeats.ctx)
+	done := make(chan struct{})
+	a.heartbeats.Go(func(context.Context) {
+		defer close(done)
+		a.heartbeatLoop(ctx)
 	})
-	if err != nil {
-		return fmt.Errorf("failed to get peers: %w", err)
+	a.hbStop = func() {
+		cancel()
+		<-done
 	}
+}
 
-	if resp.Error != "" {
-		return fmt.Errorf("coordinator error: %s", resp.Error)
-	}
+// Node states reported at registration.
+const (
+	nodeStateInitializing = "initializing" // Registered before IPFS is up (register-first); not yet accepting tasks
+	nodeStateReady        = "ready"
+)
 
-	connected := 0
-	for _, peer := range resp.Peers {
-		for _, multiaddr := range peer.Multiaddrs {
-			if err := a.ipfsManager.ConnectToPeer(ctx, multiaddr); err != nil {
-				a.logger.Warn("failed to connect to peer", "peer", multiaddr, "error", err)
-				continue
-			}
-			connected++
-		}
+// registrationState returns the state reported at registration: initializing until the IPFS peer ID is
+// known, so the coordinator expects the node rather than timing it out during a slow first boot.
+func (a *Agent) registrationState() string {
+	if a.peerID == "" {
+		return nodeStateInitializing
 	}
-
-	a.logger.Info("connected to peers", "connected", connected, "total", len(resp.Peers))
-	return nil
+	return nodeStateReady
 }
 
-// heartbeatLoop periodically sends heartbeat messages to the coordinator, reporting current storage usage and other statistics.
+// heartbeatLoop sends a heartbeat right away, unless one was sent within HeartbeatGrace, and then
+// periodically, reporting current storage usage and other statistics. While heartbeats fail the interval
+// backs off (see heartbeatDelay).
 // Runs as a background goroutine until context cancellation.
 func (a *Agent) heartbeatLoop(ctx context.Context) {
 	ticker := time.NewTicker(a.config.HeartbeatInterval)
 	defer ticker.Stop()
 
+	failures := 0
+	beat := func() {
+		prev := failures
+		failures = a.beat(ctx, failures)
+		if failures > 0 || prev > 0 {
+			ticker.Reset(a.heartbeatDelay(failures))
+		}
+	}
+	if last := a.lastBeat.Load(); last != 0 && time.Since(time.Unix(0, last)) < a.config.HeartbeatGrace {
+		a.logger.Debug("skipping immediate heartbeat, one was just sent")
+	} else {
+		beat()
+	}
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			stat, err := a.ipfs.RepoStat(ctx)
-			storageUsed := int64(0)
-			if err == nil && stat != nil {
-				if stat.RepoSize > uint64(math.MaxInt64) {
-					storageUsed = math.MaxInt64
-				} else {
-					storageUsed = int64(stat.RepoSize)
-				}
-			}
-			uptimeSeconds := int64(time.Since(a.startTime).Seconds())
-			md := metadata.New(map[string]string{
-				"authorization": "Bearer " + a.getAuthToken(),
-			})
-			heartbeatCtx := metadata.NewOutgoingContext(ctx, md)
+			beat()
+		}
+	}
+}
 
-			_, err = a.client.Heartbeat(heartbeatCtx, &nodepb.HeartbeatRequest{
-				NodeId:           a.nodeID,
-				StorageUsedBytes: storageUsed,
-				UptimeSeconds:    uptimeSeconds,
-			})
-			if err != nil {
-				a.logger.Warn("heartbeat failed", "error", err)
+// beat runs the per-heartbeat checks and sends one heartbeat. failures is the number of consecutive
+// heartbeats that failed before this one; the returned value is the count including this one (0 after a
+// success). Transitions are logged and sent as events.
+func (a *Agent) beat(ctx context.Context, failures int) int {
+	a.checkResourceLimits(ctx)
+	a.checkGateway(ctx)
+	a.lastBeat.Store(time.Now().UnixNano())
+	err := a.sendHeartbeat(ctx)
+	a.stats.HeartbeatSent(err == nil)
+	if err != nil {
+		failures++
+		a.logger.Warn("heartbeat failed", "error", err, "consecutive_failures", failures)
+		a.reconnectIfUnavailable(err)
+		if failures == 1 {
+			if a.config.MaxHeartbeatBackoff > 0 {
+				a.logger.Warn("heartbeats failing, backing off", "max_interval", a.config.MaxHeartbeatBackoff)
 			}
+			a.events.Notify(events.CoordinatorDisconnect, "heartbeat failed", map[string]any{"error": err.Error()})
 		}
+		return failures
+	}
+	if failures > 0 {
+		a.logger.Info("heartbeat succeeded, resuming normal interval", "failed_heartbeats", failures,
+			"interval", a.config.HeartbeatInterval)
+		a.events.Notify(events.CoordinatorReconnected, "heartbeat succeeded after failure", nil)
 	}
+	return 0
 }
 
 // taskLoop periodically polls the coordinator for new pinning tasks and spins up goroutines to process each task as they are received.
 // Runs as a background goroutine until context cancellation.
 func (a *Agent) taskLoop(ctx context.Context) {
+	a.pollStarted.CompareAndSwap(0, time.Now().UnixNano())
 	ticker := time.NewTicker(a.config.PollInterval)
 	defer ticker.Stop()
 
+	paused := false
+	var backoff time.Duration
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			md := metadata.New(map[string]string{
-				"authorization": "Bearer " + a.getAuthToken(),
-			})
-			taskCtx := metadata.NewOutgoingContext(ctx, md)
+			if !a.ipfsManager.IsReady() {
+				// The daemon crashed or is restarting; pins would only fail.
+				a.logger.Debug("IPFS daemon not ready, skipping task poll")
+				continue
+			}
+			if open := a.swarmGate(ctx, paused); !open {
+				// Without swarm peers the content cannot be retrieved; leave tasks with the coordinator.
+				paused = true
+				continue
+			}
+			paused = false
+			if a.ipfs.CircuitOpen(pinEndpoint) {
+				// Leave tasks with the coordinator while the daemon recovers.
+				a.logger.Debug("IPFS pin circuit breaker open, skipping task poll")
+				continue
+			}
 
-			resp, err := a.client.GetPinTasks(taskCtx, &nodepb.GetPinTasksRequest{
-				NodeId: a.nodeID,
+			resp, err := a.client.GetPinTasks(a.authContext(ctx), &nodepb.GetPinTasksRequest{
+				NodeId: a.NodeID(),
 			})
+			if hint := a.pollBackoff(resp, err); hint != backoff {
+				backoff = a.applyPollBackoff(ticker, hint)
+			}
 			if err != nil {
 				a.logger.Warn("failed to poll for tasks", "error", err)
+				a.reconnectIfUnavailable(err)
 				continue
 			}
 
-			for _, task := range resp.Tasks {
-				a.logger.Info("received pin task", "task_id", task.TaskId, "cid", task.Cid)
-				go a.processTask(ctx, task)
+			a.dispatchTasks(ctx, resp.Tasks)
+		}
+	}
+}
+
+// dispatchTasks queues polled tasks for pinning, skipping redeliveries of tasks already handled. Once
+// shutdown has begun, the remaining tasks are released to the coordinator instead of started.
+func (a *Agent) dispatchTasks(ctx context.Context, tasks []*nodepb.PinTask) {
+	for i, task := range tasks {
+		if ctx.Err() != nil || a.draining.Load() {
+			// Shutdown began while polling; don't start work that won't finish or be reported.
+			a.releaseTasks(ctx, tasks[i:], "node shutting down")
+			return
+		}
+		if !a.pinQueue.claim(task.TaskId) {
+			// Still being pinned, or completed and not yet settled on the coordinator.
+			a.logger.Debug("pin task already handled, ignoring redelivery", "task_id", task.TaskId, "cid", task.Cid)
+			continue
+		}
+		a.logger.Info("received pin task", "task_id", task.TaskId, "cid", task.Cid)
+		a.stats.TaskReceived()
+		a.firstTask.Do(a.recordFirstTask)
+		if a.config.AckTasks {
+			if err := a.ackTask(ctx, task); err != nil {
+				// Leave the task unprocessed so the coordinator re-dispatches it.
+				a.logger.Warn("failed to acknowledge pin task, skipping", "task_id", task.TaskId, "error", err)
+				a.pinQueue.abandon(task.TaskId)
+				continue
 			}
 		}
+		a.enqueuePin(task)
+	}
+}
+
+// recordFirstTask logs and records how long after task polling started the first pin task arrived. A long
+// delay on a node with free capacity points at a coordinator scheduling problem. Polling starts once the
+// node is ready, so IPFS setup in register_first mode is not counted.
+func (a *Agent) recordFirstTask() {
+	elapsed := time.Since(time.Unix(0, a.pollStarted.Load()))
+	a.stats.SetTimeToFirstTask(elapsed)
+	a.logger.Info("received first pin task since polling started", "time_to_first_task", elapsed.Round(time.Second),
+		"capacity_bytes", a.capacityBytes.Load())
+}
+
+// signReport stamps the report with a timestamp and, when an identity key is configured, a signature over
+// its canonical encoding so the coordinator can verify it.
+func (a *Agent) signReport(report *nodepb.ReportPinStatusRequest) {
+	if a.signer == nil {
+		return
+	}
+	report.SignedAt = time.Now().Unix()
+	report.Signature = a.signer.SignPinStatus(identity.PinStatusClaim{
+		NodeID:      report.NodeId,
+		TaskID:      report.TaskId,
+		Status:      int32(report.Status),
+		PinnedBytes: report.PinnedBytes,
+		Timestamp:   report.SignedAt,
+	})
+}
+
+// ackTask tells the coordinator that this node accepted the task, so it is not redelivered while the pin is in progress.
+func (a *Agent) ackTask(ctx context.Context, task *nodepb.PinTask) error {
+	resp, err := a.client.AckPinTask(a.authContext(ctx), &nodepb.AckPinTaskRequest{
+		NodeId: a.NodeID(),
+		TaskId: task.TaskId,
+	})
+	if err != nil {
+		return err
 	}
+	if resp.Error != "" {
+		return fmt.Errorf("coordinator rejected ack: %s", resp.Error)
+	}
+	return nil
 }
 
 // processTask handles the full lifecycle for a single pinning task: Execute the pin via IPFS, then report status back to coordinator.
-func (a *Agent) processTask(ctx context.Context, task *nodepb.PinTask) {
+// attempt is the 1-based pin attempt. A transient failure schedules the next attempt (see retryPin) instead
+// of being reported; processTask then returns false, since the task is not finished.
+func (a *Agent) processTask(ctx context.Context, task *nodepb.PinTask, attempt int) (finished bool) {
+	if task.Action == nodepb.PinTask_ACTION_UNPIN {
+		a.processUnpinTask(ctx, task)
+		return true
+	}
+	timing := stats.StartTaskTiming()
+	if blocked, entry := a.blocklist.Blocked(task.Cid); blocked {
+		a.logger.Warn("refusing to pin blocklisted content", "audit", true, "cid", task.Cid, "task_id", task.TaskId, "entry", entry)
+		a.reportStatus(ctx, task, nodepb.ReportPinStatusRequest_PIN_STATUS_REJECTED, "content is blocklisted by node operator")
+		return true
+	}
+	if reason := a.declineReason(task); reason != "" {
+		a.logger.Info("declining pin task", "cid", task.Cid, "task_id", task.TaskId, "reason", reason)
+		a.reportStatus(ctx, task, nodepb.ReportPinStatusRequest_PIN_STATUS_DECLINED, reason)
+		return true
+	}
+	if exhausted, f := a.attemptsExhausted(task.Cid); exhausted {
+		a.logger.Warn("not retrying abandoned content", "cid", task.Cid, "task_id", task.TaskId, "attempts", f.Attempts)
+		a.reportStatus(ctx, task, nodepb.ReportPinStatusRequest_PIN_STATUS_ABANDONED, abandonMessage(f))
+		return true
+	}
+
+	timing.Checks = timing.Lap()
 	a.logger.Info("pinning content", "cid", task.Cid)
 
-	err := a.ipfs.Pin(ctx, task.Cid)
+	leaseCtx, release := a.holdLease(ctx, task)
+	pinCtx, untrack := a.trackPin(leaseCtx, task)
+	a.stats.PinStarted()
+	stopProgress := a.reportPinProgress(pinCtx, task)
+	err := a.pinTask(pinCtx, task)
+	stopProgress()
+	a.stats.PinFinished(err == nil)
+	timing.Pin = timing.Lap()
+	if cause := context.Cause(pinCtx); err != nil && errors.Is(cause, errPinPreempted) {
+		err = cause
+	}
+	// Preemption, a lost lease and shutdown cancel pinCtx; those failures are final.
+	retry := err != nil && attempt <= a.config.MaxPinRetries && retryablePinError(err) && pinCtx.Err() == nil
+	untrack()
+	release()
+	if retry && !a.draining.Load() {
+		a.retryPin(task, attempt, err)
+		return false
+	}
+	if err != nil && errors.Is(context.Cause(leaseCtx), errLeaseLost) {
+		// The coordinator has likely handed the task to another node; leave reporting to that node.
+		a.logger.Warn("stopped working on task after losing its lease", "task_id", task.TaskId, "cid", task.Cid)
+		return true
+	}
+	if err != nil && ctx.Err() != nil && a.draining.Load() {
+		// Canceled when the shutdown drain timed out; hand the task back rather than leave it pending.
+		a.releaseTasks(ctx, []*nodepb.PinTask{task}, "node shutting down")
+		return true
+	}
+	if err == nil && a.config.VerifyPins {
+		err = a.verifyPinned(ctx, task)
+	}
+	if errors.Is(err, ipfs.ErrCircuitOpen) {
+		// The daemon was not contacted; defer the task rather than count it against the content.
+		a.releaseTasks(ctx, []*nodepb.PinTask{task}, "IPFS daemon overloaded")
+		return true
+	}
 
 	status := nodepb.ReportPinStatusRequest_PIN_STATUS_PINNED
+	failure := ""
 	if err != nil {
-		a.logger.Error("failed to pin content", "cid", task.Cid, "error", err)
+		if errors.Is(err, ipfs.ErrUnauthorized) {
+			a.logger.Error("IPFS API rejected credentials, pin cannot succeed until configuration is fixed", "cid", task.Cid, "error", err)
+		} else {
+			a.logger.Error("failed to pin content", "cid", task.Cid, "error", err)
+		}
 		status = nodepb.ReportPinStatusRequest_PIN_STATUS_FAILED
+		failure = err.Error()
+		if a.config.DiagnosePinFailures && !errors.Is(err, ipfs.ErrUnauthorized) {
+			diag := a.collectPinDiagnostics(ctx, task.Cid)
+			a.logger.Warn("pin failure diagnostics", "cid", task.Cid, "task_id", task.TaskId, "diagnostics", diag.Summary())
+			failure += " (" + diag.Summary() + ")"
+		}
+		// Credential problems and shutdown cancellation say nothing about the content; don't count them.
+		if !errors.Is(err, ipfs.ErrUnauthorized) && ctx.Err() == nil {
+			status, failure = a.recordAttemptFailure(task, status, failure)
+		}
 	}
 
-	md := metadata.New(map[string]string{
-		"authorization": "Bearer " + a.getAuthToken(),
-	})
-	reportCtx := metadata.NewOutgoingContext(ctx, md)
+	if err == nil {
+		a.recordPin(ctx, task, time.Now())
+		if err := a.inventory.ClearFailures(task.Cid); err != nil {
+			a.logger.Warn("failed to update inventory", "cid", task.Cid, "error", err)
+		}
+	}
 
-	_, err = a.client.ReportPinStatus(reportCtx, &nodepb.ReportPinStatusRequest{
-		NodeId: a.nodeID,
-		TaskId: task.TaskId,
-		Status: status,
-	})
-	if err != nil {
+	timing.Verify = timing.Lap()
+	a.stats.PinReported(strings.ToLower(strings.TrimPrefix(status.String(), "PIN_STATUS_")))
+	reportErr := a.sendReport(ctx, task, status, failure, &timing, attempt)
+	timing.Report = timing.Lap()
+	a.stats.TaskTimed(timing)
+	a.logger.Debug("pin task timing", "task_id", task.TaskId, "cid", task.Cid, "timing", timing)
+	if reportErr == nil && status == nodepb.ReportPinStatusRequest_PIN_STATUS_PINNED {
+		a.logger.Info("pin task completed", "task_id", task.TaskId, "duration", timing.Total())
+		if a.config.ReverifyAfter > 0 {
+			// Scheduled apart from the pin so the wait holds neither its queue slot nor the restart lock.
+			a.taskLoops.Go(func(ctx context.Context) { a.reverifyPin(ctx, task) })
+		}
+	}
+	return true
+}
+
+// pin pins cid under name (unnamed if empty), skipping the pin when pin/ls shows it is already pinned
+// recursively (feature pin_precheck). A failed check falls through to pinning.
+func (a *Agent) pin(ctx context.Context, cid, name string) (err error) {
+	if a.config.PinTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, a.config.PinTimeout, errPinTimeout)
+		defer cancel()
+		defer func() {
+			if err != nil && errors.Is(context.Cause(ctx), errPinTimeout) {
+				err = fmt.Errorf("%w after %s: %v", errPinTimeout, a.config.PinTimeout, err)
+			}
+		}()
+	}
+	if !a.featureEnabled(featurePinPrecheck) {
+		return a.pinNamed(ctx, cid, name)
+	}
+	if pinned, err := a.ipfs.IsPinned(ctx, cid); err == nil && pinned {
+		a.logger.Info("content already pinned, skipping pin", "cid", cid)
+		return nil
+	}
+	return a.pinNamed(ctx, cid, name)
+}
+
+// reportStatus sends a signed pin status report for task to the coordinator. Failures are logged and returned.
+func (a *Agent) reportStatus(ctx context.Context, task *nodepb.PinTask, status nodepb.ReportPinStatusRequest_PinStatus, failure string) error {
+	return a.sendReport(ctx, task, status, failure, nil, 0)
+}
+
+// sendReport is reportStatus with the task's timing breakdown so far (checks, pin, verify) attached
+// when timing is non-nil, and the number of pin attempts made for the task when non-zero. A pinned
+// report carries the pinned DAG size recorded in the inventory, which the report signature covers.
+func (a *Agent) sendReport(ctx context.Context, task *nodepb.PinTask, status nodepb.ReportPinStatusRequest_PinStatus, failure string, timing *stats.TaskTiming, attempts int) error {
+	report := &nodepb.ReportPinStatusRequest{
+		NodeId:   a.NodeID(),
+		TaskId:   task.TaskId,
+		Status:   status,
+		Error:    failure,
+		Attempts: int32(attempts),
+	}
+	if status == nodepb.ReportPinStatusRequest_PIN_STATUS_PINNED {
+		if rec, ok := a.inventory.Get(task.Cid); ok {
+			report.PinnedBytes = rec.SizeBytes
+		}
+	}
+	if timing != nil {
+		report.Timing = &nodepb.PinTiming{
+			ChecksMs: timing.Checks.Milliseconds(),
+			PinMs:    timing.Pin.Milliseconds(),
+			VerifyMs: timing.Verify.Milliseconds(),
+		}
+	}
+	a.signReport(report)
+	if _, err := a.client.ReportPinStatus(a.authContext(ctx), report); err != nil {
 		a.logger.Error("failed to report pin status", "task_id", task.TaskId, "error", err)
-	} else if status == nodepb.ReportPinStatusRequest_PIN_STATUS_PINNED {
-		a.logger.Info("pin task completed", "task_id", task.TaskId)
+		return err
 	}
+	a.saveTaskState(task, status.String())
+	return nil
 }
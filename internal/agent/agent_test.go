@@ -0,0 +1,156 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+	"github.com/wabisaby/wabisaby-node/internal/stats"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+	"google.golang.org/grpc"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// fakeIPFS is an IPFS API server recording the multiaddrs the node connects to and the CIDs it pins and
+// unpins. pin/ls streams the CIDs in pinned, or for an arg answers as kubo does whether it is pinned.
+type fakeIPFS struct {
+	mu        sync.Mutex
+	connected []string
+	unpinned  []string
+	pinned    []string
+	added     []string      // CIDs passed to pin/add
+	holdAdd   chan struct{} // When non-nil, pin/add responds only once it is closed
+	repoSize  uint64        // RepoSize reported by repo/stat
+}
+
+// newTestAgent returns an agent with cfg whose IPFS API is served by the returned fakeIPFS.
+func newTestAgent(t testing.TB, cfg AgentConfig) (*Agent, *fakeIPFS) {
+	t.Helper()
+	f := &fakeIPFS{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v0/swarm/connect", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.connected = append(f.connected, r.URL.Query().Get("arg"))
+		f.mu.Unlock()
+		io.WriteString(w, `{"Strings":["connect success"]}`)
+	})
+	mux.HandleFunc("POST /api/v0/pin/rm", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.unpinned = append(f.unpinned, r.URL.Query().Get("arg"))
+		f.mu.Unlock()
+		io.WriteString(w, `{"Pins":["`+r.URL.Query().Get("arg")+`"]}`)
+	})
+	mux.HandleFunc("POST /api/v0/pin/add", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		f.added = append(f.added, r.URL.Query().Get("arg"))
+		hold := f.holdAdd
+		f.mu.Unlock()
+		if hold != nil {
+			<-hold
+		}
+		io.WriteString(w, `{"Pins":["`+r.URL.Query().Get("arg")+`"]}`)
+	})
+	mux.HandleFunc("POST /api/v0/repo/stat", func(w http.ResponseWriter, _ *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		fmt.Fprintf(w, `{"RepoSize":%d}`, f.repoSize)
+	})
+	mux.HandleFunc("POST /api/v0/pin/ls", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if arg := r.URL.Query().Get("arg"); arg != "" && !slices.Contains(f.pinned, arg) {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"Message":"path '%s' is not pinned","Code":0,"Type":"error"}`, arg)
+			return
+		}
+		for _, cid := range f.pinned {
+			fmt.Fprintf(w, "{\"Cid\":%q,\"Type\":\"recursive\"}\n", cid)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	a := &Agent{
+		config:      cfg,
+		logger:      testLogger(),
+		stats:       stats.NewCollector(),
+		ipfsManager: ipfs.NewIPFSManager(ipfs.ManagerConfig{DataDir: t.TempDir(), APIURL: srv.URL, Logger: testLogger()}),
+	}
+	a.ipfs = ipfs.NewClient(srv.URL)
+	return a, f
+}
+
+// connectedAddrs returns the multiaddrs connected so far.
+func (f *fakeIPFS) connectedAddrs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.connected...)
+}
+
+// addedCIDs returns the CIDs passed to pin/add so far.
+func (f *fakeIPFS) addedCIDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.added...)
+}
+
+// unpinnedCIDs returns the CIDs unpinned so far.
+func (f *fakeIPFS) unpinnedCIDs() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.unpinned...)
+}
+
+// fakeCoordinator is a NodeCoordinatorClient whose RPCs are answered by the function fields that are
+// set; calling any other RPC panics.
+type fakeCoordinator struct {
+	nodepb.NodeCoordinatorClient
+	getPeers        func() (*nodepb.GetPeersResponse, error)
+	reportPinStatus func(*nodepb.ReportPinStatusRequest) (*nodepb.ReportPinStatusResponse, error)
+	deregister      func(*nodepb.DeregisterRequest) (*nodepb.DeregisterResponse, error)
+	ackPinTask      func(*nodepb.AckPinTaskRequest) (*nodepb.AckPinTaskResponse, error)
+	nackPinTask     func(*nodepb.NackPinTaskRequest) (*nodepb.NackPinTaskResponse, error)
+	heartbeat       func(context.Context, *nodepb.HeartbeatRequest) (*nodepb.HeartbeatResponse, error)
+	assignedPins    func() (*nodepb.GetAssignedPinsResponse, error)
+}
+
+func (c *fakeCoordinator) GetPeers(context.Context, *nodepb.GetPeersRequest, ...grpc.CallOption) (*nodepb.GetPeersResponse, error) {
+	return c.getPeers()
+}
+
+func (c *fakeCoordinator) ReportPinStatus(_ context.Context, req *nodepb.ReportPinStatusRequest, _ ...grpc.CallOption) (*nodepb.ReportPinStatusResponse, error) {
+	return c.reportPinStatus(req)
+}
+
+func (c *fakeCoordinator) Deregister(_ context.Context, req *nodepb.DeregisterRequest, _ ...grpc.CallOption) (*nodepb.DeregisterResponse, error) {
+	return c.deregister(req)
+}
+
+func (c *fakeCoordinator) AckPinTask(_ context.Context, req *nodepb.AckPinTaskRequest, _ ...grpc.CallOption) (*nodepb.AckPinTaskResponse, error) {
+	return c.ackPinTask(req)
+}
+
+func (c *fakeCoordinator) NackPinTask(_ context.Context, req *nodepb.NackPinTaskRequest, _ ...grpc.CallOption) (*nodepb.NackPinTaskResponse, error) {
+	return c.nackPinTask(req)
+}
+
+func (c *fakeCoordinator) Heartbeat(ctx context.Context, req *nodepb.HeartbeatRequest, _ ...grpc.CallOption) (*nodepb.HeartbeatResponse, error) {
+	return c.heartbeat(ctx, req)
+}
+
+func (c *fakeCoordinator) GetAssignedPins(context.Context, *nodepb.GetAssignedPinsRequest, ...grpc.CallOption) (*nodepb.GetAssignedPinsResponse, error) {
+	return c.assignedPins()
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxUnassignShare caps the share of the inventory a single reconciliation may unpin, so a coordinator
+// that suddenly forgets most of the node's assignments cannot wipe its pinset in one pass.
+const maxUnassignShare = 0.1
+
+// reconcileAssignments compares the pins the coordinator assigns to this node with the local pinset.
+// Assigned content that is not pinned is queued for pinning as if its task had been polled; content the
+// node pinned for a task (inventory records from before this run) that is no longer assigned is unpinned
+// and reported as unpinned. Always-pin CIDs and content pinned outside of tasks are never unpinned.
+//
+// Unpinning guards against a coordinator that answers wrongly: an empty assignment list unpins nothing, a
+// CID is only unpinned once two consecutive reconciliations found it unassigned, and at most
+// maxUnassignShare of the inventory (at least one pin) is unpinned per run; the rest follows next run.
+// Coordinators without the GetAssignedPins RPC are tolerated; the check is then skipped from then on.
+func (a *Agent) reconcileAssignments(ctx context.Context) {
+	if a.noAssignments.Load() || a.draining.Load() {
+		return
+	}
+	started := time.Now()
+	resp, err := a.client.GetAssignedPins(a.authContext(ctx), &nodepb.GetAssignedPinsRequest{NodeId: a.NodeID()})
+	if status.Code(err) == codes.Unimplemented {
+		a.logger.Debug("coordinator does not list assigned pins, skipping assignment reconciliation")
+		a.noAssignments.Store(true)
+		return
+	}
+	if err != nil {
+		a.logger.Warn("failed to get assigned pins", "error", err)
+		a.reconnectIfUnavailable(err)
+		return
+	}
+
+	if len(resp.Pins) == 0 {
+		// More likely a coordinator fault than the node losing every assignment at once.
+		a.logger.Warn("coordinator assigned no pins, skipping assignment reconciliation")
+		a.unassigned = nil
+		return
+	}
+
+	assigned := make(map[string]*nodepb.PinTask, len(resp.Pins))
+	for _, task := range resp.Pins {
+		assigned[task.Cid] = task
+	}
+	pinned := make(map[string]bool, len(assigned))
+	err = a.ipfs.StreamPins(ctx, func(cid string) error {
+		if _, ok := assigned[cid]; ok {
+			pinned[cid] = true
+		}
+		return nil
+	})
+	if err != nil {
+		a.logger.Warn("failed to list pins for assignment reconciliation", "error", err)
+		return
+	}
+
+	missing := 0
+	for cid, task := range assigned {
+		if pinned[cid] || !a.pinQueue.claim(task.TaskId) {
+			continue
+		}
+		a.logger.Info("assigned content not pinned, pinning", "cid", cid, "task_id", task.TaskId)
+		a.enqueuePin(task)
+		missing++
+	}
+
+	records := a.inventory.Records()
+	limit := max(1, int(float64(len(records))*maxUnassignShare))
+	candidates := make(map[string]bool)
+	unassigned := 0
+	for _, rec := range records {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, ok := assigned[rec.CID]; ok || a.alwaysPinned(rec.CID) || !rec.PinnedAt.Before(started) {
+			continue
+		}
+		if !a.unassigned[rec.CID] || unassigned >= limit {
+			// Not confirmed by the previous run yet, or over this run's share; reconsidered next run.
+			candidates[rec.CID] = true
+			continue
+		}
+		a.logger.Info("content no longer assigned, unpinning", "cid", rec.CID, "task_id", rec.TaskID)
+		a.unpinRecord(ctx, rec, "no longer assigned to this node")
+		unassigned++
+	}
+	a.unassigned = candidates
+	a.logger.Info("assignment reconciliation completed", "assigned", len(assigned), "missing", missing,
+		"unassigned", unassigned, "pending_unassign", len(candidates))
+}
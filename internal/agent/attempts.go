@@ -0,0 +1,52 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// attemptsExhausted reports whether the pin attempts for cid have used up MaxTaskAttempts, returning the
+// recorded failures. A MaxTaskAttempts of zero means unlimited attempts.
+func (a *Agent) attemptsExhausted(cid string) (bool, inventory.Failures) {
+	if a.config.MaxTaskAttempts <= 0 {
+		return false, inventory.Failures{}
+	}
+	f := a.inventory.FailuresFor(cid)
+	return f.Attempts >= a.config.MaxTaskAttempts, f
+}
+
+// recordAttemptFailure counts a failed pin attempt for task's CID. When the attempt budget is now
+// exhausted it returns the ABANDONED status and a failure message carrying the failure history, so the
+// coordinator stops re-dispatching the CID to this node; otherwise it returns status and failure unchanged.
+func (a *Agent) recordAttemptFailure(task *nodepb.PinTask, status nodepb.ReportPinStatusRequest_PinStatus, failure string) (nodepb.ReportPinStatusRequest_PinStatus, string) {
+	if a.config.MaxTaskAttempts <= 0 {
+		return status, failure
+	}
+	f, err := a.inventory.RecordFailure(task.Cid, failure, time.Now())
+	if err != nil {
+		a.logger.Warn("failed to record pin failure in inventory", "cid", task.Cid, "error", err)
+	}
+	if f.Attempts < a.config.MaxTaskAttempts {
+		return status, failure
+	}
+	a.logger.Warn("pin attempt budget exhausted, abandoning content", "cid", task.Cid, "task_id", task.TaskId,
+		"attempts", f.Attempts, "max_attempts", a.config.MaxTaskAttempts)
+	return nodepb.ReportPinStatusRequest_PIN_STATUS_ABANDONED, abandonMessage(f)
+}
+
+// abandonMessage summarizes the failure history of an abandoned CID for the coordinator.
+func abandonMessage(f inventory.Failures) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "abandoned after %d failed attempts", f.Attempts)
+	for i, msg := range f.History {
+		fmt.Fprintf(&b, "; [%d] %s", f.Attempts-len(f.History)+i+1, msg)
+	}
+	return b.String()
+}
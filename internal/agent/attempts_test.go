@@ -0,0 +1,108 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	"github.com/wabisaby/wabisaby-node/internal/taskstate"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// newAttemptsAgent returns an agent with an in-memory inventory and task state, allowing maxAttempts
+// pin attempts per CID.
+func newAttemptsAgent(t *testing.T, maxAttempts int) *Agent {
+	t.Helper()
+	inv, err := inventory.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	state, err := taskstate.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Agent{config: AgentConfig{MaxTaskAttempts: maxAttempts}, logger: testLogger(), inventory: inv, taskState: state}
+}
+
+func TestRecordAttemptFailure(t *testing.T) {
+	a := newAttemptsAgent(t, 3)
+	task := &nodepb.PinTask{TaskId: "task-1", Cid: "bafy-poison"}
+	failed := nodepb.ReportPinStatusRequest_PIN_STATUS_FAILED
+
+	for i := 1; i <= 2; i++ {
+		failure := fmt.Sprintf("timeout %d", i)
+		status, msg := a.recordAttemptFailure(task, failed, failure)
+		if status != failed || msg != failure {
+			t.Fatalf("attempt %d: got %s %q, want the failure unchanged", i, status, msg)
+		}
+		if exhausted, _ := a.attemptsExhausted(task.Cid); exhausted {
+			t.Fatalf("attempts exhausted after %d of 3", i)
+		}
+	}
+
+	status, msg := a.recordAttemptFailure(task, failed, "timeout 3")
+	if status != nodepb.ReportPinStatusRequest_PIN_STATUS_ABANDONED {
+		t.Fatalf("attempt 3: status %s, want ABANDONED", status)
+	}
+	want := "abandoned after 3 failed attempts; [1] timeout 1; [2] timeout 2; [3] timeout 3"
+	if msg != want {
+		t.Fatalf("abandon message = %q, want %q", msg, want)
+	}
+	if exhausted, f := a.attemptsExhausted(task.Cid); !exhausted || f.Attempts != 3 {
+		t.Fatalf("attemptsExhausted = %v, %d attempts; want true, 3", exhausted, f.Attempts)
+	}
+
+	if err := a.inventory.ClearFailures(task.Cid); err != nil {
+		t.Fatal(err)
+	}
+	if exhausted, _ := a.attemptsExhausted(task.Cid); exhausted {
+		t.Fatal("attempts still exhausted after a successful pin cleared them")
+	}
+}
+
+func TestRecordAttemptFailureUnlimited(t *testing.T) {
+	a := newAttemptsAgent(t, 0)
+	task := &nodepb.PinTask{TaskId: "task-1", Cid: "bafy-poison"}
+	for range 10 {
+		if status, _ := a.recordAttemptFailure(task, nodepb.ReportPinStatusRequest_PIN_STATUS_FAILED, "timeout"); status != nodepb.ReportPinStatusRequest_PIN_STATUS_FAILED {
+			t.Fatalf("status %s with unlimited attempts, want FAILED", status)
+		}
+	}
+	if f := a.inventory.FailuresFor(task.Cid); f.Attempts != 0 {
+		t.Fatalf("%d failures recorded with unlimited attempts, want none", f.Attempts)
+	}
+}
+
+func TestAbandonedTaskReport(t *testing.T) {
+	a := newAttemptsAgent(t, 2)
+	var reports []*nodepb.ReportPinStatusRequest
+	a.client = &fakeCoordinator{reportPinStatus: func(req *nodepb.ReportPinStatusRequest) (*nodepb.ReportPinStatusResponse, error) {
+		reports = append(reports, req)
+		return &nodepb.ReportPinStatusResponse{Success: true}, nil
+	}}
+	for _, msg := range []string{"no providers", "timeout"} {
+		if _, err := a.inventory.RecordFailure("bafy-poison", msg, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A redelivered task for exhausted content is abandoned without pinning.
+	a.processTask(context.Background(), &nodepb.PinTask{TaskId: "task-2", Cid: "bafy-poison"}, 1)
+
+	if len(reports) != 1 {
+		t.Fatalf("%d reports, want 1", len(reports))
+	}
+	r := reports[0]
+	if r.TaskId != "task-2" || r.Status != nodepb.ReportPinStatusRequest_PIN_STATUS_ABANDONED {
+		t.Fatalf("report %s for %s, want ABANDONED for task-2", r.Status, r.TaskId)
+	}
+	if !strings.Contains(r.Error, "no providers") || !strings.Contains(r.Error, "timeout") {
+		t.Fatalf("report error %q lacks the failure history", r.Error)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// pollBackoff returns the poll delay requested by the coordinator, from the response's retry_after or,
+// for a failed poll, a RetryInfo error detail. Hints no longer than the poll interval are ignored, since
+// they would not slow polling down, and hints are capped at MaxPollBackoff. It returns 0 without a hint.
+func (a *Agent) pollBackoff(resp *nodepb.GetPinTasksResponse, err error) time.Duration {
+	var hint time.Duration
+	if err != nil {
+		hint = retryDelay(err)
+	} else if resp.RetryAfterSeconds > 0 {
+		hint = time.Duration(resp.RetryAfterSeconds) * time.Second
+	}
+	if hint <= a.config.PollInterval {
+		return 0
+	}
+	if a.config.MaxPollBackoff > 0 {
+		hint = min(hint, a.config.MaxPollBackoff)
+	}
+	return hint
+}
+
+// heartbeatDelay returns the delay before the next heartbeat after failures consecutive failed ones: the
+// heartbeat interval doubled per failure, capped at MaxHeartbeatBackoff and jittered so nodes that lost
+// the coordinator together do not return in lockstep. Without failures, or with backoff disabled, it is
+// the heartbeat interval.
+func (a *Agent) heartbeatDelay(failures int) time.Duration {
+	interval := a.config.HeartbeatInterval
+	if failures == 0 || a.config.MaxHeartbeatBackoff <= 0 {
+		return interval
+	}
+	delay := interval
+	for range failures {
+		if delay >= a.config.MaxHeartbeatBackoff {
+			break
+		}
+		delay *= 2
+	}
+	return jitter(min(delay, max(a.config.MaxHeartbeatBackoff, interval)))
+}
+
+// retryDelay returns the delay in err's gRPC RetryInfo detail, or 0 if it has none.
+func retryDelay(err error) time.Duration {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.RetryDelay != nil {
+			return info.RetryDelay.AsDuration()
+		}
+	}
+	return 0
+}
+
+// applyPollBackoff switches the poll ticker to hint, or back to the poll interval when hint is 0, and
+// returns hint as the new back-off.
+func (a *Agent) applyPollBackoff(ticker *time.Ticker, hint time.Duration) time.Duration {
+	if hint > 0 {
+		a.logger.Info("coordinator requested poll back-off", "retry_after", hint)
+		ticker.Reset(hint)
+	} else {
+		a.logger.Info("coordinator back-off cleared, resuming normal polling", "interval", a.config.PollInterval)
+		ticker.Reset(a.config.PollInterval)
+	}
+	return hint
+}
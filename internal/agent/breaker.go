@@ -0,0 +1,22 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import "github.com/wabisaby/wabisaby-node/internal/ipfs"
+
+// pinEndpoint is the IPFS API command whose circuit breaker gates task intake.
+const pinEndpoint = "pin/add"
+
+// breakerChanged records an IPFS endpoint's circuit breaker transition in stats and the log.
+func (a *Agent) breakerChanged(endpoint, state string) {
+	a.stats.SetIPFSBreaker(endpoint, state)
+	switch state {
+	case ipfs.BreakerOpen:
+		a.logger.Warn("IPFS circuit breaker opened, calls short-circuited", "endpoint", endpoint, "cooldown", a.config.IPFSBreakerCooldown)
+	case ipfs.BreakerHalfOpen:
+		a.logger.Info("IPFS circuit breaker half-open, probing daemon", "endpoint", endpoint)
+	case ipfs.BreakerClosed:
+		a.logger.Info("IPFS circuit breaker closed", "endpoint", endpoint)
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"math"
+	"os"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/diskstat"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// capacityBreakdown splits repoSize into bytes held by coordinator-assigned pins, by operator pins
+// (always_pin) and the rest (unpinned cache, IPFS overhead, pins not in the inventory). Pin sizes come from
+// the inventory; content shared between pins is counted for each, so the pin totals can exceed their true
+// footprint, in which case "other" is reported as 0.
+func (a *Agent) capacityBreakdown(repoSize uint64) *nodepb.CapacityBreakdown {
+	var assigned, operator int64
+	for _, rec := range a.inventory.Records() {
+		if a.alwaysPinned(rec.CID) {
+			operator += rec.SizeBytes
+		} else {
+			assigned += rec.SizeBytes
+		}
+	}
+	other := int64(0)
+	if repoSize <= math.MaxInt64 {
+		other = max(int64(repoSize)-assigned-operator, 0)
+	}
+	return &nodepb.CapacityBreakdown{
+		AssignedPinBytes: assigned,
+		OperatorPinBytes: operator,
+		OtherBytes:       other,
+	}
+}
+
+// refineCapacity replaces an auto-detected capacity, which config computes from free disk space alone
+// before the IPFS repo can be measured, with detectCapacity's result once the daemon is running. Startup
+// and re-detection then use the same formula and the advertised capacity does not jump on the first
+// recheck. On failure the startup estimate is kept.
+func (a *Agent) refineCapacity(ctx context.Context) {
+	if !a.config.CapacityAutoDetect {
+		return
+	}
+	detected, err := a.detectCapacity(ctx)
+	if err != nil {
+		a.logger.Warn("capacity detection failed, advertising free disk space only", "error", err)
+		return
+	}
+	a.capacityBytes.Store(detected)
+	a.logger.Info("detected storage capacity", "capacity_bytes", detected)
+}
+
+// capacityLoop periodically re-detects storage capacity when it was auto-detected at startup, so disk added
+// to a running node is advertised without a restart. The new value is sent with the next heartbeat.
+// Changes smaller than CapacityChangeThreshold are ignored to avoid flapping.
+func (a *Agent) capacityLoop(ctx context.Context) {
+	if !a.config.CapacityAutoDetect || a.config.CapacityRecheckInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(a.config.CapacityRecheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			detected, err := a.detectCapacity(ctx)
+			if err != nil {
+				a.logger.Warn("capacity re-detection failed", "error", err)
+				continue
+			}
+			current := a.capacityBytes.Load()
+			if !capacityChanged(current, detected, a.config.CapacityChangeThreshold) {
+				continue
+			}
+			a.capacityBytes.Store(detected)
+			a.logger.Info("storage capacity changed, advertising new capacity", "previous_bytes", current, "capacity_bytes", detected)
+		}
+	}
+}
+
+// detectCapacity returns the capacity the node can offer: what IPFS already stores plus the usable share of
+// the remaining free disk space. Counting the repo size keeps capacity stable as pins consume free space.
+func (a *Agent) detectCapacity(ctx context.Context) (int64, error) {
+	wd, err := os.Getwd()
+	if err != nil {
+		return 0, err
+	}
+	usable, err := diskstat.UsableBytes(wd)
+	if err != nil {
+		return 0, err
+	}
+	stat, err := a.ipfs.RepoStat(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if stat.RepoSize > uint64(math.MaxInt64-usable) {
+		return math.MaxInt64, nil
+	}
+	return usable + int64(stat.RepoSize), nil
+}
+
+// capacityChanged reports whether detected differs from current by more than threshold (a fraction of current).
+func capacityChanged(current, detected int64, threshold float64) bool {
+	if current <= 0 {
+		return detected > 0
+	}
+	delta := math.Abs(float64(detected-current)) / float64(current)
+	return delta > threshold
+}
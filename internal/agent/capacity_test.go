@@ -0,0 +1,50 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/wabisaby/wabisaby-node/internal/diskstat"
+)
+
+func TestRefineCapacityCountsRepo(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, f := newTestAgent(t, AgentConfig{CapacityAutoDetect: true})
+	f.repoSize = 50 << 30
+	before, err := diskstat.UsableBytes(wd)
+	if err != nil {
+		t.Skipf("free disk space unavailable: %v", err)
+	}
+	a.capacityBytes.Store(before)
+
+	a.refineCapacity(context.Background())
+
+	// Startup and re-detection agree: repo size plus the usable share of free space.
+	redetected, err := a.detectCapacity(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := a.capacityBytes.Load()
+	if got < before+int64(f.repoSize)/2 || capacityChanged(got, redetected, 0.01) {
+		t.Fatalf("capacity after startup detection = %d, want about %d (re-detected %d)", got, before+int64(f.repoSize), redetected)
+	}
+}
+
+func TestRefineCapacityKeepsConfiguredCapacity(t *testing.T) {
+	a, f := newTestAgent(t, AgentConfig{CapacityBytes: 100e9})
+	f.repoSize = 50 << 30
+	a.capacityBytes.Store(100e9)
+
+	a.refineCapacity(context.Background())
+
+	if got := a.capacityBytes.Load(); got != 100e9 {
+		t.Fatalf("configured capacity changed to %d", got)
+	}
+}
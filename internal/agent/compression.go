@@ -0,0 +1,59 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/status"
+)
+
+// Coordinator compression modes.
+const (
+	compressionNone = "none"
+	compressionGzip = "gzip"
+)
+
+// compressionDialOptions returns the dial options for the configured coordinator compression. Importing
+// the gzip package registers the compressor, so the node advertises gzip and the coordinator may compress
+// its responses; with "gzip" requests are compressed as well.
+func (a *Agent) compressionDialOptions() []grpc.DialOption {
+	if a.config.Compression != compressionGzip {
+		return nil
+	}
+	a.compressReq.Store(true)
+	return []grpc.DialOption{grpc.WithChainUnaryInterceptor(a.compressionInterceptor)}
+}
+
+// compressionInterceptor gzip-compresses requests while the coordinator accepts them. A coordinator that
+// cannot decompress gzip rejects the call as Unimplemented; the call is then retried uncompressed and
+// request compression stays off for the life of the connection. Metadata on ctx (the auth token) is
+// passed through unchanged.
+func (a *Agent) compressionInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if !a.compressReq.Load() {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	err := invoker(ctx, method, req, reply, cc, append(opts, grpc.UseCompressor(gzip.Name))...)
+	if !compressionRejected(err) {
+		return err
+	}
+	if a.compressReq.CompareAndSwap(true, false) {
+		a.logger.Warn("coordinator does not accept gzip-compressed requests, sending them uncompressed", "error", err)
+	}
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// compressionRejected reports whether err is the server refusing the request's compression.
+func compressionRejected(err error) bool {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.Unimplemented {
+		return false
+	}
+	msg := strings.ToLower(st.Message())
+	return strings.Contains(msg, "compress")
+}
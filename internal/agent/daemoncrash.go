@@ -0,0 +1,34 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"fmt"
+
+	"github.com/wabisaby/wabisaby-node/internal/events"
+	"github.com/wabisaby/wabisaby-node/internal/health"
+)
+
+// pauseDaemonDown is the stats pause reason recorded while the IPFS daemon is down after a crash.
+const pauseDaemonDown = "IPFS daemon down"
+
+// onDaemonCrash marks the IPFS daemon critical and pauses task acceptance until onDaemonRestart. Called
+// by the IPFS manager when the daemon exits unexpectedly.
+func (a *Agent) onDaemonCrash(exitCode int, err error) {
+	a.setHealth(health.ComponentDaemon, health.SeverityCritical,
+		fmt.Sprintf("IPFS daemon exited unexpectedly (exit code %d)", exitCode))
+	a.stats.PauseTasks(pauseDaemonDown)
+	fields := map[string]any{"exit_code": exitCode}
+	if err != nil {
+		fields["error"] = err.Error()
+	}
+	a.events.Notify(events.IPFSDaemonCrashed, "IPFS daemon exited unexpectedly", fields)
+}
+
+// onDaemonRestart resumes task acceptance once the IPFS manager has restarted a crashed daemon.
+func (a *Agent) onDaemonRestart() {
+	a.setHealth(health.ComponentDaemon, health.SeverityOK, "")
+	a.stats.ResumeTasks(pauseDaemonDown)
+	a.events.Notify(events.IPFSDaemonRestarted, "IPFS daemon restarted after a crash", nil)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// pinDiagnostics is a snapshot of IPFS state gathered after a pin failure to explain why it failed.
+type pinDiagnostics struct {
+	SwarmPeers    int    // Connected swarm peers (-1 if unavailable)
+	RepoSize      uint64 // Repo size in bytes
+	StorageMax    uint64 // Repo storage limit in bytes
+	Providers     int    // Providers found for the CID (-1 if not checked or unavailable)
+	CollectErrors []string
+}
+
+// Summary renders the diagnostics as a short single-line description suitable for a status report.
+func (d pinDiagnostics) Summary() string {
+	parts := []string{fmt.Sprintf("swarm_peers=%d", d.SwarmPeers)}
+	if d.StorageMax > 0 {
+		parts = append(parts, fmt.Sprintf("repo=%d/%d bytes", d.RepoSize, d.StorageMax))
+	}
+	if d.Providers >= 0 {
+		parts = append(parts, fmt.Sprintf("providers=%d", d.Providers))
+	}
+	if len(d.CollectErrors) > 0 {
+		parts = append(parts, "diagnostic_errors="+strings.Join(d.CollectErrors, "; "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// collectPinDiagnostics gathers swarm peer count, repo stats and (optionally, since it is expensive) the
+// number of DHT providers for cid. Each probe is bounded so diagnostics never stall the worker for long.
+func (a *Agent) collectPinDiagnostics(ctx context.Context, cid string) pinDiagnostics {
+	d := pinDiagnostics{SwarmPeers: -1, Providers: -1}
+
+	probeCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if n, err := a.ipfs.SwarmPeerCount(probeCtx); err != nil {
+		d.CollectErrors = append(d.CollectErrors, "swarm peers: "+err.Error())
+	} else {
+		d.SwarmPeers = n
+	}
+	if stat, err := a.ipfs.RepoStat(probeCtx); err != nil {
+		d.CollectErrors = append(d.CollectErrors, "repo stat: "+err.Error())
+	} else {
+		d.RepoSize, d.StorageMax = stat.RepoSize, stat.StorageMax
+	}
+
+	if a.config.DiagnoseFindProviders {
+		findCtx, findCancel := context.WithTimeout(ctx, 30*time.Second)
+		defer findCancel()
+		if n, err := a.ipfs.FindProvidersCount(findCtx, cid, 5); err != nil {
+			d.CollectErrors = append(d.CollectErrors, "findprovs: "+err.Error())
+		} else {
+			d.Providers = n
+		}
+	}
+
+	return d
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/tlsconfig"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// transportCredentials returns TLS credentials for the coordinator connection when TLS is enabled,
+// and insecure (plaintext) credentials otherwise.
+func (a *Agent) transportCredentials() (credentials.TransportCredentials, error) {
+	if !a.config.TLSEnabled {
+		return insecure.NewCredentials(), nil
+	}
+	tlsCfg, err := tlsconfig.Build(tlsconfig.Options{
+		MinVersion:   a.config.TLSMinVersion,
+		CipherSuites: a.config.TLSCipherSuites,
+		CAFile:       a.config.TLSCAFile,
+		CertFile:     a.config.TLSClientCertFile,
+		KeyFile:      a.config.TLSClientKeyFile,
+		ServerName:   a.config.TLSServerName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return credentials.NewTLS(tlsCfg), nil
+}
+
+// awaitConnected forces an eager connection attempt on conn and blocks until it reaches the Ready state,
+// the timeout elapses, or ctx is canceled. grpc.NewClient dials lazily, so without this a misconfigured
+// coordinator address would only surface on the first RPC.
+func awaitConnected(ctx context.Context, conn *grpc.ClientConn, timeout time.Duration) error {
+	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		switch state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Shutdown:
+			return fmt.Errorf("connection shut down")
+		case connectivity.Idle:
+			conn.Connect()
+		}
+		if !conn.WaitForStateChange(dialCtx, state) {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%w within %s (last state: %s)", errCoordinatorUnreachable, timeout, state)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/stats"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// newDispatchAgent returns an agent whose coordinator records released tasks in nacked.
+func newDispatchAgent(nacked *[]string) (*Agent, *fakeCoordinator) {
+	c := &fakeCoordinator{nackPinTask: func(req *nodepb.NackPinTaskRequest) (*nodepb.NackPinTaskResponse, error) {
+		if req.Reason != "node shutting down" {
+			return nil, errors.New("unexpected release reason " + req.Reason)
+		}
+		*nacked = append(*nacked, req.TaskId)
+		return &nodepb.NackPinTaskResponse{}, nil
+	}}
+	a := &Agent{logger: testLogger(), stats: stats.NewCollector(), client: c, pinQueue: newPinQueue(0)}
+	return a, c
+}
+
+var polledTasks = []*nodepb.PinTask{{TaskId: "task-1", Cid: "bafy1"}, {TaskId: "task-2", Cid: "bafy2"}}
+
+func TestDispatchTasksWhileDraining(t *testing.T) {
+	var nacked []string
+	a, _ := newDispatchAgent(&nacked)
+	a.draining.Store(true)
+
+	a.dispatchTasks(context.Background(), polledTasks)
+
+	if !slices.Equal(nacked, []string{"task-1", "task-2"}) {
+		t.Fatalf("released %v, want every polled task", nacked)
+	}
+	if n := a.stats.Snapshot().TasksReceived; n != 0 {
+		t.Fatalf("%d tasks started during shutdown", n)
+	}
+}
+
+func TestDispatchTasksAfterCancel(t *testing.T) {
+	var nacked []string
+	a, _ := newDispatchAgent(&nacked)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	a.dispatchTasks(ctx, polledTasks)
+
+	if !slices.Equal(nacked, []string{"task-1", "task-2"}) {
+		t.Fatalf("released %v, want every polled task", nacked)
+	}
+	if n := a.stats.Snapshot().TasksReceived; n != 0 {
+		t.Fatalf("%d tasks started after cancellation", n)
+	}
+	// Released tasks are not claimed, so a redelivery after a restart is processed.
+	if !a.pinQueue.claim("task-1") {
+		t.Fatal("released task left claimed")
+	}
+}
+
+func TestDispatchTasksShutdownMidBatch(t *testing.T) {
+	var nacked []string
+	a, c := newDispatchAgent(&nacked)
+	a.config.AckTasks = true
+	// Shutdown begins while the first task is being acknowledged.
+	c.ackPinTask = func(*nodepb.AckPinTaskRequest) (*nodepb.AckPinTaskResponse, error) {
+		a.draining.Store(true)
+		return nil, context.Canceled
+	}
+
+	a.dispatchTasks(context.Background(), polledTasks)
+
+	if !slices.Equal(nacked, []string{"task-2"}) {
+		t.Fatalf("released %v, want the task polled after shutdown began", nacked)
+	}
+}
+
+func TestRecordFirstTaskMeasuresFromPolling(t *testing.T) {
+	var nacked []string
+	a, _ := newDispatchAgent(&nacked)
+	// Registered an hour ago (e.g. before a long IPFS setup), polling for tasks for two seconds.
+	a.startTime = time.Now().Add(-time.Hour)
+	a.pollStarted.Store(time.Now().Add(-2 * time.Second).UnixNano())
+
+	a.recordFirstTask()
+
+	if got := a.stats.Snapshot().TimeToFirstTaskSeconds; got < 2 || got > 60 {
+		t.Fatalf("time to first task = %.0fs, want about 2s since polling started", got)
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"maps"
+	"slices"
+	"sync"
+)
+
+// Feature flags the coordinator can toggle per node.
+const (
+	featurePinPrecheck       = "pin_precheck"       // Check pin/ls before pinning and skip content that is already pinned
+	featureCapacityBreakdown = "capacity_breakdown" // Report the capacity breakdown in heartbeats
+)
+
+// featureDefaults lists the known feature flags with the value used until the coordinator sets them.
+// Flags the coordinator sends that are not listed here are ignored.
+var featureDefaults = map[string]bool{
+	featurePinPrecheck:       true,
+	featureCapacityBreakdown: true,
+}
+
+// featureFlags holds the coordinator-provided feature flags. The zero value reports the defaults.
+type featureFlags struct {
+	mu    sync.RWMutex
+	flags map[string]bool // Known flags as last sent by the coordinator
+}
+
+// applyFeatureFlags replaces the coordinator-provided flags with flags and logs every flag whose
+// effective value changed. A nil or empty map (a coordinator without feature flags) leaves them unchanged.
+func (a *Agent) applyFeatureFlags(flags map[string]bool) {
+	if len(flags) == 0 {
+		return
+	}
+	known := make(map[string]bool, len(flags))
+	for name, enabled := range flags {
+		if _, ok := featureDefaults[name]; !ok {
+			a.logger.Debug("ignoring unknown feature flag from coordinator", "flag", name)
+			continue
+		}
+		known[name] = enabled
+	}
+
+	before := make(map[string]bool, len(featureDefaults))
+	for name := range featureDefaults {
+		before[name] = a.featureEnabled(name)
+	}
+	a.features.mu.Lock()
+	a.features.flags = known
+	a.features.mu.Unlock()
+
+	for _, name := range slices.Sorted(maps.Keys(featureDefaults)) {
+		if now := a.featureEnabled(name); now != before[name] {
+			a.logger.Info("feature flag changed", "flag", name, "enabled", now, "locally_disabled", a.featureDisabledLocally(name))
+		}
+	}
+}
+
+// featureEnabled reports whether the named feature is on: the coordinator's value, or the default when
+// it has not set one, unless the flag is disabled in local config.
+func (a *Agent) featureEnabled(name string) bool {
+	if a.featureDisabledLocally(name) {
+		return false
+	}
+	a.features.mu.RLock()
+	defer a.features.mu.RUnlock()
+	if enabled, ok := a.features.flags[name]; ok {
+		return enabled
+	}
+	return featureDefaults[name]
+}
+
+// featureDisabledLocally reports whether the operator force-disabled the named feature.
+func (a *Agent) featureDisabledLocally(name string) bool {
+	return slices.Contains(a.config.DisabledFeatures, name)
+}
+
+// warnUnknownDisabledFeatures logs locally disabled features that the node does not know, which are
+// likely typos.
+func (a *Agent) warnUnknownDisabledFeatures() {
+	for _, name := range a.config.DisabledFeatures {
+		if _, ok := featureDefaults[name]; !ok {
+			a.logger.Warn("unknown feature in features.disable", "flag", name)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Gateway self-check results reported in heartbeats and stats.
+const (
+	GatewayOK      = "ok"
+	GatewayFailing = "failing"
+	GatewayUnknown = "unknown" // No pinned content to check yet
+)
+
+// gatewayCheckTimeout bounds a single gateway self-check request.
+const gatewayCheckTimeout = 10 * time.Second
+
+// checkGateway fetches a known-pinned CID through the node's own gateway and records the result in stats,
+// from where heartbeats report it. A failing gateway while pins succeed points at a serving problem rather
+// than a storage one. Does nothing when no gateway is configured.
+func (a *Agent) checkGateway(ctx context.Context) {
+	if a.config.IPFSGatewayURL == "" {
+		return
+	}
+	result := GatewayUnknown
+	var err error
+	if rec, ok := a.inventory.Newest(); ok {
+		if err = probeGateway(ctx, a.gatewayHTTP, a.config.IPFSGatewayURL, rec.CID); err == nil {
+			result = GatewayOK
+		} else {
+			result = GatewayFailing
+		}
+	}
+
+	if previous := a.stats.SetGatewayStatus(result); previous != result {
+		switch result {
+		case GatewayFailing:
+			a.logger.Warn("IPFS gateway self-check failing", "gateway_url", a.config.IPFSGatewayURL, "error", err)
+		case GatewayOK:
+			a.logger.Info("IPFS gateway self-check passing", "gateway_url", a.config.IPFSGatewayURL)
+		}
+	}
+}
+
+// probeGateway requests /ipfs/<cid> from the gateway at gatewayURL and expects a 200 response. Only the
+// headers are requested, so large content is not transferred.
+func probeGateway(ctx context.Context, client *http.Client, gatewayURL, cid string) error {
+	ctx, cancel := context.WithTimeout(ctx, gatewayCheckTimeout)
+	defer cancel()
+	url := strings.TrimRight(gatewayURL, "/") + "/ipfs/" + cid
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("gateway returned status %d for %s", resp.StatusCode, cid)
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	"github.com/wabisaby/wabisaby-node/internal/stats"
+)
+
+func TestCheckGateway(t *testing.T) {
+	var failing atomic.Bool
+	var probed atomic.Value
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probed.Store(r.Method + " " + r.URL.Path)
+		if failing.Load() {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer gateway.Close()
+
+	inv, err := inventory.Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &Agent{
+		config:      AgentConfig{IPFSGatewayURL: gateway.URL + "/"},
+		logger:      testLogger(),
+		stats:       stats.NewCollector(),
+		inventory:   inv,
+		gatewayHTTP: gateway.Client(),
+	}
+	ctx := context.Background()
+
+	a.checkGateway(ctx)
+	if got := a.stats.GatewayStatus(); got != GatewayUnknown {
+		t.Fatalf("status with nothing pinned = %q, want %q", got, GatewayUnknown)
+	}
+
+	now := time.Now()
+	for _, rec := range []inventory.Record{
+		{CID: "bafy-old", PinnedAt: now.Add(-time.Hour)},
+		{CID: "bafy-new", PinnedAt: now},
+	} {
+		if err := inv.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.checkGateway(ctx)
+	if got := a.stats.GatewayStatus(); got != GatewayOK {
+		t.Fatalf("status with a serving gateway = %q, want %q", got, GatewayOK)
+	}
+	if got := probed.Load(); got != "HEAD /ipfs/bafy-new" {
+		t.Fatalf("gateway probed with %v, want HEAD /ipfs/bafy-new", got)
+	}
+
+	failing.Store(true)
+	a.checkGateway(ctx)
+	if got := a.stats.GatewayStatus(); got != GatewayFailing {
+		t.Fatalf("status with a failing gateway = %q, want %q", got, GatewayFailing)
+	}
+	if got := a.stats.Snapshot().GatewayStatus; got != GatewayFailing {
+		t.Fatalf("stats snapshot gateway status = %q, want %q", got, GatewayFailing)
+	}
+}
+
+func TestCheckGatewayNotConfigured(t *testing.T) {
+	a := &Agent{logger: testLogger(), stats: stats.NewCollector()}
+	a.checkGateway(context.Background())
+	if got := a.stats.GatewayStatus(); got != "" {
+		t.Fatalf("status without a gateway = %q, want none", got)
+	}
+}
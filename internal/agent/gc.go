@@ -0,0 +1,61 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+)
+
+// maybeCollectGarbage starts a repo GC in the background when the repo has grown past GCHighWatermark of
+// its storage limit (the kubo StorageMax, or the advertised capacity if the repo has none), so space held
+// by unpinned blocks is reclaimed before new pins start failing. At most one GC runs at a time, and none
+// starts within GCMinInterval of the previous one, since a repo full of pinned content stays full.
+func (a *Agent) maybeCollectGarbage(stat *ipfs.RepoStatResult) {
+	if a.config.GCHighWatermark <= 0 || stat == nil {
+		return
+	}
+	limit := stat.StorageMax
+	if limit == 0 {
+		limit = uint64(max(a.capacityBytes.Load(), 0))
+	}
+	if limit == 0 {
+		return
+	}
+	used := float64(stat.RepoSize) / float64(limit)
+	if used < a.config.GCHighWatermark {
+		return
+	}
+	if last := a.lastGC.Load(); last != 0 && time.Since(time.Unix(0, last)) < a.config.GCMinInterval {
+		return
+	}
+	if !a.gcRunning.CompareAndSwap(false, true) {
+		return
+	}
+	a.lastGC.Store(time.Now().UnixNano())
+	a.logger.Info("repo above GC high watermark, running IPFS garbage collection",
+		"repo_size", stat.RepoSize, "limit", limit, "used", used, "watermark", a.config.GCHighWatermark)
+	a.heartbeats.Go(func(ctx context.Context) {
+		defer a.gcRunning.Store(false)
+		a.collectGarbage(ctx)
+	})
+}
+
+// collectGarbage runs one repo GC and logs how much space it reclaimed.
+func (a *Agent) collectGarbage(ctx context.Context) {
+	started := time.Now()
+	before, _ := a.ipfs.RepoStat(ctx)
+	removed, err := a.ipfs.RepoGC(ctx)
+	if err != nil {
+		a.logger.Warn("IPFS garbage collection failed", "blocks_removed", removed, "error", err)
+		return
+	}
+	attrs := []any{"blocks_removed", removed, "duration", time.Since(started).Round(time.Millisecond)}
+	if after, err := a.ipfs.RepoStat(ctx); err == nil && before != nil && before.RepoSize >= after.RepoSize {
+		attrs = append(attrs, "bytes_reclaimed", before.RepoSize-after.RepoSize)
+	}
+	a.logger.Info("IPFS garbage collection completed", attrs...)
+}
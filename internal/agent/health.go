@@ -0,0 +1,187 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/events"
+	"github.com/wabisaby/wabisaby-node/internal/health"
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+const (
+	ipfsSlowThreshold     = 5 * time.Second  // IPFS API latency above which the daemon counts as slow
+	capacityDegradedRatio = 0.90             // Share of capacity used above which storage counts as nearly full
+	capacityCriticalRatio = 0.98             // Share of capacity used above which storage counts as full
+	flapWindow            = 10 * time.Minute // Window over which coordinator reconnects are counted
+	flapThreshold         = 3                // Reconnects within flapWindow that count as a flapping connection
+)
+
+// reconnectHistory records when coordinator reconnects started, to detect a flapping connection.
+type reconnectHistory struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+// add records a reconnect at now and returns the number of reconnects within flapWindow.
+func (h *reconnectHistory) add(now time.Time) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.times = append(h.prune(now), now)
+	return len(h.times)
+}
+
+// recent returns the number of reconnects within flapWindow of now.
+func (h *reconnectHistory) recent(now time.Time) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.times = h.prune(now)
+	return len(h.times)
+}
+
+func (h *reconnectHistory) prune(now time.Time) []time.Time {
+	i := 0
+	for i < len(h.times) && now.Sub(h.times[i]) > flapWindow {
+		i++
+	}
+	return h.times[i:]
+}
+
+// Health returns the node's current health: each monitored component and the overall status. The
+// readiness components (IPFS daemon, heartbeat) are evaluated on every call.
+func (a *Agent) Health() health.Status {
+	a.checkReadiness(time.Now())
+	return a.health.Status()
+}
+
+// checkReadiness marks the IPFS daemon critical while it is not ready and the heartbeat critical unless
+// a heartbeat succeeded within two heartbeat intervals of now.
+func (a *Agent) checkReadiness(now time.Time) {
+	if a.ipfsManager.IsReady() {
+		a.setHealth(health.ComponentDaemon, health.SeverityOK, "")
+	} else {
+		a.setHealth(health.ComponentDaemon, health.SeverityCritical, "IPFS daemon not ready")
+	}
+
+	last := a.stats.LastHeartbeat()
+	switch {
+	case last.IsZero():
+		a.setHealth(health.ComponentHeartbeat, health.SeverityCritical, "no successful heartbeat yet")
+	case now.Sub(last) > 2*a.config.HeartbeatInterval:
+		a.setHealth(health.ComponentHeartbeat, health.SeverityCritical,
+			fmt.Sprintf("last successful heartbeat %s ago", now.Sub(last).Round(time.Second)))
+	default:
+		a.setHealth(health.ComponentHeartbeat, health.SeverityOK, "")
+	}
+}
+
+// setHealth records a component's health and logs severity changes. It reports whether the component's
+// health changed.
+func (a *Agent) setHealth(component string, severity health.Severity, message string) bool {
+	if !a.health.Set(component, severity, message) {
+		return false
+	}
+	if severity == health.SeverityOK {
+		a.logger.Info("component recovered", "component", component)
+		return true
+	}
+	a.logger.Warn("component degraded", "component", component, "severity", severity.String(), "reason", message)
+	return true
+}
+
+// checkHealth updates the components checked on every heartbeat from the repo stat result (err and the
+// call's latency), the advertised capacity, a write probe of the IPFS data directory and the coordinator
+// reconnect history.
+func (a *Agent) checkHealth(stat *ipfs.RepoStatResult, err error, latency time.Duration) {
+	switch {
+	case err != nil:
+		a.setHealth(health.ComponentIPFS, health.SeverityCritical, fmt.Sprintf("repo stat failed: %v", err))
+	case latency > ipfsSlowThreshold:
+		a.setHealth(health.ComponentIPFS, health.SeverityDegraded, fmt.Sprintf("IPFS API slow: repo stat took %s", latency.Round(time.Millisecond)))
+	default:
+		a.setHealth(health.ComponentIPFS, health.SeverityOK, "")
+	}
+
+	if capacity := a.capacityBytes.Load(); stat != nil && capacity > 0 {
+		used := float64(stat.RepoSize) / float64(capacity)
+		fields := map[string]any{"repo_size_bytes": stat.RepoSize, "capacity_bytes": capacity}
+		switch {
+		case used >= capacityCriticalRatio:
+			message := fmt.Sprintf("storage full: %.0f%% of capacity used", used*100)
+			if a.setHealth(health.ComponentCapacity, health.SeverityCritical, message) {
+				a.events.Notify(events.CapacityCritical, message, fields)
+			}
+		case used >= capacityDegradedRatio:
+			message := fmt.Sprintf("storage nearly full: %.0f%% of capacity used", used*100)
+			if a.setHealth(health.ComponentCapacity, health.SeverityDegraded, message) {
+				a.events.Notify(events.CapacityDegraded, message, fields)
+			}
+		default:
+			a.setHealth(health.ComponentCapacity, health.SeverityOK, "")
+		}
+	}
+
+	if dir := a.config.IPFSDataDir; dir != "" {
+		if err := probeWritable(dir); err != nil {
+			message := fmt.Sprintf("cannot write to %s: %v", dir, err)
+			if errors.Is(err, syscall.EROFS) {
+				message = fmt.Sprintf("%s is on a read-only filesystem", dir)
+			}
+			a.setHealth(health.ComponentDisk, health.SeverityCritical, message)
+		} else {
+			a.setHealth(health.ComponentDisk, health.SeverityOK, "")
+		}
+	}
+
+	a.checkCoordinatorHealth()
+}
+
+// checkCoordinatorHealth marks the coordinator critical while a reconnect is running and degraded while
+// reconnects within flapWindow reach flapThreshold.
+func (a *Agent) checkCoordinatorHealth() {
+	if a.reconnecting.Load() {
+		a.setHealth(health.ComponentCoordinator, health.SeverityCritical, "coordinator unavailable, reconnecting")
+		return
+	}
+	if n := a.reconnects.recent(time.Now()); n >= flapThreshold {
+		a.setHealth(health.ComponentCoordinator, health.SeverityDegraded, fmt.Sprintf("coordinator connection flapping: %d reconnects in %s", n, flapWindow))
+		return
+	}
+	a.setHealth(health.ComponentCoordinator, health.SeverityOK, "")
+}
+
+// probeWritable creates and removes a temporary file in dir.
+func probeWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".wabisaby-probe-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	err = f.Close()
+	if rmErr := os.Remove(name); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// healthProto converts a health status for the heartbeat, listing only the components that are not OK.
+func healthProto(status health.Status) *nodepb.NodeHealth {
+	out := &nodepb.NodeHealth{Status: status.Overall.String()}
+	for _, c := range status.Degraded() {
+		out.Components = append(out.Components, &nodepb.ComponentHealth{
+			Name:         c.Name,
+			Severity:     c.Severity.String(),
+			Message:      c.Message,
+			SinceUnixSec: c.Since.Unix(),
+		})
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// capabilityExtendedHeartbeat is advertised by coordinators that accept heartbeat fields beyond the core
+// node ID, storage usage and uptime.
+const capabilityExtendedHeartbeat = "heartbeat.extended"
+
+// negotiateHeartbeat selects minimal heartbeats when configured, or when the coordinator advertises its
+// capabilities at registration without extended heartbeat support. Coordinators that advertise nothing
+// keep the configured mode.
+func (a *Agent) negotiateHeartbeat(capabilities []string) {
+	if a.config.MinimalHeartbeat {
+		a.hbMinimal.Store(true)
+		return
+	}
+	if len(capabilities) > 0 && !slices.Contains(capabilities, capabilityExtendedHeartbeat) {
+		a.logger.Info("coordinator does not support extended heartbeats, sending core fields only")
+		a.hbMinimal.Store(true)
+	}
+}
+
+// buildHeartbeat assembles the heartbeat request. Core fields are always set; the additive optional
+// fields are only set when extended heartbeats are in use.
+func (a *Agent) buildHeartbeat(ctx context.Context, minimal bool) *nodepb.HeartbeatRequest {
+	started := time.Now()
+	stat, err := a.ipfs.RepoStat(ctx)
+	a.checkHealth(stat, err, time.Since(started))
+	if err == nil {
+		a.maybeCollectGarbage(stat)
+	}
+	storageUsed := int64(0)
+	var repoSize uint64
+	if err == nil && stat != nil {
+		repoSize = stat.RepoSize
+		a.stats.SetRepoSize(stat.RepoSize)
+		if stat.RepoSize > uint64(math.MaxInt64) {
+			storageUsed = math.MaxInt64
+		} else {
+			storageUsed = int64(stat.RepoSize)
+		}
+	}
+
+	req := &nodepb.HeartbeatRequest{
+		NodeId:           a.NodeID(),
+		StorageUsedBytes: storageUsed,
+		UptimeSeconds:    int64(time.Since(a.startTime).Seconds()),
+	}
+	if minimal {
+		return req
+	}
+	req.StorageCapacityBytes = a.capacityBytes.Load()
+	req.Reachability = a.refreshReachability(ctx)
+	req.GatewayStatus = a.stats.GatewayStatus()
+	req.Group = a.config.Group
+	req.Health = healthProto(a.health.Status())
+	req.Resources = a.collectNodeStats().proto()
+	req.Network = a.collectNetworkStats(ctx)
+	if a.featureEnabled(featureCapacityBreakdown) {
+		req.CapacityBreakdown = a.capacityBreakdown(repoSize)
+	}
+	return req
+}
+
+// sendHeartbeat sends one heartbeat. If an extended heartbeat is rejected in a way that suggests the
+// coordinator could not decode or accept its fields, the heartbeat is resent with core fields only and the
+// agent stays in minimal mode, so extra fields never cause a heartbeat to be dropped.
+func (a *Agent) sendHeartbeat(ctx context.Context) error {
+	minimal := a.hbMinimal.Load()
+	resp, err := a.client.Heartbeat(a.authContext(ctx), a.buildHeartbeat(ctx, minimal))
+	if err == nil {
+		a.applyFeatureFlags(resp.FeatureFlags)
+	}
+	if err == nil || minimal || !heartbeatPayloadRejected(err) {
+		return err
+	}
+
+	a.logger.Warn("extended heartbeat rejected, retrying with core fields only", "error", err)
+	resp, retryErr := a.client.Heartbeat(a.authContext(ctx), a.buildHeartbeat(ctx, true))
+	if retryErr != nil {
+		return retryErr
+	}
+	a.applyFeatureFlags(resp.FeatureFlags)
+	a.logger.Warn("coordinator accepts only minimal heartbeats, disabling extended heartbeat fields")
+	a.hbMinimal.Store(true)
+	return nil
+}
+
+// heartbeatPayloadRejected reports whether err indicates the coordinator rejected the heartbeat payload
+// itself (a field it cannot decode or accept) rather than failed transiently, which would otherwise switch
+// the agent to minimal heartbeats for good.
+func heartbeatPayloadRejected(err error) bool {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.Unimplemented:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/health"
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// heartbeatRecorder is a coordinator Heartbeat RPC recording the context of every heartbeat.
+type heartbeatRecorder struct {
+	mu   sync.Mutex
+	ctxs []context.Context
+}
+
+func (r *heartbeatRecorder) heartbeat(ctx context.Context, _ *nodepb.HeartbeatRequest) (*nodepb.HeartbeatResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ctxs = append(r.ctxs, ctx)
+	return &nodepb.HeartbeatResponse{}, nil
+}
+
+func (r *heartbeatRecorder) sent() []context.Context {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]context.Context(nil), r.ctxs...)
+}
+
+// waitHeartbeats waits until r has recorded n heartbeats.
+func waitHeartbeats(t *testing.T, r *heartbeatRecorder, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(r.sent()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d heartbeats sent, want %d", len(r.sent()), n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHeartbeatsAcrossReconnect(t *testing.T) {
+	a, _ := newTestAgent(t, AgentConfig{HeartbeatInterval: time.Hour, HeartbeatGrace: time.Minute})
+	rec := &heartbeatRecorder{}
+	a.client = &fakeCoordinator{heartbeat: rec.heartbeat}
+	a.health = health.NewTracker()
+	a.heartbeats = newLoopGroup(context.Background())
+	a.noRcmgr = true
+	a.hbMinimal.Store(true)
+	defer a.heartbeats.Stop()
+
+	a.startHeartbeats()
+	waitHeartbeats(t, rec, 1)
+
+	// Re-registration after a reconnect restarts the heartbeat loop.
+	a.startHeartbeats()
+	if ctx := rec.sent()[0]; ctx.Err() == nil {
+		t.Fatal("heartbeat loop from before the reconnect still running")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := len(rec.sent()); n != 1 {
+		t.Fatalf("%d heartbeats sent across the reconnect, want the immediate one suppressed", n)
+	}
+
+	// Outside the grace window the restarted loop beats right away.
+	a.lastBeat.Store(time.Now().Add(-time.Hour).UnixNano())
+	a.startHeartbeats()
+	waitHeartbeats(t, rec, 2)
+}
+
+func TestHeartbeatPayloadRejected(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{status.Error(codes.InvalidArgument, "unknown field"), true},
+		{status.Error(codes.Unimplemented, "extended heartbeat"), true},
+		{status.Error(codes.Internal, "database unavailable"), false},
+		{status.Error(codes.ResourceExhausted, "rate limited"), false},
+		{status.Error(codes.Unavailable, "connection refused"), false},
+		{status.Error(codes.Unauthenticated, "token expired"), false},
+		{context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		if got := heartbeatPayloadRejected(tt.err); got != tt.want {
+			t.Errorf("heartbeatPayloadRejected(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSendHeartbeatKeepsExtendedFieldsAfterInternalError(t *testing.T) {
+	a, _ := newTestAgent(t, AgentConfig{})
+	a.health = health.NewTracker()
+	var err error
+	if a.inventory, err = inventory.Open(""); err != nil {
+		t.Fatal(err)
+	}
+	var minimalSent int
+	a.client = &fakeCoordinator{heartbeat: func(_ context.Context, req *nodepb.HeartbeatRequest) (*nodepb.HeartbeatResponse, error) {
+		if req.Resources == nil {
+			minimalSent++
+		}
+		return nil, status.Error(codes.Internal, "database unavailable")
+	}}
+
+	if err := a.sendHeartbeat(context.Background()); status.Code(err) != codes.Internal {
+		t.Fatalf("sendHeartbeat error = %v, want Internal", err)
+	}
+	if minimalSent != 0 || a.hbMinimal.Load() {
+		t.Fatalf("sent %d minimal heartbeats, minimal mode %v; want the extended heartbeat kept", minimalSent, a.hbMinimal.Load())
+	}
+}
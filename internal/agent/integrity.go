@@ -0,0 +1,64 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// verifyPinned checks that a freshly pinned DAG is fully stored locally. Only missing blocks fail the pin;
+// if the check itself fails (e.g. the API is briefly unreachable) the pin is reported as usual.
+func (a *Agent) verifyPinned(ctx context.Context, task *nodepb.PinTask) error {
+	blocks, err := a.ipfs.VerifyPin(ctx, task.Cid)
+	switch {
+	case errors.Is(err, ipfs.ErrContentIncomplete):
+		return fmt.Errorf("pin verification: %w", err)
+	case err != nil:
+		a.logger.Warn("pin verification failed, reporting pin unverified", "cid", task.Cid, "task_id", task.TaskId, "error", err)
+	default:
+		a.logger.Debug("pin verified", "cid", task.Cid, "task_id", task.TaskId, "blocks", blocks)
+	}
+	return nil
+}
+
+// verifyInventorySample checks ReconcileVerifyPins randomly chosen inventory pins block by block, so
+// content lost or corrupted in the blockstore is found even though its pin is still listed. Incomplete
+// pins are dropped from the inventory and reported as failed, letting the coordinator re-replicate them.
+func (a *Agent) verifyInventorySample(ctx context.Context) {
+	if a.config.ReconcileVerifyPins <= 0 {
+		return
+	}
+	records := a.inventory.Records()
+	rand.Shuffle(len(records), func(i, j int) { records[i], records[j] = records[j], records[i] })
+	checked, incomplete := 0, 0
+	for _, rec := range records[:min(a.config.ReconcileVerifyPins, len(records))] {
+		if ctx.Err() != nil {
+			return
+		}
+		_, err := a.ipfs.VerifyPin(ctx, rec.CID)
+		if err != nil && !errors.Is(err, ipfs.ErrContentIncomplete) {
+			a.logger.Warn("pin integrity check failed", "cid", rec.CID, "error", err)
+			continue
+		}
+		checked++
+		if err == nil {
+			continue
+		}
+		incomplete++
+		a.logger.Error("pinned content incomplete in the blockstore", "cid", rec.CID, "task_id", rec.TaskID, "error", err)
+		if err := a.inventory.Remove(rec.CID); err != nil {
+			a.logger.Warn("failed to update inventory", "cid", rec.CID, "error", err)
+		}
+		task := &nodepb.PinTask{TaskId: rec.TaskID, Cid: rec.CID}
+		_ = a.reportStatus(ctx, task, nodepb.ReportPinStatusRequest_PIN_STATUS_FAILED,
+			"content incomplete (found by integrity check): "+err.Error())
+	}
+	a.logger.Info("pin integrity check completed", "checked", checked, "incomplete", incomplete)
+}
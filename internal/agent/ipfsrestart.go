@@ -0,0 +1,101 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// ipfsRestartWait bounds how long restartIPFS blocks its caller waiting for in-flight pins.
+const ipfsRestartWait = time.Minute
+
+// syncPrivateNetwork writes the swarm key and peers from a GetPeers response into the IPFS config as the
+// swarm key and bootstrap peers. kubo reads both only at startup, but the daemon is restarted only for a
+// new swarm key: changed bootstrap peers are used from the next start, and the peers are dialed directly
+// meanwhile.
+func (a *Agent) syncPrivateNetwork(ctx context.Context, resp *nodepb.GetPeersResponse) {
+	keyChanged, err := a.ipfsManager.UpdatePrivateNetwork(resp.SwarmKey, bootstrapAddrs(resp.Peers))
+	if err != nil {
+		a.logger.Warn("failed to update IPFS private network config", "error", err)
+		return
+	}
+	if !keyChanged {
+		return
+	}
+	if err := a.restartIPFS(ctx, "swarm key rotated"); err != nil {
+		a.logger.Error("failed to restart IPFS daemon", "error", err)
+	}
+}
+
+// bootstrapAddrs returns the peers' multiaddrs as bootstrap entries, which kubo requires to end in the
+// peer ID (/p2p/<id>). Addresses without one get the peer's ID appended, or are skipped if it is unknown.
+func bootstrapAddrs(peers []*nodepb.PeerInfo) []string {
+	var addrs []string
+	for _, peer := range peers {
+		for _, addr := range peer.Multiaddrs {
+			switch {
+			case strings.Contains(addr, "/p2p/"):
+				addrs = append(addrs, addr)
+			case peer.PeerId != "":
+				addrs = append(addrs, addr+"/p2p/"+peer.PeerId)
+			}
+		}
+	}
+	return addrs
+}
+
+// restartIPFS restarts the IPFS daemon once the pins running now have finished. Pins started meanwhile
+// wait until the daemon is back, so no pin is cut off mid-transfer and reported as failed. If the running
+// pins take longer than ipfsRestartWait, or ctx ends first, restartIPFS returns and the restart happens in
+// the background once they finish; a restart requested while one is pending is folded into it, since the
+// daemon reads the latest config when it starts.
+func (a *Agent) restartIPFS(ctx context.Context, reason string) error {
+	if !a.restartQueued.CompareAndSwap(false, true) {
+		a.logger.Info("IPFS daemon restart already pending", "reason", reason)
+		return nil
+	}
+	a.logger.Info("restarting IPFS daemon once in-flight pins finish", "reason", reason)
+	locked := make(chan struct{})
+	go func() {
+		a.ipfsRestart.Lock()
+		close(locked)
+	}()
+
+	timer := time.NewTimer(ipfsRestartWait)
+	defer timer.Stop()
+	select {
+	case <-locked:
+		return a.restartLocked(ctx, reason)
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	a.logger.Warn("in-flight pins still running, IPFS daemon restart continues in the background", "reason", reason)
+	go func() {
+		<-locked
+		if err := a.restartLocked(ctx, reason); err != nil {
+			a.logger.Error("failed to restart IPFS daemon", "error", err)
+		}
+	}()
+	return nil
+}
+
+// restartLocked restarts the daemon with ipfsRestart write-held, then releases it and clears the pending
+// restart. The restart is skipped if ctx has ended, e.g. because the agent is shutting down.
+func (a *Agent) restartLocked(ctx context.Context, reason string) error {
+	defer a.restartQueued.Store(false)
+	defer a.ipfsRestart.Unlock()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("restart IPFS daemon (%s): %w", reason, err)
+	}
+	if err := a.ipfsManager.RestartDaemon(ctx); err != nil {
+		return fmt.Errorf("restart IPFS daemon (%s): %w", reason, err)
+	}
+	a.logger.Info("IPFS daemon restarted", "reason", reason)
+	return nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRestartIPFSReturnsWhileWaitingForPins(t *testing.T) {
+	a := &Agent{logger: testLogger()}
+	a.ipfsRestart.RLock() // A pin that outlives the caller's patience
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- a.restartIPFS(ctx, "test") }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("restartIPFS = %v, want nil after handing off to the background", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("restartIPFS blocked behind an in-flight pin")
+	}
+	if !a.restartQueued.Load() {
+		t.Fatal("no restart pending after handing off to the background")
+	}
+	if err := a.restartIPFS(context.Background(), "again"); err != nil {
+		t.Fatalf("second restartIPFS = %v, want it folded into the pending restart", err)
+	}
+
+	// Once the pin finishes, the background restart sees the expired context and stands down.
+	a.ipfsRestart.RUnlock()
+	deadline := time.Now().Add(5 * time.Second)
+	for a.restartQueued.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("pending restart never cleared")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !a.ipfsRestart.TryLock() {
+		t.Fatal("background restart did not release the restart lock")
+	}
+}
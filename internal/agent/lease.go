@@ -0,0 +1,95 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errLeaseLost is the cancellation cause of a task whose lease could not be renewed before it expired.
+var errLeaseLost = errors.New("task lease lost")
+
+// maxLeaseRenewFailures is the number of consecutive failed renewals after which the lease is given up
+// even if it has not expired yet.
+const maxLeaseRenewFailures = 3
+
+// holdLease keeps the coordinator-issued lease on task alive while it is being worked on, renewing it at a
+// third of the lease duration so the coordinator does not redeliver it. The returned context is canceled
+// with cause errLeaseLost once renewal keeps failing, since another node has likely taken the task; the
+// caller must call release when done. Tasks without a lease, and coordinators without lease renewal
+// support, are worked on without renewal.
+func (a *Agent) holdLease(ctx context.Context, task *nodepb.PinTask) (leaseCtx context.Context, release func()) {
+	if task.LeaseSeconds <= 0 {
+		return ctx, func() {}
+	}
+	leaseCtx, cancel := context.WithCancelCause(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		a.renewLease(leaseCtx, task, cancel)
+	}()
+	return leaseCtx, func() {
+		cancel(nil)
+		<-done
+	}
+}
+
+// renewLease renews task's lease until ctx is done, canceling with errLeaseLost when the lease expires
+// without a successful renewal or renewal fails maxLeaseRenewFailures times in a row.
+func (a *Agent) renewLease(ctx context.Context, task *nodepb.PinTask, cancel context.CancelCauseFunc) {
+	lease := time.Duration(task.LeaseSeconds) * time.Second
+	expires := time.Now().Add(lease)
+	failures := 0
+	timer := time.NewTimer(lease / 3)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+
+		resp, err := a.client.RenewPinTaskLease(a.authContext(ctx), &nodepb.RenewPinTaskLeaseRequest{
+			NodeId: a.NodeID(),
+			TaskId: task.TaskId,
+		})
+		if status.Code(err) == codes.Unimplemented {
+			a.logger.Debug("coordinator does not support task lease renewal", "task_id", task.TaskId)
+			return
+		}
+		if err == nil && resp.Error != "" {
+			err = fmt.Errorf("coordinator rejected lease renewal: %s", resp.Error)
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			failures++
+			a.logger.Warn("task lease renewal failed", "task_id", task.TaskId, "attempt", failures, "error", err)
+			if failures >= maxLeaseRenewFailures || !time.Now().Before(expires) {
+				a.logger.Warn("task lease lost, abandoning task to the coordinator", "task_id", task.TaskId, "cid", task.Cid)
+				cancel(errLeaseLost)
+				return
+			}
+			// Retry sooner, but not after the lease expires.
+			timer.Reset(min(lease/6, max(time.Until(expires), time.Second)))
+			continue
+		}
+
+		failures = 0
+		if resp.LeaseSeconds > 0 {
+			lease = time.Duration(resp.LeaseSeconds) * time.Second
+		}
+		expires = time.Now().Add(lease)
+		timer.Reset(lease / 3)
+	}
+}
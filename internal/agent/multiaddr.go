@@ -0,0 +1,185 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// maComponent is one protocol of a multiaddr with its value, if the protocol takes one.
+type maComponent struct {
+	proto string
+	value string
+}
+
+// Kinds of multiaddr protocol values.
+const (
+	maNoValue = iota // The protocol takes no value, e.g. /ws
+	maValue          // The protocol takes one value, e.g. /tcp/4001
+	maPath           // The protocol's value is the rest of the address, e.g. /unix/run/ipfs.sock
+)
+
+// maProtocols lists the multiaddr protocols by name with the kind of value each takes, following the
+// multiformats multiaddr protocol table (github.com/multiformats/multiaddr).
+var maProtocols = map[string]int{
+	"ip4": maValue, "ip6": maValue, "ip6zone": maValue, "ipcidr": maValue,
+	"dns": maValue, "dns4": maValue, "dns6": maValue, "dnsaddr": maValue,
+	"tcp": maValue, "udp": maValue, "dccp": maValue, "sctp": maValue,
+	"p2p": maValue, "ipfs": maValue, "onion": maValue, "onion3": maValue, "garlic32": maValue, "garlic64": maValue,
+	"sni": maValue, "certhash": maValue, "memory": maValue, "http-path": maValue,
+	"unix": maPath,
+	"quic": maNoValue, "quic-v1": maNoValue, "tls": maNoValue, "noise": maNoValue, "ws": maNoValue,
+	"wss": maNoValue, "webtransport": maNoValue, "webrtc": maNoValue, "webrtc-direct": maNoValue,
+	"p2p-circuit": maNoValue, "http": maNoValue, "https": maNoValue, "utp": maNoValue, "udt": maNoValue,
+	"plaintextv2": maNoValue,
+}
+
+// parseMultiaddr splits a multiaddr string into its components, using the protocol table to tell
+// protocol names from values, so a DNS name such as /dns4/ws/... is not mistaken for a protocol.
+func parseMultiaddr(addr string) ([]maComponent, error) {
+	if !strings.HasPrefix(addr, "/") {
+		return nil, fmt.Errorf("multiaddr %q does not start with /", addr)
+	}
+	parts := strings.Split(strings.TrimSuffix(addr[1:], "/"), "/")
+	var comps []maComponent
+	for i := 0; i < len(parts); i++ {
+		kind, ok := maProtocols[parts[i]]
+		if !ok {
+			return nil, fmt.Errorf("multiaddr %q: unknown protocol %q", addr, parts[i])
+		}
+		comp := maComponent{proto: parts[i]}
+		switch kind {
+		case maValue:
+			if i+1 >= len(parts) || parts[i+1] == "" {
+				return nil, fmt.Errorf("multiaddr %q: %s lacks a value", addr, parts[i])
+			}
+			i++
+			comp.value = parts[i]
+		case maPath:
+			comp.value = "/" + strings.Join(parts[i+1:], "/")
+			i = len(parts)
+		}
+		comps = append(comps, comp)
+	}
+	if len(comps) == 0 {
+		return nil, fmt.Errorf("empty multiaddr")
+	}
+	return comps, nil
+}
+
+// Multiaddr reachability ranks, lower is preferred when advertising addresses.
+const (
+	rankPublic = iota
+	rankPrivate
+	rankLocal
+)
+
+// multiaddrRank classifies a multiaddr string by how useful it is to remote peers: public/routable
+// addresses and DNS names first, then private (RFC 1918 / ULA) addresses, then loopback and link-local.
+func multiaddrRank(addr string) int {
+	comps, err := parseMultiaddr(addr)
+	if err != nil {
+		return rankLocal
+	}
+	switch first := comps[0]; first.proto {
+	case "ip4", "ip6":
+		ip, err := netip.ParseAddr(first.value)
+		if err != nil {
+			return rankLocal
+		}
+		switch {
+		case ip.IsLoopback(), ip.IsLinkLocalUnicast(), ip.IsUnspecified():
+			return rankLocal
+		case ip.IsPrivate():
+			return rankPrivate
+		default:
+			return rankPublic
+		}
+	case "dns", "dns4", "dns6", "dnsaddr":
+		if first.value == "localhost" {
+			return rankLocal
+		}
+		return rankPublic
+	default:
+		return rankLocal
+	}
+}
+
+// limitMultiaddrs returns at most max addresses, preferring public over private over local addresses
+// while preserving the original order within each rank. It also returns the number of addresses dropped.
+// A max of zero or less means no limit.
+func limitMultiaddrs(addrs []string, max int) ([]string, int) {
+	if max <= 0 || len(addrs) <= max {
+		return addrs, 0
+	}
+	sorted := make([]string, len(addrs))
+	copy(sorted, addrs)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return multiaddrRank(sorted[i]) < multiaddrRank(sorted[j])
+	})
+	return sorted[:max], len(addrs) - max
+}
+
+// Transport buckets reported to the coordinator at registration.
+const (
+	transportTCP          = "tcp"
+	transportQUIC         = "quic"
+	transportWS           = "ws"
+	transportWebTransport = "webtransport"
+	transportWebRTC       = "webrtc"
+	transportOther        = "other"
+)
+
+// multiaddrTransport returns the transport bucket of a multiaddr string. The outermost transport
+// protocol wins, so /udp/4001/quic-v1/webtransport is webtransport and /tcp/4001/tls/ws is ws. Relayed
+// addresses and addresses that do not parse, e.g. with unknown protocols, are "other".
+func multiaddrTransport(addr string) string {
+	comps, err := parseMultiaddr(addr)
+	if err != nil {
+		return transportOther
+	}
+	transport := transportOther
+	for _, comp := range comps {
+		switch comp.proto {
+		case "p2p-circuit":
+			return transportOther
+		case "tcp":
+			transport = transportTCP
+		case "quic", "quic-v1":
+			transport = transportQUIC
+		case "ws", "wss":
+			transport = transportWS
+		case "webtransport":
+			transport = transportWebTransport
+		case "webrtc", "webrtc-direct":
+			transport = transportWebRTC
+		}
+	}
+	return transport
+}
+
+// transportSummary groups addrs by transport, preserving their order within each transport. Transports
+// are listed in alphabetical order so the summary is stable across registrations.
+func transportSummary(addrs []string) []*nodepb.TransportAddrs {
+	byTransport := make(map[string][]string)
+	for _, addr := range addrs {
+		t := multiaddrTransport(addr)
+		byTransport[t] = append(byTransport[t], addr)
+	}
+	transports := make([]string, 0, len(byTransport))
+	for t := range byTransport {
+		transports = append(transports, t)
+	}
+	sort.Strings(transports)
+	summary := make([]*nodepb.TransportAddrs, 0, len(transports))
+	for _, t := range transports {
+		summary = append(summary, &nodepb.TransportAddrs{Transport: t, Multiaddrs: byTransport[t]})
+	}
+	return summary
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import "testing"
+
+func TestMultiaddrTransport(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"/ip4/203.0.113.7/tcp/4001", transportTCP},
+		{"/ip6/2001:db8::1/udp/4001/quic-v1", transportQUIC},
+		{"/ip4/203.0.113.7/udp/4001/quic-v1/webtransport/certhash/uEiAkH5a4DPGKUuOBjYw0CgwjvcJCJMD2K_1aluKR_tpevQ", transportWebTransport},
+		{"/ip4/203.0.113.7/udp/4001/webrtc-direct/certhash/uEiAkH5a4DPGKUuOBjYw0CgwjvcJCJMD2K_1aluKR_tpevQ", transportWebRTC},
+		{"/dns4/node.example.com/tcp/443/tls/sni/node.example.com/ws", transportWS},
+		{"/dns4/tcp/udp/4001/quic-v1", transportQUIC}, // A host named "tcp"
+		{"/dns4/ws/tcp/4001", transportTCP},           // A host named "ws"
+		{"/dns6/webtransport/tcp/4001/wss", transportWS},
+		{"/ip4/203.0.113.7/tcp/4001/p2p/12D3KooWGzBqYzBdt1Dn6XVPbDMhsxqWT4DdJc3hNFCvG9zBbcQp/p2p-circuit", transportOther},
+		{"/unix/run/tcp/ws.sock", transportOther}, // A path, not protocols
+		{"/ip4/203.0.113.7/tcp", transportOther},  // Missing port
+		{"/ip4/203.0.113.7/sctp/4001/bogus", transportOther},
+		{"ip4/203.0.113.7/tcp/4001", transportOther},
+		{"", transportOther},
+	}
+	for _, tt := range tests {
+		if got := multiaddrTransport(tt.addr); got != tt.want {
+			t.Errorf("multiaddrTransport(%q) = %s, want %s", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestMultiaddrRank(t *testing.T) {
+	tests := []struct {
+		addr string
+		want int
+	}{
+		{"/ip4/203.0.113.7/tcp/4001", rankPublic},
+		{"/ip4/10.1.2.3/tcp/4001", rankPrivate},
+		{"/ip6/fd00::1/udp/4001/quic-v1", rankPrivate},
+		{"/ip4/127.0.0.1/tcp/4001", rankLocal},
+		{"/ip6/fe80::1/tcp/4001", rankLocal},
+		{"/dns4/ws/tcp/4001", rankPublic},
+		{"/dns/localhost/tcp/4001", rankLocal},
+		{"/ip4/not-an-ip/tcp/4001", rankLocal},
+		{"/ip4", rankLocal},
+	}
+	for _, tt := range tests {
+		if got := multiaddrRank(tt.addr); got != tt.want {
+			t.Errorf("multiaddrRank(%q) = %d, want %d", tt.addr, got, tt.want)
+		}
+	}
+}
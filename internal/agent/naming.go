@@ -0,0 +1,63 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+
+	"github.com/wabisaby/wabisaby-node/internal/identity"
+)
+
+// Node name suffix strategies (node.name_suffix).
+const (
+	NameSuffixNone        = "none"         // Register with the configured name as-is
+	NameSuffixPeerID      = "peer_id"      // Append a short hash of the IPFS peer ID
+	NameSuffixIdentityKey = "identity_key" // Append a short hash of the node identity public key
+)
+
+// nameSuffixLength is the number of base32 characters appended to disambiguate node names.
+const nameSuffixLength = 6
+
+// shortStableSuffix returns a short lowercase base32 digest of seed, stable for a given seed.
+func shortStableSuffix(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum[:])
+	return strings.ToLower(encoded[:nameSuffixLength])
+}
+
+// baseName returns the configured node name. It is empty with node.name_strategy peer_id, in which case
+// the name is derived from the IPFS peer ID ("wabisaby-node" until the peer ID is known).
+func (a *Agent) baseName() string {
+	if a.config.NodeName != "" {
+		return a.config.NodeName
+	}
+	if a.peerID == "" {
+		return "wabisaby-node"
+	}
+	return identity.NodeName([]byte(a.peerID))
+}
+
+// registrationName returns the node name sent to the coordinator, with a stable suffix appended according
+// to the configured strategy. If the seed for the suffix is not available yet (e.g. the peer ID before IPFS
+// is up), the name is returned unchanged.
+func (a *Agent) registrationName() string {
+	name := a.baseName()
+	var seed []byte
+	switch a.config.NameSuffix {
+	case NameSuffixPeerID:
+		if a.peerID != "" {
+			seed = []byte(a.peerID)
+		}
+	case NameSuffixIdentityKey:
+		if pub := a.signer.PublicKey(); pub != nil {
+			seed = pub
+		}
+	}
+	if seed == nil {
+		return name
+	}
+	return name + "-" + shortStableSuffix(seed)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/wabisaby/wabisaby-node/internal/identity"
+)
+
+func TestBaseNameStrategies(t *testing.T) {
+	const peerID = "12D3KooWGzBqYzBdt1Dn6XVPbDMhsxqWT4DdJc3hNFCvG9zBbcQp"
+	tests := []struct {
+		name     string
+		nodeName string // Name resolved by the config for the hostname and identity_key strategies; empty for peer_id
+		peerID   string
+		want     string
+	}{
+		{"hostname", "wabisaby-node-host1-alice", peerID, "wabisaby-node-host1-alice"},
+		{"identity_key", "wabisaby-abcdefghij", peerID, "wabisaby-abcdefghij"},
+		{"peer_id before IPFS is up", "", "", "wabisaby-node"},
+		{"peer_id", "", peerID, identity.NodeName([]byte(peerID))},
+	}
+	for _, tt := range tests {
+		a := &Agent{config: AgentConfig{NodeName: tt.nodeName}, peerID: tt.peerID}
+		if got := a.baseName(); got != tt.want {
+			t.Errorf("%s: baseName = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRegistrationNameSuffixes(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const peerID = "12D3KooWGzBqYzBdt1Dn6XVPbDMhsxqWT4DdJc3hNFCvG9zBbcQp"
+	signer := identity.NewSigner(key)
+	tests := []struct {
+		suffix string
+		signer *identity.Signer
+		peerID string
+		want   string
+	}{
+		{NameSuffixNone, signer, peerID, "node"},
+		{NameSuffixPeerID, signer, peerID, "node-" + shortStableSuffix([]byte(peerID))},
+		{NameSuffixPeerID, signer, "", "node"},
+		{NameSuffixIdentityKey, signer, peerID, "node-" + shortStableSuffix(signer.PublicKey())},
+		{NameSuffixIdentityKey, nil, peerID, "node"},
+	}
+	for _, tt := range tests {
+		a := &Agent{config: AgentConfig{NodeName: "node", NameSuffix: tt.suffix}, signer: tt.signer, peerID: tt.peerID}
+		if got := a.registrationName(); got != tt.want {
+			t.Errorf("suffix %s (peer ID %q, signer %v): registrationName = %q, want %q",
+				tt.suffix, tt.peerID, tt.signer != nil, got, tt.want)
+		}
+	}
+	if s := shortStableSuffix([]byte(peerID)); len(s) != nameSuffixLength || strings.ToLower(s) != s {
+		t.Fatalf("suffix %q, want %d lowercase characters", s, nameSuffixLength)
+	}
+}
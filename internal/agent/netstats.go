@@ -0,0 +1,40 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// collectNetworkStats reads the IPFS node's bitswap and bandwidth counters for extended heartbeats, so the
+// coordinator can spot nodes with poor connectivity. Failures are logged at debug level and leave the
+// affected counters zero; nil is returned if neither could be read.
+func (a *Agent) collectNetworkStats(ctx context.Context) *nodepb.NodeNetwork {
+	bitswap, bsErr := a.ipfs.BitswapStat(ctx)
+	if bsErr != nil {
+		a.logger.Debug("failed to read bitswap stats", "error", bsErr)
+	}
+	bw, bwErr := a.ipfs.BandwidthStat(ctx)
+	if bwErr != nil {
+		a.logger.Debug("failed to read bandwidth stats", "error", bwErr)
+	}
+	if bsErr != nil && bwErr != nil {
+		return nil
+	}
+
+	n := &nodepb.NodeNetwork{}
+	if bitswap != nil {
+		n.BlocksReceived = bitswap.BlocksReceived
+		n.BlocksSent = bitswap.BlocksSent
+	}
+	if bw != nil {
+		n.BytesIn = bw.TotalIn
+		n.BytesOut = bw.TotalOut
+		n.RateInBytesPerSecond = bw.RateIn
+		n.RateOutBytesPerSecond = bw.RateOut
+	}
+	return n
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"errors"
+	"runtime"
+
+	"github.com/wabisaby/wabisaby-node/internal/diskstat"
+	"github.com/wabisaby/wabisaby-node/internal/sysstat"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// nodeStats are the host resource signals sent in extended heartbeats for the coordinator's scheduler.
+// Statistics that could not be read are zero.
+type nodeStats struct {
+	LoadAverage  float64 // One-minute load average
+	CPUs         int     // Logical CPUs usable by the process
+	MemTotal     uint64  // Physical memory in bytes
+	MemAvailable uint64  // Memory available without swapping, in bytes
+	DiskFree     uint64  // Free space on the IPFS data dir's filesystem, in bytes
+}
+
+// Sources of host statistics, replaceable so collectNodeStats can run without a live system.
+var (
+	loadAverage   = sysstat.LoadAverage
+	readMemory    = sysstat.ReadMemory
+	diskAvailable = diskstat.AvailableBytes
+)
+
+// collectNodeStats gathers CPU load, memory usage and free disk space on the IPFS data dir. Failures are
+// logged at debug level, since they would repeat on every heartbeat, and leave the statistic zero.
+func (a *Agent) collectNodeStats() nodeStats {
+	s := nodeStats{CPUs: runtime.NumCPU()}
+	if load, err := loadAverage(); err == nil {
+		s.LoadAverage = load
+	} else if !errors.Is(err, sysstat.ErrUnsupported) {
+		a.logger.Debug("failed to read load average", "error", err)
+	}
+	if mem, err := readMemory(); err == nil {
+		s.MemTotal, s.MemAvailable = mem.Total, mem.Available
+	} else if !errors.Is(err, sysstat.ErrUnsupported) {
+		a.logger.Debug("failed to read memory usage", "error", err)
+	}
+	dir := a.config.IPFSDataDir
+	if dir == "" {
+		dir = "."
+	}
+	if free, err := diskAvailable(dir); err == nil {
+		s.DiskFree = free
+	} else {
+		a.logger.Debug("failed to read free disk space", "path", dir, "error", err)
+	}
+	return s
+}
+
+// proto converts s to its heartbeat representation.
+func (s nodeStats) proto() *nodepb.NodeResources {
+	return &nodepb.NodeResources{
+		LoadAverage_1M:       s.LoadAverage,
+		CpuCount:             int32(s.CPUs),
+		MemoryTotalBytes:     s.MemTotal,
+		MemoryAvailableBytes: s.MemAvailable,
+		DiskFreeBytes:        s.DiskFree,
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/fsutil"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errCoordinatorUnreachable is returned (wrapped) when the coordinator connection cannot be established.
+var errCoordinatorUnreachable = errors.New("coordinator unreachable")
+
+// Backoff between startup attempts to reach the coordinator while it is unreachable.
+const (
+	minStartupRetry = 5 * time.Second
+	maxStartupRetry = 2 * time.Minute
+)
+
+// cachedPeer is a peer as stored in the peer cache.
+type cachedPeer struct {
+	PeerID     string   `json:"peer_id"`
+	Region     string   `json:"region,omitempty"`
+	Multiaddrs []string `json:"multiaddrs"`
+}
+
+// peerCache is the on-disk representation of the last peer list received from the coordinator.
+type peerCache struct {
+	SavedAt time.Time    `json:"saved_at"`
+	Peers   []cachedPeer `json:"peers"`
+}
+
+// savePeerCache persists peers as the last-known-good peer list.
+func savePeerCache(path string, peers []*nodepb.PeerInfo) error {
+	cache := peerCache{SavedAt: time.Now().UTC(), Peers: make([]cachedPeer, 0, len(peers))}
+	for _, p := range peers {
+		cache.Peers = append(cache.Peers, cachedPeer{PeerID: p.PeerId, Region: p.Region, Multiaddrs: p.Multiaddrs})
+	}
+	data, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode peer cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create peer cache directory: %w", err)
+	}
+	return fsutil.WriteFileAtomic(path, data, 0o644)
+}
+
+// loadPeerCache returns the cached peer list and when it was saved. A missing file yields no peers.
+func loadPeerCache(path string) ([]*nodepb.PeerInfo, time.Time, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, time.Time{}, nil
+	}
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var cache peerCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, time.Time{}, fmt.Errorf("parse peer cache %s: %w", path, err)
+	}
+	peers := make([]*nodepb.PeerInfo, 0, len(cache.Peers))
+	for _, p := range cache.Peers {
+		peers = append(peers, &nodepb.PeerInfo{PeerId: p.PeerID, Region: p.Region, Multiaddrs: p.Multiaddrs})
+	}
+	return peers, cache.SavedAt, nil
+}
+
+// seedPeersFromCache connects to the cached peer list, so the node can rejoin the swarm while the
+// coordinator is unavailable.
+func (a *Agent) seedPeersFromCache(ctx context.Context) {
+	peers, savedAt, err := loadPeerCache(a.config.PeerCacheFile)
+	if err != nil {
+		a.logger.Warn("failed to load peer cache", "error", err)
+		return
+	}
+	if len(peers) == 0 {
+		a.logger.Info("no cached peers to seed swarm connections from")
+		return
+	}
+	a.logger.Info("seeding swarm connections from peer cache", "peers", len(peers), "saved_at", savedAt)
+	if err := a.dialPeers(ctx, peers); err != nil {
+		a.logger.Warn("failed to connect to some cached peers", "error", err)
+	}
+}
+
+// coordinatorUnreachable reports whether err means the coordinator could not be reached, as opposed to
+// it rejecting the node.
+func coordinatorUnreachable(err error) bool {
+	if errors.Is(err, errCoordinatorUnreachable) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// retryWhileUnreachable runs step until it succeeds, fails for a reason other than the coordinator
+// being unreachable, or ctx is canceled. On the first unreachable failure the swarm is seeded from the
+// peer cache in the background. Without the peer cache the first error is returned.
+func (a *Agent) retryWhileUnreachable(ctx context.Context, what string, step func(context.Context) error) error {
+	delay := minStartupRetry
+	seeded := false
+	for {
+		err := step(ctx)
+		if err == nil || !a.config.PeerCache || !coordinatorUnreachable(err) || ctx.Err() != nil {
+			return err
+		}
+		if !seeded {
+			seeded = true
+			go a.seedPeersFromCache(ctx)
+		}
+		a.logger.Warn("coordinator unreachable, retrying", "step", what, "error", err, "retry_in", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(delay*2, maxStartupRetry)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoadPeerCacheTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "peers.json")
+	peers := []*nodepb.PeerInfo{{PeerId: "12D3KooWA", Region: "eu-west", Multiaddrs: []string{"/ip4/10.0.0.1/tcp/4001"}}}
+	if err := savePeerCache(path, peers); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[:len(data)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _, err := loadPeerCache(path)
+	if err == nil {
+		t.Fatal("loadPeerCache accepted a truncated file")
+	}
+	if len(got) != 0 {
+		t.Fatalf("loadPeerCache returned %d peers from a truncated file", len(got))
+	}
+}
+
+func TestLoadPeerCacheMissing(t *testing.T) {
+	peers, savedAt, err := loadPeerCache(filepath.Join(t.TempDir(), "peers.json"))
+	if err != nil || len(peers) != 0 || !savedAt.IsZero() {
+		t.Fatalf("loadPeerCache(missing) = %v, %v, %v; want no peers and no error", peers, savedAt, err)
+	}
+}
+
+func TestColdStartFromPeerCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "peers.json")
+	a, fake := newTestAgent(t, AgentConfig{PeerCache: true, PeerCacheFile: cacheFile})
+	cached := []*nodepb.PeerInfo{
+		{PeerId: "12D3KooWA", Region: "eu-west", Multiaddrs: []string{"/ip4/10.0.0.1/tcp/4001"}},
+		{PeerId: "12D3KooWB", Region: "us-east", Multiaddrs: []string{"/ip4/10.0.0.2/tcp/4001"}},
+	}
+	if err := savePeerCache(cacheFile, cached); err != nil {
+		t.Fatal(err)
+	}
+	a.client = &fakeCoordinator{getPeers: func() (*nodepb.GetPeersResponse, error) {
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	}}
+
+	if err := a.connectToPeers(context.Background()); err != nil {
+		t.Fatalf("connectToPeers with an unreachable coordinator: %v", err)
+	}
+	if got, want := fake.connectedAddrs(), []string{"/ip4/10.0.0.1/tcp/4001", "/ip4/10.0.0.2/tcp/4001"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("connected to %v, want the cached peers %v", got, want)
+	}
+	if got, want := a.stats.Snapshot().PeersByRegion, map[string]int{"eu-west": 1, "us-east": 1}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("peers by region = %v, want %v", got, want)
+	}
+}
+
+func TestRetryWhileUnreachableSeedsFromCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "peers.json")
+	a, fake := newTestAgent(t, AgentConfig{PeerCache: true, PeerCacheFile: cacheFile})
+	cached := []*nodepb.PeerInfo{{PeerId: "12D3KooWA", Multiaddrs: []string{"/ip4/10.0.0.1/tcp/4001"}}}
+	if err := savePeerCache(cacheFile, cached); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	// Give up once the swarm has been seeded while the coordinator is still down.
+	go func() {
+		for len(fake.connectedAddrs()) == 0 && ctx.Err() == nil {
+			time.Sleep(10 * time.Millisecond)
+		}
+		cancel()
+	}()
+	attempts := 0
+	err := a.retryWhileUnreachable(ctx, "register", func(context.Context) error {
+		attempts++
+		return status.Error(codes.Unavailable, "connection refused")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("retryWhileUnreachable = %v, want context.Canceled", err)
+	}
+	if got := fake.connectedAddrs(); len(got) != 1 || got[0] != "/ip4/10.0.0.1/tcp/4001" {
+		t.Fatalf("connected to %v, want the cached peer", got)
+	}
+	if attempts != 1 {
+		t.Fatalf("%d attempts, want 1 before the retry delay", attempts)
+	}
+}
+
+func TestGetPeersRefreshesCache(t *testing.T) {
+	cacheFile := filepath.Join(t.TempDir(), "peers.json")
+	a, _ := newTestAgent(t, AgentConfig{PeerCache: true, PeerCacheFile: cacheFile})
+	peers := []*nodepb.PeerInfo{{PeerId: "12D3KooWC", Region: "ap-south", Multiaddrs: []string{"/ip4/10.0.0.3/tcp/4001"}}}
+	a.client = &fakeCoordinator{getPeers: func() (*nodepb.GetPeersResponse, error) {
+		return &nodepb.GetPeersResponse{Peers: peers}, nil
+	}}
+
+	if err := a.connectToPeers(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := loadPeerCache(cacheFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].PeerId != "12D3KooWC" || got[0].Region != "ap-south" {
+		t.Fatalf("cached peers = %v, want the peers from GetPeers", got)
+	}
+}
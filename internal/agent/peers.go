@@ -0,0 +1,163 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/events"
+	"github.com/wabisaby/wabisaby-node/internal/health"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// connectToPeers connects to peers returned by the coordinator, same-region peers first when configured,
+// stopping once MaxPeers peers are connected. With the peer cache enabled, a non-empty peer list is saved,
+// and the cached list is used instead when the coordinator cannot be reached.
+func (a *Agent) connectToPeers(ctx context.Context) error {
+	resp, err := a.client.GetPeers(a.authContext(ctx), &nodepb.GetPeersRequest{
+		NodeId: a.NodeID(),
+	})
+	if err != nil {
+		if a.config.PeerCache && coordinatorUnreachable(err) {
+			a.logger.Warn("failed to get peers from coordinator, using peer cache", "error", err)
+			a.seedPeersFromCache(ctx)
+			return nil
+		}
+		return fmt.Errorf("failed to get peers: %w", err)
+	}
+
+	if resp.Error != "" {
+		return fmt.Errorf("coordinator error: %s", resp.Error)
+	}
+	a.syncPrivateNetwork(ctx, resp)
+	// An empty list is not cached, so a transient coordinator glitch cannot wipe the last good one.
+	if a.config.PeerCache && len(resp.Peers) > 0 {
+		if err := savePeerCache(a.config.PeerCacheFile, resp.Peers); err != nil {
+			a.logger.Warn("failed to save peer cache", "error", err)
+		}
+	}
+	return a.dialPeers(ctx, resp.Peers)
+}
+
+// peerRefreshLoop reconnects to the coordinator's peers every PeerRefreshInterval, so peers that joined
+// or dropped since startup are reflected in the swarm and in the per-region peer counts.
+func (a *Agent) peerRefreshLoop(ctx context.Context) {
+	if a.config.PeerRefreshInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(a.config.PeerRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.connectToPeers(ctx); err != nil && ctx.Err() == nil {
+				a.logger.Warn("peer refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// dialPeers connects to peers, same-region peers first when configured, stopping once MaxPeers peers are
+// connected, and records the connected peers per region.
+func (a *Agent) dialPeers(ctx context.Context, peers []*nodepb.PeerInfo) error {
+	total := len(peers)
+	if a.config.PreferSameRegion {
+		peers = orderPeersByRegion(peers, a.config.Region)
+	}
+
+	connected := 0
+	connectedPeers := 0
+	byRegion := make(map[string]int)
+	for _, peer := range peers {
+		if a.config.MaxPeers > 0 && connectedPeers >= a.config.MaxPeers {
+			break
+		}
+		peerConnected := false
+		for _, multiaddr := range peer.Multiaddrs {
+			if err := a.ipfsManager.ConnectToPeer(ctx, multiaddr); err != nil {
+				if ctx.Err() != nil {
+					a.logger.Info("peer connection canceled", "connected", connected)
+					return ctx.Err()
+				}
+				a.logger.Warn("failed to connect to peer", "peer", multiaddr, "error", err)
+				continue
+			}
+			connected++
+			peerConnected = true
+		}
+		if peerConnected {
+			connectedPeers++
+			byRegion[peerRegion(peer)]++
+		}
+	}
+	a.stats.SetPeersByRegion(byRegion)
+
+	a.logger.Info("connected to peers", "connected", connected, "total", total, "by_region", byRegion)
+	return nil
+}
+
+// peerRegion returns the region reported for a peer, or "unknown" when the coordinator did not provide one.
+func peerRegion(peer *nodepb.PeerInfo) string {
+	if peer.Region == "" {
+		return "unknown"
+	}
+	return peer.Region
+}
+
+// orderPeersByRegion returns peers with those in region first, preserving the coordinator's order otherwise.
+// If the local region is unknown the input order is kept.
+func orderPeersByRegion(peers []*nodepb.PeerInfo, region string) []*nodepb.PeerInfo {
+	if region == "" || region == "unknown" {
+		return peers
+	}
+	ordered := make([]*nodepb.PeerInfo, len(peers))
+	copy(ordered, peers)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Region == region && ordered[j].Region != region
+	})
+	return ordered
+}
+
+// pauseSwarmPeers is the stats pause reason recorded while the swarm gate is closed.
+const pauseSwarmPeers = "insufficient swarm peers"
+
+// swarmGate reports whether the node has at least MinPeersForTasks connected swarm peers and may accept
+// tasks. paused is the gate's previous state; transitions are logged, reported in stats and sent as events.
+// A failed peer count is treated as zero peers.
+func (a *Agent) swarmGate(ctx context.Context, paused bool) bool {
+	if a.config.MinPeersForTasks <= 0 {
+		return true
+	}
+	peers, err := a.ipfs.SwarmPeerCount(ctx)
+	if err != nil {
+		a.logger.Warn("failed to count swarm peers", "error", err)
+		peers = 0
+	}
+	open := peers >= a.config.MinPeersForTasks
+	if open {
+		a.setHealth(health.ComponentPeers, health.SeverityOK, "")
+	} else {
+		a.setHealth(health.ComponentPeers, health.SeverityDegraded,
+			fmt.Sprintf("%d swarm peers connected, %d required to accept tasks", peers, a.config.MinPeersForTasks))
+	}
+	switch {
+	case !open && !paused:
+		a.logger.Warn("too few swarm peers, pausing task acceptance",
+			"peers", peers, "min_peers_for_tasks", a.config.MinPeersForTasks)
+		a.stats.PauseTasks(pauseSwarmPeers)
+		a.events.Notify(events.TasksPaused, "too few swarm peers to accept tasks",
+			map[string]any{"peers": peers, "min_peers": a.config.MinPeersForTasks})
+	case open && paused:
+		a.logger.Info("swarm peers connected, resuming task acceptance", "peers", peers)
+		a.stats.ResumeTasks(pauseSwarmPeers)
+		a.events.Notify(events.TasksResumed, "swarm peers connected, accepting tasks", map[string]any{"peers": peers})
+	}
+	return open
+}
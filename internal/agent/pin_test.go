@@ -0,0 +1,67 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+)
+
+const testCID = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+
+// newPinServer serves pin/ls, answering pinned or not pinned, and a pin/add that reports the CID as
+// already pinned. It returns the number of pin/add calls.
+func newPinServer(t *testing.T, pinned bool) (*ipfs.Client, *atomic.Int32) {
+	t.Helper()
+	var adds atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v0/pin/ls", func(w http.ResponseWriter, r *http.Request) {
+		if pinned {
+			io.WriteString(w, `{"Keys":{"`+testCID+`":{"Type":"recursive"}}}`)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, `{"Message":"path '`+testCID+`' is not pinned","Code":0,"Type":"error"}`)
+	})
+	mux.HandleFunc("POST /api/v0/pin/add", func(w http.ResponseWriter, r *http.Request) {
+		adds.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, `{"Message":"pin: `+testCID+` already pinned recursively","Code":0,"Type":"error"}`)
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return ipfs.NewClient(srv.URL), &adds
+}
+
+func TestPinAlreadyPinnedError(t *testing.T) {
+	a, _ := newTestAgent(t, AgentConfig{})
+	var adds *atomic.Int32
+	a.ipfs, adds = newPinServer(t, false)
+
+	if err := a.pin(context.Background(), testCID, ""); err != nil {
+		t.Fatalf("pin = %v, want success when IPFS reports the content as already pinned", err)
+	}
+	if n := adds.Load(); n != 1 {
+		t.Fatalf("pin/add called %d times, want 1", n)
+	}
+}
+
+func TestPinPrecheckSkipsPinnedContent(t *testing.T) {
+	a, _ := newTestAgent(t, AgentConfig{})
+	var adds *atomic.Int32
+	a.ipfs, adds = newPinServer(t, true)
+
+	if err := a.pin(context.Background(), testCID, ""); err != nil {
+		t.Fatalf("pin = %v, want success for content already pinned", err)
+	}
+	if n := adds.Load(); n != 0 {
+		t.Fatalf("pin/add called %d times after pin/ls showed the content pinned", n)
+	}
+}
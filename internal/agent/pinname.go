@@ -0,0 +1,35 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// pinNamePrefix prefixes the names of pins created for tasks without a coordinator-provided name.
+const pinNamePrefix = "wabisaby-task-"
+
+// pinName returns the IPFS pin name for task: the coordinator-provided pin name if set, the task ID
+// otherwise. It is empty when pin names are disabled.
+func (a *Agent) pinName(task *nodepb.PinTask) string {
+	if !a.config.PinNames {
+		return ""
+	}
+	if task.PinName != "" {
+		return task.PinName
+	}
+	return pinNamePrefix + task.TaskId
+}
+
+// pinNamed pins cid under name, logging when the daemon turns out not to support named pins.
+func (a *Agent) pinNamed(ctx context.Context, cid, name string) error {
+	supported := a.ipfs.PinNamesSupported()
+	err := a.ipfs.PinNamed(ctx, cid, name)
+	if name != "" && supported && !a.ipfs.PinNamesSupported() {
+		a.logger.Info("IPFS daemon does not support named pins, pinning without names")
+	}
+	return err
+}
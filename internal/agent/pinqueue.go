@@ -0,0 +1,131 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// completedTaskTTL is how long a finished task ID is remembered. A poll can return a task again until the
+// coordinator has processed its report; redeliveries within this window are ignored.
+const completedTaskTTL = 10 * time.Minute
+
+// pinQueue bounds the number of pins running at once and remembers which tasks are queued, running or
+// recently completed, so a task redelivered by a later poll is not processed twice.
+type pinQueue struct {
+	slots chan struct{} // One token per running pin; nil when concurrency is unlimited
+
+	mu        sync.Mutex
+	tasks     map[string]struct{}  // Task IDs queued or running
+	completed map[string]time.Time // Task IDs finished, with when they finished; evicted after completedTaskTTL
+	now       func() time.Time
+}
+
+// newPinQueue returns a queue running at most limit pins at once (0 = unlimited).
+func newPinQueue(limit int) *pinQueue {
+	q := &pinQueue{
+		tasks:     make(map[string]struct{}),
+		completed: make(map[string]time.Time),
+		now:       time.Now,
+	}
+	if limit > 0 {
+		q.slots = make(chan struct{}, limit)
+	}
+	return q
+}
+
+// claim records taskID as queued and reports whether it was not queued, running or recently completed.
+func (q *pinQueue) claim(taskID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.evictLocked()
+	if _, ok := q.tasks[taskID]; ok {
+		return false
+	}
+	if _, ok := q.completed[taskID]; ok {
+		return false
+	}
+	q.tasks[taskID] = struct{}{}
+	return true
+}
+
+// done records taskID as completed once its processing has finished.
+func (q *pinQueue) done(taskID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.tasks, taskID)
+	q.completed[taskID] = q.now()
+}
+
+// abandon drops a claim on taskID without recording it as completed, so a redelivery is processed.
+func (q *pinQueue) abandon(taskID string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	delete(q.tasks, taskID)
+}
+
+// evictLocked removes completed task IDs older than completedTaskTTL. q.mu must be held.
+func (q *pinQueue) evictLocked() {
+	now := q.now()
+	for id, at := range q.completed {
+		if now.Sub(at) > completedTaskTTL {
+			delete(q.completed, id)
+		}
+	}
+}
+
+// acquire waits for a free slot and reports whether it got one before ctx was done.
+func (q *pinQueue) acquire(ctx context.Context) bool {
+	if q.slots == nil {
+		return true
+	}
+	select {
+	case q.slots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release frees a slot taken by acquire.
+func (q *pinQueue) release() {
+	if q.slots != nil {
+		<-q.slots
+	}
+}
+
+// enqueuePin queues a task claimed with a.pinQueue.claim to be processed once fewer than MaxConcurrentPins
+// pins are running. Tasks still waiting when shutdown begins are handed back to the coordinator instead of
+// being started.
+func (a *Agent) enqueuePin(task *nodepb.PinTask) {
+	a.enqueuePinAttempt(task, 1)
+}
+
+// enqueuePinAttempt is enqueuePin for the given 1-based pin attempt; retryPin queues the later ones. The
+// task's claim is kept while a retry is pending, so a redelivery in between is still ignored.
+func (a *Agent) enqueuePinAttempt(task *nodepb.PinTask, attempt int) {
+	a.pins.Go(func(ctx context.Context) {
+		if !a.pinQueue.acquire(ctx) {
+			a.pinQueue.abandon(task.TaskId)
+			a.releaseTasks(ctx, []*nodepb.PinTask{task}, "node shutting down")
+			return
+		}
+		defer a.pinQueue.release()
+		if a.draining.Load() {
+			a.pinQueue.abandon(task.TaskId)
+			a.releaseTasks(ctx, []*nodepb.PinTask{task}, "node shutting down")
+			return
+		}
+		// A daemon restart waits for running pins; pins started meanwhile wait for the restart.
+		a.ipfsRestart.RLock()
+		defer a.ipfsRestart.RUnlock()
+		if a.processTask(ctx, task, attempt) {
+			a.pinQueue.done(task.TaskId)
+		}
+	})
+}
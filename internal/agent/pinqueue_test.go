@@ -0,0 +1,129 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	"github.com/wabisaby/wabisaby-node/internal/taskstate"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+func TestDispatchTasksPinsRedeliveredTaskOnce(t *testing.T) {
+	a, f := newTestAgent(t, AgentConfig{})
+	var err error
+	if a.inventory, err = inventory.Open(""); err != nil {
+		t.Fatal(err)
+	}
+	if a.taskState, err = taskstate.Open(""); err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var reported []string
+	a.client = &fakeCoordinator{reportPinStatus: func(req *nodepb.ReportPinStatusRequest) (*nodepb.ReportPinStatusResponse, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported = append(reported, req.TaskId+" "+req.Status.String())
+		return &nodepb.ReportPinStatusResponse{Success: true}, nil
+	}}
+	a.pinQueue = newPinQueue(0)
+	a.pins = newLoopGroup(context.Background())
+	a.taskLoops = newLoopGroup(context.Background())
+	hold := make(chan struct{})
+	f.holdAdd = hold
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	poll := func() {
+		a.dispatchTasks(ctx, []*nodepb.PinTask{{TaskId: "task-1", Cid: testCID}})
+	}
+
+	// The first delivery is still pinning while later polls return the task again.
+	poll()
+	for len(f.addedCIDs()) == 0 {
+		if ctx.Err() != nil {
+			t.Fatal("first delivery never reached pin/add")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	poll()
+	poll()
+	close(hold)
+	if !a.pins.Wait(ctx) {
+		t.Fatal("pin did not finish")
+	}
+
+	// Redelivered after completion, before the coordinator has settled the report.
+	poll()
+	if !a.pins.Wait(ctx) {
+		t.Fatal("redelivery did not finish")
+	}
+	if added := f.addedCIDs(); len(added) != 1 {
+		t.Fatalf("pin/add called %d times, want 1", len(added))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(reported, []string{"task-1 PIN_STATUS_PINNED"}) {
+		t.Fatalf("reported %v, want task-1 pinned once", reported)
+	}
+	if n := a.stats.Snapshot().TasksReceived; n != 1 {
+		t.Fatalf("%d tasks received, want 1", n)
+	}
+}
+
+func TestPinQueueCompletedTaskEvictedAfterTTL(t *testing.T) {
+	q := newPinQueue(0)
+	now := time.Now()
+	q.now = func() time.Time { return now }
+
+	if !q.claim("task-1") {
+		t.Fatal("first claim refused")
+	}
+	q.done("task-1")
+	now = now.Add(completedTaskTTL - time.Second)
+	if q.claim("task-1") {
+		t.Fatal("completed task claimed again within the TTL")
+	}
+	now = now.Add(2 * time.Second)
+	if !q.claim("task-1") {
+		t.Fatal("completed task not claimable after the TTL")
+	}
+	if len(q.completed) != 0 {
+		t.Fatalf("%d completed tasks remembered after eviction, want 0", len(q.completed))
+	}
+}
+
+func TestPinQueueAbandonAllowsRedelivery(t *testing.T) {
+	q := newPinQueue(0)
+	if !q.claim("task-1") {
+		t.Fatal("first claim refused")
+	}
+	if q.claim("task-1") {
+		t.Fatal("queued task claimed twice")
+	}
+	q.abandon("task-1")
+	if !q.claim("task-1") {
+		t.Fatal("abandoned task not claimable")
+	}
+}
+
+func TestPinQueueLimitsConcurrentPins(t *testing.T) {
+	q := newPinQueue(1)
+	if !q.acquire(context.Background()) {
+		t.Fatal("first acquire failed")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if q.acquire(ctx) {
+		t.Fatal("second pin started while the only slot was taken")
+	}
+	q.release()
+	if !q.acquire(context.Background()) {
+		t.Fatal("acquire failed after release")
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// errPinTimeout is the cancellation cause of a pin attempt that ran longer than PinTimeout. Unlike
+// preemption it is retryable: the next attempt starts from the blocks already fetched.
+var errPinTimeout = errors.New("pin timed out")
+
+// Delays before retrying a failed pin within a task, doubling per retry.
+const (
+	minPinRetry = 5 * time.Second
+	maxPinRetry = 2 * time.Minute
+)
+
+// retryPin schedules the attempt after a failed one for task, up to MaxPinRetries retries with
+// exponential backoff. The backoff holds neither a pin slot nor the IPFS restart lock: the task waits in
+// the pins group, keeping its lease alive, and is enqueued again once the delay has passed. If shutdown
+// begins first the task is handed back to the coordinator; if its lease is lost it is left to the node
+// the coordinator hands it to.
+func (a *Agent) retryPin(task *nodepb.PinTask, attempt int, err error) {
+	wait := jitter(pinRetryDelay(attempt))
+	a.logger.Warn("pin failed, retrying", "cid", task.Cid, "task_id", task.TaskId,
+		"attempt", attempt, "retry_in", wait.Round(time.Second), "error", err)
+	a.pins.Go(func(ctx context.Context) {
+		leaseCtx, release := a.holdLease(ctx, task)
+		defer release()
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			a.enqueuePinAttempt(task, attempt+1)
+			return
+		case <-leaseCtx.Done():
+		case <-a.taskLoops.ctx.Done():
+		}
+		a.pinQueue.abandon(task.TaskId)
+		if errors.Is(context.Cause(leaseCtx), errLeaseLost) {
+			a.logger.Warn("dropped pin retry after losing the task lease", "task_id", task.TaskId, "cid", task.Cid)
+			return
+		}
+		a.releaseTasks(ctx, []*nodepb.PinTask{task}, "node shutting down")
+	})
+}
+
+// pinRetryDelay returns the backoff before the retry following attempt: minPinRetry, doubling per attempt
+// up to maxPinRetry.
+func pinRetryDelay(attempt int) time.Duration {
+	delay := minPinRetry
+	for range attempt - 1 {
+		if delay >= maxPinRetry {
+			break
+		}
+		delay *= 2
+	}
+	return min(delay, maxPinRetry)
+}
+
+// retryablePinError reports whether a failed pin may succeed when retried. Invalid CIDs and rejected
+// credentials fail the same way again, and an open circuit breaker hands the task back instead.
+func retryablePinError(err error) bool {
+	return !errors.Is(err, ipfs.ErrInvalidCID) &&
+		!errors.Is(err, ipfs.ErrUnauthorized) &&
+		!errors.Is(err, ipfs.ErrCircuitOpen)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+	"github.com/wabisaby/wabisaby-node/internal/taskstate"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+func TestRetryablePinError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("context deadline exceeded"), true},
+		{fmt.Errorf("IPFS pin/add failed with status 401: %w", ipfs.ErrUnauthorized), false},
+		{fmt.Errorf("IPFS pin/add failed with status 500: bad cid: %w", ipfs.ErrInvalidCID), false},
+		{fmt.Errorf("pin/add: %w", ipfs.ErrCircuitOpen), false},
+	}
+	for _, tt := range tests {
+		if got := retryablePinError(tt.err); got != tt.want {
+			t.Errorf("retryablePinError(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestPinRetryDelay(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{1: 5 * time.Second, 2: 10 * time.Second, 3: 20 * time.Second, 10: maxPinRetry} {
+		if got := pinRetryDelay(attempt); got != want {
+			t.Errorf("pinRetryDelay(%d) = %s, want %s", attempt, got, want)
+		}
+	}
+}
+
+func TestPinRetryBackoffHoldsNoSlot(t *testing.T) {
+	a, _ := newTestAgent(t, AgentConfig{MaxPinRetries: 3})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "daemon hiccup", http.StatusInternalServerError)
+	}))
+	t.Cleanup(srv.Close)
+	a.ipfs = ipfs.NewClient(srv.URL)
+	var err error
+	if a.inventory, err = inventory.Open(""); err != nil {
+		t.Fatal(err)
+	}
+	if a.taskState, err = taskstate.Open(""); err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var reported, nacked []string
+	a.client = &fakeCoordinator{
+		reportPinStatus: func(req *nodepb.ReportPinStatusRequest) (*nodepb.ReportPinStatusResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, req.TaskId)
+			return &nodepb.ReportPinStatusResponse{Success: true}, nil
+		},
+		nackPinTask: func(req *nodepb.NackPinTaskRequest) (*nodepb.NackPinTaskResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			nacked = append(nacked, req.TaskId)
+			return &nodepb.NackPinTaskResponse{}, nil
+		},
+	}
+	a.pinQueue = newPinQueue(1)
+	a.pins = newLoopGroup(context.Background())
+	a.taskLoops = newLoopGroup(context.Background())
+
+	task := &nodepb.PinTask{TaskId: "task-1", Cid: "bafy1"}
+	a.pinQueue.claim(task.TaskId)
+	a.enqueuePin(task)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for a.stats.Snapshot().PinsFailed == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("first pin attempt never finished")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// While the retry waits out its backoff, the only pin slot and the restart lock are free.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if !a.pinQueue.acquire(ctx) {
+		t.Fatal("pin slot still held during the retry backoff")
+	}
+	a.pinQueue.release()
+	if !a.ipfsRestart.TryLock() {
+		t.Fatal("IPFS restart lock still held during the retry backoff")
+	}
+	a.ipfsRestart.Unlock()
+	if a.pinQueue.claim(task.TaskId) {
+		t.Fatal("redelivered task claimed while its retry is pending")
+	}
+
+	// Shutdown hands the waiting task back instead of retrying or reporting it.
+	a.taskLoops.Stop()
+	if !a.pins.Wait(ctx) {
+		t.Fatal("pending retry did not stop on shutdown")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 0 || !slices.Equal(nacked, []string{"task-1"}) {
+		t.Fatalf("reported %v and released %v, want only task-1 released", reported, nacked)
+	}
+	if !a.pinQueue.claim(task.TaskId) {
+		t.Fatal("released task left claimed")
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// scanPins compares the local pinset against the inventory. Pins are streamed from IPFS and each one is
+// marked seen in the inventory as it arrives, so memory use does not grow with the pinset; every
+// ReconcileBatchSize pins the scan pauses ReconcileBatchPause so a large pinset does not starve other work.
+// Inventory records pinned before the scan started that it did not see are removed and reported as failed.
+func (a *Agent) scanPins(ctx context.Context) error {
+	started := time.Now()
+	batchSize := max(a.config.ReconcileBatchSize, 1)
+	total := 0
+	err := a.ipfs.StreamPins(ctx, func(cid string) error {
+		a.inventory.MarkSeen(cid, started)
+		total++
+		if total%batchSize != 0 || a.config.ReconcileBatchPause <= 0 {
+			return ctx.Err()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(a.config.ReconcileBatchPause):
+			return nil
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	vanished := a.inventory.Unseen(started)
+	for _, rec := range vanished {
+		a.reportVanishedPin(ctx, &nodepb.PinTask{TaskId: rec.TaskID, Cid: rec.CID}, "pin no longer present (found by reconcile scan)")
+	}
+	a.logger.Info("pin scan completed", "pins", total, "vanished", len(vanished), "duration", time.Since(started).Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	"github.com/wabisaby/wabisaby-node/internal/taskstate"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// newScanAgent returns a test agent whose IPFS pinset holds pinned and whose inventory holds a record,
+// pinned an hour ago, for each of recorded.
+func newScanAgent(tb testing.TB, pinned, recorded []string) (*Agent, *fakeIPFS, *[]*nodepb.ReportPinStatusRequest) {
+	tb.Helper()
+	a, f := newTestAgent(tb, AgentConfig{ReconcileBatchSize: 1000})
+	var reports []*nodepb.ReportPinStatusRequest
+	a.client = &fakeCoordinator{reportPinStatus: func(req *nodepb.ReportPinStatusRequest) (*nodepb.ReportPinStatusResponse, error) {
+		reports = append(reports, req)
+		return &nodepb.ReportPinStatusResponse{Success: true}, nil
+	}}
+	var err error
+	if a.inventory, err = inventory.Open(""); err != nil {
+		tb.Fatal(err)
+	}
+	if a.taskState, err = taskstate.Open(""); err != nil {
+		tb.Fatal(err)
+	}
+	for _, cid := range recorded {
+		if err := a.inventory.Add(inventory.Record{CID: cid, TaskID: "task-" + cid, PinnedAt: time.Now().Add(-time.Hour)}); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	f.pinned = pinned
+	return a, f, &reports
+}
+
+func TestScanPinsReportsVanished(t *testing.T) {
+	a, f, reports := newScanAgent(t, []string{"bafy-a", "bafy-other"}, []string{"bafy-a", "bafy-gone"})
+
+	if err := a.scanPins(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(*reports) != 1 || (*reports)[0].TaskId != "task-bafy-gone" || (*reports)[0].Status != nodepb.ReportPinStatusRequest_PIN_STATUS_FAILED {
+		t.Fatalf("reports = %+v, want one FAILED report for bafy-gone", *reports)
+	}
+	if a.inventory.Contains("bafy-gone") || !a.inventory.Contains("bafy-a") {
+		t.Fatal("inventory not updated to the scanned pinset")
+	}
+
+	// Marks from the previous scan do not carry over.
+	f.mu.Lock()
+	f.pinned = nil
+	f.mu.Unlock()
+	if err := a.scanPins(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if a.inventory.Contains("bafy-a") {
+		t.Fatal("a pin seen by an earlier scan survived a scan that did not see it")
+	}
+}
+
+func BenchmarkScanPins(b *testing.B) {
+	const pins, recorded = 200_000, 20_000
+	cids := make([]string, pins)
+	for i := range cids {
+		cids[i] = fmt.Sprintf("bafy-%07d", i)
+	}
+	a, _, reports := newScanAgent(b, cids, cids[:recorded])
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := a.scanPins(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if len(*reports) != 0 {
+		b.Fatalf("%d pins reported vanished, want none", len(*reports))
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// errPinPreempted is the cancellation cause (wrapped with the reason) of a pin stopped by the
+// preemption policy.
+var errPinPreempted = errors.New("pin preempted")
+
+// preemptCheckInterval is how often in-flight pins are checked against the preemption policy.
+const preemptCheckInterval = 10 * time.Second
+
+// inflightPin is a pin tracked for preemption.
+type inflightPin struct {
+	task    *nodepb.PinTask
+	started time.Time
+	cancel  context.CancelCauseFunc
+}
+
+// pinTracker records the in-flight pins. The zero value is ready to use.
+type pinTracker struct {
+	mu   sync.Mutex
+	pins map[*inflightPin]struct{}
+}
+
+// trackPin registers a pin of task so the preemption policy can cancel it. The returned context is
+// canceled with a cause wrapping errPinPreempted when the pin is preempted; the caller must call done when
+// the pin finishes.
+func (a *Agent) trackPin(ctx context.Context, task *nodepb.PinTask) (pinCtx context.Context, done func()) {
+	pinCtx, cancel := context.WithCancelCause(ctx)
+	p := &inflightPin{task: task, started: time.Now(), cancel: cancel}
+	t := &a.inflight
+	t.mu.Lock()
+	if t.pins == nil {
+		t.pins = make(map[*inflightPin]struct{})
+	}
+	t.pins[p] = struct{}{}
+	t.mu.Unlock()
+	return pinCtx, func() {
+		t.mu.Lock()
+		delete(t.pins, p)
+		t.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// preemptLoop periodically applies the preemption policy until ctx is done. It does nothing when neither
+// MaxPinDuration nor MaxInflightPinTime is set.
+func (a *Agent) preemptLoop(ctx context.Context) {
+	if a.config.MaxPinDuration <= 0 && a.config.MaxInflightPinTime <= 0 {
+		return
+	}
+	ticker := time.NewTicker(preemptCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			a.preemptPins(now)
+		}
+	}
+}
+
+// preemptPins cancels every pin running longer than MaxPinDuration, then, while the combined running time
+// of the remaining pins exceeds MaxInflightPinTime, the oldest of them. Preempted pins are reported as
+// failed by their task and count as a failed attempt for the CID.
+func (a *Agent) preemptPins(now time.Time) {
+	t := &a.inflight
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var total time.Duration
+	remaining := make([]*inflightPin, 0, len(t.pins))
+	for p := range t.pins {
+		elapsed := now.Sub(p.started)
+		if a.config.MaxPinDuration > 0 && elapsed > a.config.MaxPinDuration {
+			a.preempt(p, elapsed, fmt.Sprintf("running for %s, over max_pin_duration %s", elapsed.Round(time.Second), a.config.MaxPinDuration))
+			delete(t.pins, p)
+			continue
+		}
+		total += elapsed
+		remaining = append(remaining, p)
+	}
+
+	budget := a.config.MaxInflightPinTime
+	for budget > 0 && total > budget && len(remaining) > 0 {
+		oldest := 0
+		for i, p := range remaining {
+			if p.started.Before(remaining[oldest].started) {
+				oldest = i
+			}
+		}
+		p := remaining[oldest]
+		elapsed := now.Sub(p.started)
+		a.preempt(p, elapsed, fmt.Sprintf("in-flight pin time %s over max_inflight_pin_time %s", total.Round(time.Second), budget))
+		delete(t.pins, p)
+		total -= elapsed
+		remaining = append(remaining[:oldest], remaining[oldest+1:]...)
+	}
+}
+
+// preempt cancels p with reason.
+func (a *Agent) preempt(p *inflightPin, elapsed time.Duration, reason string) {
+	a.logger.Warn("preempting pin", "cid", p.task.Cid, "task_id", p.task.TaskId, "elapsed", elapsed.Round(time.Second), "reason", reason)
+	p.cancel(fmt.Errorf("%w: %s", errPinPreempted, reason))
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// trackPinAt tracks a pin of a task with id that started at started.
+func trackPinAt(t *testing.T, a *Agent, id string, started time.Time) context.Context {
+	t.Helper()
+	ctx, done := a.trackPin(context.Background(), &nodepb.PinTask{TaskId: id, Cid: "cid-" + id})
+	t.Cleanup(done)
+	a.inflight.mu.Lock()
+	for p := range a.inflight.pins {
+		if p.task.TaskId == id {
+			p.started = started
+		}
+	}
+	a.inflight.mu.Unlock()
+	return ctx
+}
+
+func preempted(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errPinPreempted)
+}
+
+func TestPreemptMaxPinDuration(t *testing.T) {
+	a := &Agent{config: AgentConfig{MaxPinDuration: time.Minute}, logger: testLogger()}
+	now := time.Now()
+	slow := trackPinAt(t, a, "slow", now.Add(-2*time.Minute))
+	fast := trackPinAt(t, a, "fast", now.Add(-30*time.Second))
+
+	a.preemptPins(now)
+	if !preempted(slow) {
+		t.Error("pin over max_pin_duration was not preempted")
+	}
+	if preempted(fast) {
+		t.Error("pin under max_pin_duration was preempted")
+	}
+	if n := len(a.inflight.pins); n != 1 {
+		t.Errorf("%d pins still tracked, want 1", n)
+	}
+}
+
+func TestPreemptMaxInflightPinTime(t *testing.T) {
+	a := &Agent{config: AgentConfig{MaxInflightPinTime: 5 * time.Minute}, logger: testLogger()}
+	now := time.Now()
+	oldest := trackPinAt(t, a, "oldest", now.Add(-3*time.Minute))
+	older := trackPinAt(t, a, "older", now.Add(-2*time.Minute))
+	newest := trackPinAt(t, a, "newest", now.Add(-90*time.Second))
+
+	// 6m30s in flight: preempting the oldest pin brings the total to 3m30s, within the budget.
+	a.preemptPins(now)
+	if !preempted(oldest) {
+		t.Error("oldest pin was not preempted with in-flight pin time over budget")
+	}
+	if preempted(older) || preempted(newest) {
+		t.Error("pins were preempted after the in-flight pin time fell within budget")
+	}
+}
+
+func TestPreemptWithinLimits(t *testing.T) {
+	a := &Agent{config: AgentConfig{MaxPinDuration: time.Hour, MaxInflightPinTime: time.Hour}, logger: testLogger()}
+	now := time.Now()
+	ctx := trackPinAt(t, a, "task", now.Add(-time.Minute))
+
+	a.preemptPins(now)
+	if preempted(ctx) {
+		t.Fatal("pin within limits was preempted")
+	}
+}
+
+func TestTrackPinDone(t *testing.T) {
+	a := &Agent{}
+	ctx, done := a.trackPin(context.Background(), &nodepb.PinTask{TaskId: "task"})
+	done()
+	if n := len(a.inflight.pins); n != 0 {
+		t.Fatalf("%d pins still tracked after done, want 0", n)
+	}
+	if ctx.Err() == nil || preempted(ctx) {
+		t.Fatalf("pin context after done: err %v, cause %v; want canceled without preemption", ctx.Err(), context.Cause(ctx))
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Startup errors distinguishing why the coordinator turned the node away.
+var (
+	errAuthRejected     = errors.New("coordinator rejected the node's credentials")
+	errPermissionDenied = errors.New("coordinator denied the node access")
+)
+
+// preflight checks, before registering, that the coordinator answers and accepts the node's credentials,
+// using the lightweight GetVersion RPC. Coordinators that do not implement it are skipped; registration
+// errors are then classified the same way (see classifyCoordinatorError).
+func (a *Agent) preflight(ctx context.Context) error {
+	resp, err := a.client.GetVersion(a.authContext(ctx), &nodepb.GetVersionRequest{
+		ProtocolVersion: protocolVersion,
+	})
+	if status.Code(err) == codes.Unimplemented {
+		a.logger.Debug("coordinator does not implement GetVersion, skipping pre-flight check")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("coordinator pre-flight check failed: %w", a.classifyCoordinatorError(err))
+	}
+	a.logger.Info("coordinator reachable", "addr", a.config.CoordinatorAddr,
+		"coordinator_version", resp.Version, "coordinator_protocol", resp.ProtocolVersion)
+	return nil
+}
+
+// classifyCoordinatorError turns gRPC errors that commonly stop a node at startup into errors saying
+// what to check. Unreachable coordinators wrap errCoordinatorUnreachable, so they are retried like
+// connection failures. Other errors are returned unchanged.
+func (a *Agent) classifyCoordinatorError(err error) error {
+	switch status.Code(err) {
+	case codes.Unauthenticated:
+		return fmt.Errorf("%w (check auth.token, or auth.refresh_token and auth.keycloak_token_url): %w", errAuthRejected, err)
+	case codes.PermissionDenied:
+		return fmt.Errorf("%w (the token is valid but lacks node permissions): %w", errPermissionDenied, err)
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return fmt.Errorf("%w at %s (check coordinator.address, the network and TLS settings): %w",
+			errCoordinatorUnreachable, a.config.CoordinatorAddr, err)
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// capabilityPinProgress is advertised by coordinators that accept PIN_STATUS_PINNING reports carrying
+// the bytes fetched so far. Older coordinators only know terminal statuses and are never sent one.
+const capabilityPinProgress = "pin.progress"
+
+// pinProgressInterval is how often the bytes fetched are reported while a pin runs.
+const pinProgressInterval = 15 * time.Second
+
+// negotiatePinProgress enables progress reports when the coordinator advertises support at registration.
+func (a *Agent) negotiatePinProgress(capabilities []string) {
+	supported := slices.Contains(capabilities, capabilityPinProgress)
+	if supported && !a.pinProgress.Load() {
+		a.logger.Info("coordinator accepts pin progress reports")
+	}
+	a.pinProgress.Store(supported)
+}
+
+// reportPinProgress reports PIN_STATUS_PINNING for task when the coordinator supports it, then the bytes
+// received by bitswap since the pin started every pinProgressInterval, until the returned stop function is
+// called or ctx is done. Bitswap counters are node-wide, so with concurrent pins the figure is an estimate;
+// it is capped at the task's size when known.
+func (a *Agent) reportPinProgress(ctx context.Context, task *nodepb.PinTask) (stop func()) {
+	if !a.pinProgress.Load() {
+		return func() {}
+	}
+	var start uint64
+	if stat, err := a.ipfs.BitswapStat(ctx); err == nil {
+		start = stat.DataReceived
+	} else {
+		a.logger.Debug("failed to read bitswap stats, pin progress unavailable", "task_id", task.TaskId, "error", err)
+	}
+	a.sendProgress(ctx, task, 0)
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		ticker := time.NewTicker(pinProgressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				stat, err := a.ipfs.BitswapStat(ctx)
+				if err != nil {
+					continue
+				}
+				if start == 0 || stat.DataReceived < start {
+					// No baseline yet, or the daemon restarted and reset its counters.
+					start = stat.DataReceived
+					continue
+				}
+				fetched := int64(stat.DataReceived - start)
+				if task.SizeBytes > 0 {
+					fetched = min(fetched, task.SizeBytes)
+				}
+				a.sendProgress(ctx, task, fetched)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-exited
+	}
+}
+
+// sendProgress reports task as PIN_STATUS_PINNING with fetched bytes. Unlike sendReport it does not record
+// the status in the task state store, and a failed report is only logged at debug level: the terminal
+// report is what counts.
+func (a *Agent) sendProgress(ctx context.Context, task *nodepb.PinTask, fetched int64) {
+	report := &nodepb.ReportPinStatusRequest{
+		NodeId:       a.NodeID(),
+		TaskId:       task.TaskId,
+		Status:       nodepb.ReportPinStatusRequest_PIN_STATUS_PINNING,
+		BytesFetched: fetched,
+		BytesTotal:   task.SizeBytes,
+	}
+	a.signReport(report)
+	if _, err := a.client.ReportPinStatus(a.authContext(ctx), report); err != nil {
+		a.logger.Debug("failed to report pin progress", "task_id", task.TaskId, "error", err)
+	}
+}
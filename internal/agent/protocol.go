@@ -0,0 +1,58 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Coordinator protocol versions. The node sends protocolVersion at registration and the coordinator
+// answers with its own version and the oldest node version it still serves. Compatibility policy:
+//
+//   - Coordinator version 0: the coordinator predates the version exchange. The node runs as before,
+//     relying on capability negotiation (see negotiateHeartbeat).
+//   - Coordinator older than minCoordinatorProtocol: outside the window; the node refuses to start and
+//     the coordinator must be upgraded (or an older node release used).
+//   - Coordinator older than protocolVersion but within the window: reduced compatibility; the node
+//     sends core heartbeat fields only, since later fields may be rejected.
+//   - Coordinator newer than protocolVersion: the node runs if the coordinator still serves
+//     protocolVersion, and refuses to start with an upgrade hint otherwise.
+//
+// Bump protocolVersion when the node starts relying on a proto change, and minCoordinatorProtocol when
+// support for older coordinators is dropped.
+const (
+	protocolVersion        = 2
+	minCoordinatorProtocol = 1
+)
+
+// errProtocolIncompatible is returned (wrapped) from registration when the node and coordinator
+// protocol versions are outside each other's compatibility window.
+var errProtocolIncompatible = errors.New("incompatible coordinator protocol version")
+
+// negotiateProtocol applies the compatibility policy to the versions returned at registration and logs
+// the outcome. It returns an error wrapping errProtocolIncompatible when the node must not run.
+func (a *Agent) negotiateProtocol(coordinatorVersion, minNodeVersion uint32) error {
+	switch {
+	case coordinatorVersion == 0:
+		a.logger.Info("coordinator did not report a protocol version, relying on capability negotiation",
+			"node_protocol", protocolVersion)
+	case minNodeVersion > protocolVersion:
+		return fmt.Errorf("%w: coordinator requires node protocol %d or later, this node speaks %d; upgrade the node to a newer release",
+			errProtocolIncompatible, minNodeVersion, protocolVersion)
+	case coordinatorVersion < minCoordinatorProtocol:
+		return fmt.Errorf("%w: coordinator speaks protocol %d, this node requires %d or later; upgrade the coordinator or use an older node release",
+			errProtocolIncompatible, coordinatorVersion, minCoordinatorProtocol)
+	case coordinatorVersion < protocolVersion:
+		a.logger.Warn("coordinator uses an older protocol, running in reduced-compatibility mode with core heartbeat fields only",
+			"coordinator_protocol", coordinatorVersion, "node_protocol", protocolVersion)
+		a.hbMinimal.Store(true)
+	case coordinatorVersion > protocolVersion:
+		a.logger.Info("coordinator uses a newer protocol; consider upgrading the node",
+			"coordinator_protocol", coordinatorVersion, "node_protocol", protocolVersion)
+	default:
+		a.logger.Info("negotiated coordinator protocol", "protocol", protocolVersion)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+)
+
+// refreshReachability queries AutoNAT reachability, records it, and logs prominently when the node is
+// not reachable from the outside (its advertised multiaddrs are useless to peers).
+func (a *Agent) refreshReachability(ctx context.Context) string {
+	reachability, err := a.ipfs.Reachability(ctx)
+	if err != nil {
+		a.logger.Debug("reachability check failed", "error", err)
+	}
+	previous := a.stats.SetReachability(reachability)
+	if reachability == ipfs.ReachabilityPrivate && previous != ipfs.ReachabilityPrivate {
+		a.logger.Warn("node is NOT reachable from the network (behind NAT/firewall); peers cannot fetch content from it. " +
+			"Forward the IPFS swarm port or enable a relay.")
+	} else if reachability != previous {
+		a.logger.Info("node reachability changed", "reachability", reachability)
+	}
+	return reachability
+}
+
+// checkRequiredReachability waits up to timeout for AutoNAT to determine reachability and returns an error
+// if the node is private. Unknown reachability after the timeout is allowed with a warning.
+func (a *Agent) checkRequiredReachability(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		reachability := a.refreshReachability(ctx)
+		switch reachability {
+		case ipfs.ReachabilityPublic:
+			return nil
+		case ipfs.ReachabilityPrivate:
+			return fmt.Errorf("node is not publicly reachable and node.require_reachable is set")
+		}
+		if time.Now().After(deadline) {
+			a.logger.Warn("reachability still unknown, registering anyway", "waited", timeout)
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
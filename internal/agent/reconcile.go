@@ -0,0 +1,109 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// reconcileLoop periodically reconciles the local pin inventory: it scans the IPFS pinset for inventory
+// pins that vanished, sweeps pins whose retention TTL has elapsed, checks a sample of pins for missing
+// blocks, then reconciles the pinset with the coordinator's assignments. Runs as a background goroutine until context cancellation.
+func (a *Agent) reconcileLoop(ctx context.Context) {
+	if a.config.ReconcileInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(a.config.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := a.scanPins(ctx); err != nil && ctx.Err() == nil {
+				a.logger.Warn("pin scan failed", "error", err)
+			}
+			a.sweepExpired(ctx, time.Now())
+			a.verifyInventorySample(ctx)
+			a.reconcileAssignments(ctx)
+		}
+	}
+}
+
+// sweepExpired unpins every inventory record whose retention has elapsed at now and reports the unpin
+// to the coordinator. Always-pin CIDs are never unpinned. Records whose unpin fails are kept and retried
+// on the next sweep.
+func (a *Agent) sweepExpired(ctx context.Context, now time.Time) {
+	for _, rec := range a.inventory.Expired(now) {
+		if ctx.Err() != nil {
+			return
+		}
+		if a.alwaysPinned(rec.CID) {
+			// Pinned before the CID was added to always_pin; keep it and drop the deadline.
+			rec.ExpiresAt = time.Time{}
+			if err := a.inventory.Add(rec); err != nil {
+				a.logger.Warn("failed to update inventory", "cid", rec.CID, "error", err)
+			}
+			continue
+		}
+		a.unpinExpired(ctx, rec)
+	}
+}
+
+// unpinExpired removes a single expired pin and reports it as unpinned against its original task.
+func (a *Agent) unpinExpired(ctx context.Context, rec inventory.Record) {
+	a.logger.Info("retention expired, unpinning content", "cid", rec.CID, "task_id", rec.TaskID,
+		"pinned_at", rec.PinnedAt, "expires_at", rec.ExpiresAt)
+	a.unpinRecord(ctx, rec, "retention expired")
+}
+
+// unpinRecord unpins an inventory record's CID, removes the record and reports the unpin with reason
+// against the record's task. A failed unpin keeps the record so it is retried.
+func (a *Agent) unpinRecord(ctx context.Context, rec inventory.Record, reason string) {
+	if err := a.ipfs.Unpin(ctx, rec.CID); err != nil {
+		a.logger.Warn("failed to unpin content", "cid", rec.CID, "reason", reason, "error", err)
+		return
+	}
+	if err := a.inventory.Remove(rec.CID); err != nil {
+		a.logger.Warn("failed to update inventory", "cid", rec.CID, "error", err)
+	}
+	task := &nodepb.PinTask{TaskId: rec.TaskID, Cid: rec.CID}
+	_ = a.reportStatus(ctx, task, nodepb.ReportPinStatusRequest_PIN_STATUS_UNPINNED, reason)
+}
+
+// recordPin adds a completed pin to the inventory, with a retention deadline when the task carries a TTL
+// and the CID is not always-pinned, and the pinned size for the capacity breakdown (the task's stated
+// size if the DAG size cannot be read).
+func (a *Agent) recordPin(ctx context.Context, task *nodepb.PinTask, pinnedAt time.Time) {
+	rec := inventory.Record{CID: task.Cid, TaskID: task.TaskId, PinnedAt: pinnedAt, SizeBytes: task.SizeBytes}
+	if a.ipfs.PinNamesSupported() {
+		rec.Name = a.pinName(task)
+	}
+	if size, err := a.ipfs.DagSize(ctx, task.Cid); err == nil {
+		rec.SizeBytes = size
+	} else {
+		a.logger.Debug("failed to read pinned size", "cid", task.Cid, "error", err)
+	}
+	if task.RetentionSeconds > 0 && !a.alwaysPinned(task.Cid) {
+		rec.ExpiresAt = pinnedAt.Add(time.Duration(task.RetentionSeconds) * time.Second)
+	}
+	if err := a.inventory.Add(rec); err != nil {
+		a.logger.Warn("failed to record pin in inventory", "cid", task.Cid, "error", err)
+	}
+}
+
+// alwaysPinned reports whether cid is configured as operator-pinned and therefore exempt from expiry.
+func (a *Agent) alwaysPinned(cid string) bool {
+	for _, c := range a.config.AlwaysPin {
+		if c == cid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/inventory"
+	"github.com/wabisaby/wabisaby-node/internal/taskstate"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+func TestSweepExpired(t *testing.T) {
+	a, f := newTestAgent(t, AgentConfig{AlwaysPin: []string{"bafy-always"}})
+	var reports []*nodepb.ReportPinStatusRequest
+	a.client = &fakeCoordinator{reportPinStatus: func(req *nodepb.ReportPinStatusRequest) (*nodepb.ReportPinStatusResponse, error) {
+		reports = append(reports, req)
+		return &nodepb.ReportPinStatusResponse{Success: true}, nil
+	}}
+	var err error
+	if a.inventory, err = inventory.Open(""); err != nil {
+		t.Fatal(err)
+	}
+	if a.taskState, err = taskstate.Open(""); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	pinnedAt := now.Add(-2 * time.Hour)
+	for _, rec := range []inventory.Record{
+		{CID: "bafy-expired", TaskID: "task-expired", PinnedAt: pinnedAt, ExpiresAt: now.Add(-time.Minute)},
+		{CID: "bafy-live", TaskID: "task-live", PinnedAt: pinnedAt, ExpiresAt: now.Add(time.Hour)},
+		{CID: "bafy-forever", TaskID: "task-forever", PinnedAt: pinnedAt},
+		{CID: "bafy-always", TaskID: "task-always", PinnedAt: pinnedAt, ExpiresAt: now.Add(-time.Minute)},
+	} {
+		if err := a.inventory.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a.sweepExpired(context.Background(), now)
+
+	if got := f.unpinnedCIDs(); !slices.Equal(got, []string{"bafy-expired"}) {
+		t.Fatalf("unpinned %v, want only the expired pin", got)
+	}
+	if _, ok := a.inventory.Get("bafy-expired"); ok {
+		t.Error("expired pin still in the inventory")
+	}
+	for _, cid := range []string{"bafy-live", "bafy-forever"} {
+		if _, ok := a.inventory.Get(cid); !ok {
+			t.Errorf("%s removed from the inventory before its retention elapsed", cid)
+		}
+	}
+	if rec, ok := a.inventory.Get("bafy-always"); !ok || !rec.ExpiresAt.IsZero() {
+		t.Errorf("always-pin record = %+v, %v; want it kept without a deadline", rec, ok)
+	}
+	if len(reports) != 1 || reports[0].TaskId != "task-expired" || reports[0].Status != nodepb.ReportPinStatusRequest_PIN_STATUS_UNPINNED {
+		t.Fatalf("reports = %+v, want one UNPINNED report for task-expired", reports)
+	}
+}
+
+// newAssignmentAgent returns a test agent whose inventory holds a task pin for each of cids, pinned an hour
+// ago, and whose coordinator assigns only the CIDs in *assigned.
+func newAssignmentAgent(t *testing.T, cids []string, assigned *[]string) (*Agent, *fakeIPFS) {
+	t.Helper()
+	a, f := newTestAgent(t, AgentConfig{})
+	a.client = &fakeCoordinator{
+		assignedPins: func() (*nodepb.GetAssignedPinsResponse, error) {
+			resp := &nodepb.GetAssignedPinsResponse{}
+			for _, cid := range *assigned {
+				resp.Pins = append(resp.Pins, &nodepb.PinTask{TaskId: "task-" + cid, Cid: cid})
+			}
+			return resp, nil
+		},
+		reportPinStatus: func(*nodepb.ReportPinStatusRequest) (*nodepb.ReportPinStatusResponse, error) {
+			return &nodepb.ReportPinStatusResponse{Success: true}, nil
+		},
+	}
+	var err error
+	if a.inventory, err = inventory.Open(""); err != nil {
+		t.Fatal(err)
+	}
+	if a.taskState, err = taskstate.Open(""); err != nil {
+		t.Fatal(err)
+	}
+	for _, cid := range cids {
+		if err := a.inventory.Add(inventory.Record{CID: cid, TaskID: "task-" + cid, PinnedAt: time.Now().Add(-time.Hour)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f.pinned = cids
+	return a, f
+}
+
+func TestReconcileAssignmentsEmptyResponse(t *testing.T) {
+	var assigned []string
+	a, f := newAssignmentAgent(t, []string{"bafy-a", "bafy-b"}, &assigned)
+
+	for range 3 {
+		a.reconcileAssignments(context.Background())
+	}
+	if got := f.unpinnedCIDs(); len(got) != 0 {
+		t.Fatalf("unpinned %v after empty assignment lists, want nothing", got)
+	}
+}
+
+func TestReconcileAssignmentsConfirmsBeforeUnpinning(t *testing.T) {
+	assigned := []string{"bafy-a"}
+	a, f := newAssignmentAgent(t, []string{"bafy-a", "bafy-b"}, &assigned)
+
+	a.reconcileAssignments(context.Background())
+	if got := f.unpinnedCIDs(); len(got) != 0 {
+		t.Fatalf("unpinned %v on the first unassigned sighting, want nothing", got)
+	}
+
+	// Reassigned in between: the sighting does not carry over.
+	assigned = []string{"bafy-a", "bafy-b"}
+	a.reconcileAssignments(context.Background())
+	assigned = []string{"bafy-a"}
+	a.reconcileAssignments(context.Background())
+	if got := f.unpinnedCIDs(); len(got) != 0 {
+		t.Fatalf("unpinned %v without two consecutive unassigned sightings", got)
+	}
+
+	a.reconcileAssignments(context.Background())
+	if got := f.unpinnedCIDs(); !slices.Equal(got, []string{"bafy-b"}) {
+		t.Fatalf("unpinned %v, want bafy-b after two consecutive unassigned sightings", got)
+	}
+}
+
+func TestReconcileAssignmentsCapsUnpinsPerRun(t *testing.T) {
+	var cids []string
+	for i := range 20 {
+		cids = append(cids, fmt.Sprintf("bafy-%02d", i))
+	}
+	assigned := []string{"bafy-00"}
+	a, f := newAssignmentAgent(t, cids, &assigned)
+
+	a.reconcileAssignments(context.Background())
+	a.reconcileAssignments(context.Background())
+	if got := len(f.unpinnedCIDs()); got != 2 {
+		t.Fatalf("unpinned %d of 20 pins in one run, want the 10%% cap of 2", got)
+	}
+	// The cap follows the shrinking inventory: 10% of the 18 pins left rounds down to 1.
+	a.reconcileAssignments(context.Background())
+	if got := len(f.unpinnedCIDs()); got != 3 {
+		t.Fatalf("unpinned %d pins after the next run, want 3", got)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// coordinatorConn is the connection behind a.client. It forwards every call to the current gRPC
+// connection, which a reconnect replaces, so the client stays valid across reconnects.
+type coordinatorConn struct {
+	cur atomic.Pointer[grpc.ClientConn]
+}
+
+// Invoke implements grpc.ClientConnInterface.
+func (c *coordinatorConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	return c.cur.Load().Invoke(ctx, method, args, reply, opts...)
+}
+
+// NewStream implements grpc.ClientConnInterface.
+func (c *coordinatorConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return c.cur.Load().NewStream(ctx, desc, method, opts...)
+}
+
+// close closes the current connection, if any.
+func (c *coordinatorConn) close() error {
+	if conn := c.cur.Load(); conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+// reconnectIfUnavailable starts reconnecting to the coordinator when err is an Unavailable RPC error,
+// unless a reconnect is already in progress or the node is shutting down.
+func (a *Agent) reconnectIfUnavailable(err error) {
+	if status.Code(err) != codes.Unavailable || a.draining.Load() {
+		return
+	}
+	if !a.reconnecting.CompareAndSwap(false, true) {
+		return
+	}
+	a.heartbeats.Go(a.reconnectLoop)
+}
+
+// reconnectLoop re-dials the coordinator, re-registers and reconnects to peers, retrying with
+// exponential backoff and jitter from ReconnectBaseDelay up to ReconnectMaxDelay until it succeeds or
+// ctx is canceled.
+func (a *Agent) reconnectLoop(ctx context.Context) {
+	defer a.checkCoordinatorHealth()
+	defer a.reconnecting.Store(false)
+	a.reconnects.add(time.Now())
+	a.logger.Warn("coordinator unavailable, reconnecting", "addr", a.config.CoordinatorAddr)
+	a.checkCoordinatorHealth()
+
+	delay := a.config.ReconnectBaseDelay
+	for attempt := 1; ; attempt++ {
+		err := a.reconnect(ctx)
+		if err == nil {
+			a.logger.Info("reconnected to coordinator", "attempts", attempt)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		wait := jitter(delay)
+		a.logger.Warn("coordinator reconnect failed", "attempt", attempt, "error", err, "retry_in", wait.Round(time.Millisecond))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		delay = min(delay*2, a.config.ReconnectMaxDelay)
+	}
+}
+
+// reconnect replaces the coordinator connection with a freshly established one, then re-registers with
+// the last advertised multiaddrs and reconnects to peers.
+func (a *Agent) reconnect(ctx context.Context) error {
+	conn, err := a.dialCoordinator(ctx, true)
+	if err != nil {
+		return err
+	}
+	if old := a.conn.cur.Swap(conn); old != nil {
+		_ = old.Close()
+	}
+
+	var multiaddrs []string
+	if addrs := a.advertised.Load(); addrs != nil {
+		multiaddrs = *addrs
+	}
+	if err := a.register(ctx, multiaddrs); err != nil {
+		return fmt.Errorf("re-registration failed: %w", err)
+	}
+	if err := a.connectToPeers(ctx); err != nil {
+		a.logger.Warn("failed to connect to some peers", "error", err)
+	}
+	return nil
+}
+
+// jitter returns d randomized by up to ±25%, so nodes disconnected together do not reconnect in lockstep.
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return d - d/4 + rand.N(d/2+1)
+}
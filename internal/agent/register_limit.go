@@ -0,0 +1,82 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/fsutil"
+)
+
+// awaitRegisterSlot enforces MinRegisterInterval between registration attempts across process restarts,
+// so a crash-looping node cannot hammer the coordinator. The time of the last attempt is persisted in
+// RegisterStateFile; a too-soon attempt is delayed (never skipped) until the interval has passed or ctx
+// is canceled. The current attempt is then recorded.
+func (a *Agent) awaitRegisterSlot(ctx context.Context) error {
+	interval := a.config.MinRegisterInterval
+	if interval <= 0 || a.config.RegisterStateFile == "" {
+		return nil
+	}
+
+	last, err := readLastRegisterAttempt(a.config.RegisterStateFile)
+	if err != nil {
+		a.logger.Warn("failed to read registration state, not delaying registration", "error", err)
+	}
+	if wait := registerDelay(last, time.Now(), interval); wait > 0 {
+		a.logger.Info("delaying registration to respect coordinator.min_register_interval",
+			"last_attempt", last, "delay", wait.Round(time.Second))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("registration delay interrupted: %w", ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	if err := writeLastRegisterAttempt(a.config.RegisterStateFile, time.Now()); err != nil {
+		a.logger.Warn("failed to record registration attempt", "error", err)
+	}
+	return nil
+}
+
+// registerDelay returns how long to wait at now before registering, given the last attempt. A last attempt
+// in the future (clock moved backwards) waits at most one interval.
+func registerDelay(last, now time.Time, interval time.Duration) time.Duration {
+	if last.IsZero() {
+		return 0
+	}
+	return min(last.Add(interval).Sub(now), interval)
+}
+
+// readLastRegisterAttempt returns the persisted time of the last registration attempt, or the zero time
+// if none has been recorded.
+func readLastRegisterAttempt(path string) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// writeLastRegisterAttempt persists t as the time of the last registration attempt.
+func writeLastRegisterAttempt(path string, t time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return fsutil.WriteFileAtomic(path, []byte(t.UTC().Format(time.RFC3339Nano)+"\n"), 0o644)
+}
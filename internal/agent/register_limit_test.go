@@ -0,0 +1,101 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAwaitRegisterSlotTruncatedState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last-register")
+	if err := writeLastRegisterAttempt(path, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[:len(data)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readLastRegisterAttempt(path); err == nil {
+		t.Fatal("readLastRegisterAttempt accepted a truncated file")
+	}
+
+	a := &Agent{
+		config: AgentConfig{MinRegisterInterval: time.Hour, RegisterStateFile: path},
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := a.awaitRegisterSlot(ctx); err != nil {
+		t.Fatalf("awaitRegisterSlot with a truncated state file: %v", err)
+	}
+	// The unreadable state is replaced by the current attempt.
+	if last, err := readLastRegisterAttempt(path); err != nil || time.Since(last) > time.Minute {
+		t.Fatalf("recorded attempt = %v, %v; want now", last, err)
+	}
+}
+
+func TestRegisterDelay(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		last time.Time
+		want time.Duration
+	}{
+		{"never registered", time.Time{}, 0},
+		{"interval elapsed", now.Add(-2 * time.Minute), -time.Minute},
+		{"too soon", now.Add(-20 * time.Second), 40 * time.Second},
+		{"clock moved backwards", now.Add(time.Hour), time.Minute},
+	}
+	for _, tt := range tests {
+		if got := registerDelay(tt.last, now, time.Minute); got != tt.want {
+			t.Errorf("%s: registerDelay = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAwaitRegisterSlotAcrossRestarts(t *testing.T) {
+	const interval = 300 * time.Millisecond
+	path := filepath.Join(t.TempDir(), "last-register")
+	// Each restart is a new agent sharing only the persisted state file.
+	restart := func() *Agent {
+		return &Agent{config: AgentConfig{MinRegisterInterval: interval, RegisterStateFile: path}, logger: testLogger()}
+	}
+	ctx := context.Background()
+
+	start := time.Now()
+	if err := restart().awaitRegisterSlot(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > interval/2 {
+		t.Fatalf("first registration delayed %s, want no delay", d)
+	}
+
+	start = time.Now()
+	if err := restart().awaitRegisterSlot(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < interval*3/4 {
+		t.Fatalf("registration after a restart delayed %s, want about %s", d, interval)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	start = time.Now()
+	if err := restart().awaitRegisterSlot(canceled); !errors.Is(err, context.Canceled) {
+		t.Fatalf("awaitRegisterSlot with a canceled context = %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > interval/2 {
+		t.Fatalf("canceled registration delay took %s", d)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+)
+
+// checkResourceLimits fetches the IPFS resource manager stats and surfaces scopes at their limit, which
+// make the daemon silently refuse connections and streams (a common cause of pin failures). Changes in
+// the exceeded set are logged as warnings; the current set is recorded in stats. Checks stop after the
+// daemon reports the resource manager as unavailable.
+func (a *Agent) checkResourceLimits(ctx context.Context) {
+	if a.noRcmgr {
+		return
+	}
+	limits, err := a.ipfs.SwarmResources(ctx)
+	if errors.Is(err, ipfs.ErrResourceManagerUnavailable) {
+		a.logger.Info("IPFS resource manager stats unavailable, not monitoring resource limits")
+		a.noRcmgr = true
+		return
+	}
+	if err != nil {
+		a.logger.Debug("failed to fetch IPFS resource manager stats", "error", err)
+		return
+	}
+
+	var exceeded []string
+	for _, l := range limits {
+		if l.Exceeded() {
+			exceeded = append(exceeded, l.String())
+		}
+	}
+	previous := a.stats.SetResourceLimitsExceeded(exceeded)
+	switch {
+	case len(exceeded) > 0 && !slices.Equal(previous, exceeded):
+		a.logger.Warn("IPFS resource manager limits reached; connections and streams are being throttled",
+			"scopes", exceeded)
+	case len(exceeded) == 0 && len(previous) > 0:
+		a.logger.Info("IPFS resource manager limits no longer reached")
+	}
+}
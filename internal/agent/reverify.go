@@ -0,0 +1,47 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// reverifyPin waits ReverifyAfter after a successful pin report and checks that the pin is still present,
+// catching GC or eviction that removes freshly pinned content. If the pin vanished, a failure report
+// correcting the earlier success is sent. processTask runs it in the taskLoops group, so the wait ends
+// early, without a check, when task intake stops during shutdown.
+func (a *Agent) reverifyPin(ctx context.Context, task *nodepb.PinTask) {
+	timer := time.NewTimer(a.config.ReverifyAfter)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	pinned, err := a.ipfs.IsPinned(ctx, task.Cid)
+	if err != nil {
+		a.logger.Warn("pin re-verification failed", "cid", task.Cid, "task_id", task.TaskId, "error", err)
+		return
+	}
+	if pinned {
+		a.logger.Debug("pin re-verified", "cid", task.Cid, "task_id", task.TaskId)
+		return
+	}
+
+	a.reportVanishedPin(ctx, task, "pin no longer present "+a.config.ReverifyAfter.String()+" after success was reported")
+}
+
+// reportVanishedPin handles a pin that disappeared after it was reported as pinned: the inventory record
+// is removed and a failure report correcting the earlier success is sent.
+func (a *Agent) reportVanishedPin(ctx context.Context, task *nodepb.PinTask, reason string) {
+	a.logger.Error("pinned content vanished; check IPFS GC configuration", "cid", task.Cid, "task_id", task.TaskId, "reason", reason)
+	if err := a.inventory.Remove(task.Cid); err != nil {
+		a.logger.Warn("failed to update inventory", "cid", task.Cid, "error", err)
+	}
+	_ = a.reportStatus(ctx, task, nodepb.ReportPinStatusRequest_PIN_STATUS_FAILED, reason)
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// loopGroup runs background goroutines under a shared cancelable context, so a shutdown step can stop
+// exactly that group and wait for it to exit before the next step begins.
+type loopGroup struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newLoopGroup(parent context.Context) *loopGroup {
+	ctx, cancel := context.WithCancel(parent)
+	return &loopGroup{ctx: ctx, cancel: cancel}
+}
+
+// Go runs fn in a new goroutine with the group's context.
+func (g *loopGroup) Go(fn func(ctx context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		fn(g.ctx)
+	}()
+}
+
+// Wait blocks until every goroutine in the group has returned or ctx is done. It reports whether the
+// group finished.
+func (g *loopGroup) Wait(ctx context.Context) bool {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Stop cancels the group's context and waits for its goroutines to return.
+func (g *loopGroup) Stop() {
+	g.cancel()
+	g.wg.Wait()
+}
+
+// Stop shuts the agent down in a fixed order, logging each step: stop accepting tasks, drain in-flight
+// pins (each pin sends its status report before finishing), deregister, stop heartbeats, stop IPFS, and
+// close the coordinator connection. In-flight pins still running when the drain timeout or ctx expires
+// are canceled. Stop is safe to call more than once; later calls wait for the first to finish.
+func (a *Agent) Stop(ctx context.Context) error {
+	a.stopOnce.Do(func() { a.stopErr = a.shutdown(ctx) })
+	return a.stopErr
+}
+
+func (a *Agent) shutdown(ctx context.Context) error {
+	a.logger.Info("shutdown: stopping task intake")
+	a.draining.Store(true)
+	a.taskLoops.Stop()
+
+	a.logger.Info("shutdown: draining in-flight pins and status reports", "timeout", a.config.ShutdownDrainTimeout)
+	drainCtx := ctx
+	if a.config.ShutdownDrainTimeout > 0 {
+		var cancel context.CancelFunc
+		drainCtx, cancel = context.WithTimeout(ctx, a.config.ShutdownDrainTimeout)
+		defer cancel()
+	}
+	if !a.pins.Wait(drainCtx) {
+		a.logger.Warn("shutdown: drain timed out, canceling remaining pins")
+	}
+	a.pins.Stop()
+
+	if a.config.DeregisterOnShutdown && a.client != nil && a.NodeID() != "" {
+		a.logger.Info("shutdown: deregistering from coordinator")
+		if err := a.deregister(ctx); err != nil {
+			a.logger.Warn("shutdown: deregistration failed", "error", err)
+		}
+	}
+
+	a.logger.Info("shutdown: stopping heartbeats")
+	a.heartbeats.Stop()
+
+	a.logger.Info("shutdown: stopping IPFS daemon")
+	if err := a.ipfsManager.StopDaemon(ctx); err != nil {
+		a.logger.Warn("failed to stop IPFS daemon", "error", err)
+	}
+
+	// Ends the remaining background work (blocklist refresh, token refresh) and unblocks Start.
+	a.cancel()
+
+	a.logger.Info("shutdown: closing coordinator connection")
+	return a.conn.close()
+}
+
+// deregister tells the coordinator the node is going offline so it stops assigning tasks to it.
+// Coordinators without the Deregister RPC are tolerated.
+func (a *Agent) deregister(ctx context.Context) error {
+	_, err := a.client.Deregister(a.authContext(ctx), &nodepb.DeregisterRequest{NodeId: a.NodeID()})
+	if status.Code(err) == codes.Unimplemented {
+		a.logger.Debug("coordinator does not support deregistration")
+		return nil
+	}
+	return err
+}
+
+// nackTimeout bounds releasing tasks back to the coordinator, which may happen after the task loop's context is canceled.
+const nackTimeout = 5 * time.Second
+
+// releaseTasks hands tasks back to the coordinator without working on them, so they are redelivered to
+// another node right away instead of after their lease or ack timeout. Coordinators without the
+// NackPinTask RPC are tolerated; the tasks are then redelivered once they time out.
+func (a *Agent) releaseTasks(ctx context.Context, tasks []*nodepb.PinTask, reason string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), nackTimeout)
+	defer cancel()
+	for _, task := range tasks {
+		a.logger.Info("releasing pin task", "task_id", task.TaskId, "cid", task.Cid, "reason", reason)
+		_, err := a.client.NackPinTask(a.authContext(ctx), &nodepb.NackPinTaskRequest{
+			NodeId: a.NodeID(),
+			TaskId: task.TaskId,
+			Reason: reason,
+		})
+		if status.Code(err) == codes.Unimplemented {
+			a.logger.Debug("coordinator does not support releasing tasks")
+			return
+		}
+		if err != nil {
+			a.logger.Warn("failed to release pin task", "task_id", task.TaskId, "error", err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// eventLog records shutdown events and, as an io.Writer for a JSON slog handler, the logged messages.
+type eventLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (l *eventLog) record(event string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+func (l *eventLog) Write(p []byte) (int, error) {
+	var entry struct {
+		Msg string `json:"msg"`
+	}
+	if err := json.Unmarshal(p, &entry); err != nil {
+		return 0, err
+	}
+	l.record(entry.Msg)
+	return len(p), nil
+}
+
+func (l *eventLog) snapshot() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.events...)
+}
+
+func TestShutdownOrder(t *testing.T) {
+	a, _ := newTestAgent(t, AgentConfig{DeregisterOnShutdown: true})
+	log := &eventLog{}
+	a.logger = slog.New(slog.NewJSONHandler(log, nil))
+	nodeID := "node-1"
+	a.nodeID.Store(&nodeID)
+	a.client = &fakeCoordinator{deregister: func(*nodepb.DeregisterRequest) (*nodepb.DeregisterResponse, error) {
+		log.record("deregistered")
+		return &nodepb.DeregisterResponse{}, nil
+	}}
+	_, cancel := context.WithCancel(context.Background())
+	a.cancel = func() {
+		log.record("agent canceled")
+		cancel()
+	}
+	a.taskLoops = newLoopGroup(context.Background())
+	a.pins = newLoopGroup(context.Background())
+	a.heartbeats = newLoopGroup(context.Background())
+
+	taskLoopDone := make(chan struct{})
+	a.taskLoops.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		log.record("task loop stopped")
+		close(taskLoopDone)
+	})
+	// The in-flight pin only finishes once intake has stopped, so the drain has to wait for it.
+	a.pins.Go(func(ctx context.Context) {
+		select {
+		case <-taskLoopDone:
+			log.record("pin finished")
+		case <-ctx.Done():
+			log.record("pin canceled")
+		}
+	})
+	a.heartbeats.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		log.record("heartbeat loop stopped")
+	})
+
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop = %v", err)
+	}
+
+	events := log.snapshot()
+	order := []string{
+		"shutdown: stopping task intake",
+		"task loop stopped",
+		"pin finished",
+		"shutdown: deregistering from coordinator",
+		"deregistered",
+		"shutdown: stopping heartbeats",
+		"heartbeat loop stopped",
+		"shutdown: stopping IPFS daemon",
+		"agent canceled",
+		"shutdown: closing coordinator connection",
+	}
+	last := -1
+	for _, event := range order {
+		i := slices.Index(events, event)
+		if i < 0 {
+			t.Fatalf("event %q missing from shutdown: %q", event, events)
+		}
+		if i < last {
+			t.Fatalf("event %q out of order in shutdown: %q", event, events)
+		}
+		last = i
+	}
+	if slices.Contains(events, "pin canceled") {
+		t.Fatalf("in-flight pin was canceled instead of drained: %q", events)
+	}
+
+	// A second Stop does not run the sequence again.
+	n := len(events)
+	if err := a.Stop(context.Background()); err != nil {
+		t.Fatalf("second Stop = %v", err)
+	}
+	if got := log.snapshot(); len(got) != n {
+		t.Fatalf("second Stop logged %q", got[n:])
+	}
+}
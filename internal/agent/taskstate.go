@@ -0,0 +1,85 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/taskstate"
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// saveTaskState records the state of task (taskstate.StatusPinning or a reported status name).
+// Failures are logged; the state is an optimization, not required for correctness.
+func (a *Agent) saveTaskState(task *nodepb.PinTask, state string) {
+	err := a.taskState.Put(taskstate.Entry{TaskID: task.TaskId, CID: task.Cid, Status: state})
+	if err != nil {
+		a.logger.Warn("failed to save task state", "task_id", task.TaskId, "error", err)
+	}
+}
+
+// restoreTaskState reconciles the task state left by a previous run with the IPFS pinset before task
+// polling starts. Tasks whose content is pinned are not pinned again if redelivered; a pin that completed
+// without being reported is reported now. Interrupted pins whose content is not pinned are forgotten,
+// so their redelivery is processed normally. If the pinset cannot be listed nothing is skipped.
+func (a *Agent) restoreTaskState(ctx context.Context) {
+	entries := a.taskState.Entries()
+	if len(entries) == 0 {
+		return
+	}
+	wanted := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		wanted[e.CID] = false
+	}
+	err := a.ipfs.StreamPins(ctx, func(cid string) error {
+		if _, ok := wanted[cid]; ok {
+			wanted[cid] = true
+		}
+		return nil
+	})
+	if err != nil {
+		a.logger.Warn("failed to list pins, not restoring task state", "error", err)
+		return
+	}
+
+	pinnedStatus := nodepb.ReportPinStatusRequest_PIN_STATUS_PINNED.String()
+	restored, reported := 0, 0
+	for _, e := range entries {
+		if e.Status != taskstate.StatusPinning && e.Status != pinnedStatus {
+			continue
+		}
+		task := &nodepb.PinTask{TaskId: e.TaskID, Cid: e.CID}
+		if !wanted[e.CID] {
+			if e.Status == taskstate.StatusPinning {
+				if err := a.taskState.Delete(e.TaskID); err != nil {
+					a.logger.Warn("failed to save task state", "task_id", e.TaskID, "error", err)
+				}
+			}
+			continue
+		}
+		a.restoredPins.Store(e.TaskID, struct{}{})
+		restored++
+		if e.Status == taskstate.StatusPinning {
+			// The pin finished, but the node stopped before reporting it.
+			if !a.inventory.Contains(e.CID) {
+				a.recordPin(ctx, task, time.Now())
+			}
+			if a.reportStatus(ctx, task, nodepb.ReportPinStatusRequest_PIN_STATUS_PINNED, "") == nil {
+				reported++
+			}
+		}
+	}
+	a.logger.Info("restored task state", "tasks", len(entries), "pinned", restored, "reported", reported)
+}
+
+// pinTask pins task's content, unless restoreTaskState found it already pinned for this task.
+func (a *Agent) pinTask(ctx context.Context, task *nodepb.PinTask) error {
+	if _, ok := a.restoredPins.LoadAndDelete(task.TaskId); ok {
+		a.logger.Info("content pinned before restart, skipping pin", "task_id", task.TaskId, "cid", task.Cid)
+		return nil
+	}
+	a.saveTaskState(task, taskstate.StatusPinning)
+	return a.pin(ctx, task.Cid, a.pinName(task))
+}
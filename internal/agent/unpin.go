@@ -0,0 +1,37 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package agent
+
+import (
+	"context"
+
+	nodepb "github.com/wabisaby/wabisaby-protos-go/go/node"
+)
+
+// processUnpinTask releases content the coordinator no longer wants stored: the CID is unpinned (content
+// that is not pinned counts as success), removed from the inventory and reported as unpinned. Operator
+// pinned content (always_pin) is kept and the task rejected.
+func (a *Agent) processUnpinTask(ctx context.Context, task *nodepb.PinTask) {
+	if a.alwaysPinned(task.Cid) {
+		a.logger.Info("keeping operator-pinned content despite unpin task", "cid", task.Cid, "task_id", task.TaskId)
+		a.reportStatus(ctx, task, nodepb.ReportPinStatusRequest_PIN_STATUS_REJECTED, "content is pinned by node operator (always_pin)")
+		return
+	}
+
+	a.logger.Info("unpinning content", "cid", task.Cid, "task_id", task.TaskId)
+	if err := a.ipfs.Unpin(ctx, task.Cid); err != nil {
+		a.logger.Error("failed to unpin content", "cid", task.Cid, "error", err)
+		a.reportStatus(ctx, task, nodepb.ReportPinStatusRequest_PIN_STATUS_FAILED, err.Error())
+		return
+	}
+	if err := a.inventory.Remove(task.Cid); err != nil {
+		a.logger.Warn("failed to update inventory", "cid", task.Cid, "error", err)
+	}
+	if err := a.inventory.ClearFailures(task.Cid); err != nil {
+		a.logger.Warn("failed to update inventory", "cid", task.Cid, "error", err)
+	}
+	if err := a.reportStatus(ctx, task, nodepb.ReportPinStatusRequest_PIN_STATUS_UNPINNED, ""); err == nil {
+		a.logger.Info("unpin task completed", "task_id", task.TaskId)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package blocklist
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// prefixMarker marks a blocklist entry that matches every CID starting with the given string.
+const prefixMarker = "prefix:"
+
+// Config holds blocklist sources. Entries from the file and the URL are combined.
+type Config struct {
+	File            string        // Local blocklist file (optional)
+	URL             string        // Remote blocklist URL (optional)
+	RefreshInterval time.Duration // How often to reload both sources
+	Logger          *slog.Logger
+}
+
+// Blocklist is a set of CIDs (exact match) and CID prefixes the node refuses to pin.
+// The list format is one entry per line: a CID, or "prefix:<string>" for prefix matching.
+// Blank lines and lines starting with '#' are ignored. All methods are safe for concurrent use.
+type Blocklist struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu       sync.RWMutex
+	cids     map[string]struct{}
+	prefixes []string
+}
+
+// New creates an empty blocklist for the configured sources. Call Load before use.
+func New(cfg Config) *Blocklist {
+	return &Blocklist{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		cids:       make(map[string]struct{}),
+	}
+}
+
+// Enabled reports whether any blocklist source is configured.
+func (b *Blocklist) Enabled() bool {
+	return b != nil && (b.cfg.File != "" || b.cfg.URL != "")
+}
+
+// Blocked reports whether cid is blocked and, if so, the matching entry.
+func (b *Blocklist) Blocked(cid string) (bool, string) {
+	if b == nil {
+		return false, ""
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if _, ok := b.cids[cid]; ok {
+		return true, cid
+	}
+	for _, p := range b.prefixes {
+		if strings.HasPrefix(cid, p) {
+			return true, prefixMarker + p
+		}
+	}
+	return false, ""
+}
+
+// Load reads all configured sources and atomically replaces the current entries. On error the
+// previous entries are kept.
+func (b *Blocklist) Load(ctx context.Context) error {
+	cids := make(map[string]struct{})
+	var prefixes []string
+
+	if b.cfg.File != "" {
+		f, err := os.Open(b.cfg.File)
+		if err != nil {
+			return fmt.Errorf("open blocklist file: %w", err)
+		}
+		err = parse(f, cids, &prefixes)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("read blocklist file: %w", err)
+		}
+	}
+
+	if b.cfg.URL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.cfg.URL, nil)
+		if err != nil {
+			return fmt.Errorf("create blocklist request: %w", err)
+		}
+		resp, err := b.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("fetch blocklist: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("fetch blocklist: status %d", resp.StatusCode)
+		}
+		if err := parse(resp.Body, cids, &prefixes); err != nil {
+			return fmt.Errorf("read blocklist: %w", err)
+		}
+	}
+
+	b.mu.Lock()
+	b.cids = cids
+	b.prefixes = prefixes
+	b.mu.Unlock()
+
+	b.cfg.Logger.Info("blocklist loaded", "cids", len(cids), "prefixes", len(prefixes))
+	return nil
+}
+
+// RefreshLoop reloads the blocklist every RefreshInterval until ctx is canceled.
+func (b *Blocklist) RefreshLoop(ctx context.Context) {
+	if !b.Enabled() || b.cfg.RefreshInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(b.cfg.RefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := b.Load(ctx); err != nil {
+				b.cfg.Logger.Warn("blocklist refresh failed, keeping previous entries", "error", err)
+			}
+		}
+	}
+}
+
+// parse reads blocklist entries from r into cids and prefixes.
+func parse(r io.Reader, cids map[string]struct{}, prefixes *[]string) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if p, ok := strings.CutPrefix(line, prefixMarker); ok {
+			if p = strings.TrimSpace(p); p != "" {
+				*prefixes = append(*prefixes, p)
+			}
+			continue
+		}
+		cids[line] = struct{}{}
+	}
+	return scanner.Err()
+}
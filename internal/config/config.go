@@ -4,68 +4,340 @@
 package config
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"log"
+	"math"
+	"net/url"
 	"os"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/spf13/viper"
+	"github.com/wabisaby/wabisaby-node/internal/diskstat"
+	"github.com/wabisaby/wabisaby-node/internal/httpserver"
+	"github.com/wabisaby/wabisaby-node/internal/identity"
+	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+	"github.com/wabisaby/wabisaby-node/internal/logging"
+	"github.com/wabisaby/wabisaby-node/internal/secrets"
+	"github.com/wabisaby/wabisaby-node/internal/tlsconfig"
 )
 
 // NodeConfig holds storage node configuration (nested structure for node.yaml).
 type NodeConfig struct {
-	Auth        AuthConfig        `mapstructure:"auth"`
-	Coordinator CoordinatorConfig `mapstructure:"coordinator"`
-	IPFS        IPFSConfig        `mapstructure:"ipfs"`
+	Auth        AuthConfig         `mapstructure:"auth"`
+	Coordinator CoordinatorConfig  `mapstructure:"coordinator"`
+	IPFS        IPFSConfig         `mapstructure:"ipfs"`
 	Node        NodeIdentityConfig `mapstructure:"node"`
-	Storage     StorageConfig     `mapstructure:"storage"`
-	Intervals   IntervalsConfig   `mapstructure:"intervals"`
-	Log         LogConfig         `mapstructure:"log"`
+	Storage     StorageConfig      `mapstructure:"storage"`
+	Intervals   IntervalsConfig    `mapstructure:"intervals"`
+	Log         LogConfig          `mapstructure:"log"`
+	Events      EventsConfig       `mapstructure:"events"`
+	Startup     StartupConfig      `mapstructure:"startup"`
+	Content     ContentConfig      `mapstructure:"content"`
+	Shutdown    ShutdownConfig     `mapstructure:"shutdown"`
+	Tasks       TasksConfig        `mapstructure:"tasks"`
+	Reconcile   ReconcileConfig    `mapstructure:"reconcile"`
+	HTTP        HTTPConfig         `mapstructure:"http"`
+	Features    FeaturesConfig     `mapstructure:"features"`
+	Runtime     RuntimeConfig      `mapstructure:"runtime"`
+	Admin       AdminConfig        `mapstructure:"admin"`
+	Health      HealthConfig       `mapstructure:"health"`
+	Metrics     MetricsConfig      `mapstructure:"metrics"`
 }
 
 // AuthConfig holds authentication settings.
 type AuthConfig struct {
-	Token              string `mapstructure:"token"`                // JWT access token (or use refresh_token for programmatic refresh)
-	RefreshToken       string `mapstructure:"refresh_token"`         // Keycloak refresh token; if set with keycloak_token_url, node will refresh access token automatically
-	KeycloakTokenURL   string `mapstructure:"keycloak_token_url"`    // Keycloak token endpoint, e.g. http://localhost:8180/realms/wabisaby/protocol/openid-connect/token
-	KeycloakClientID   string `mapstructure:"keycloak_client_id"`    // OIDC client id for token refresh (default: wabisaby-api)
+	Token            string `mapstructure:"token"`              // JWT access token (or use refresh_token for programmatic refresh)
+	RefreshToken     string `mapstructure:"refresh_token"`      // Keycloak refresh token; if set with keycloak_token_url, node will refresh access token automatically
+	KeycloakTokenURL string `mapstructure:"keycloak_token_url"` // Keycloak token endpoint, e.g. http://localhost:8180/realms/wabisaby/protocol/openid-connect/token
+	KeycloakClientID string `mapstructure:"keycloak_client_id"` // OIDC client id for token refresh (default: wabisaby-api)
 }
 
 // CoordinatorConfig holds coordinator connection settings.
 type CoordinatorConfig struct {
-	Address string `mapstructure:"address"`
+	Address     string        `mapstructure:"address"`
+	DialTimeout time.Duration `mapstructure:"dial_timeout"` // Max time to establish the connection at startup
+	LazyDial    bool          `mapstructure:"lazy_dial"`    // Skip the eager connection check and connect on first RPC
+	AckTasks    bool          `mapstructure:"ack_tasks"`    // Acknowledge pin tasks on receipt (requires coordinator support)
+	Compression string        `mapstructure:"compression"`  // RPC compression: none or gzip
+
+	MinimalHeartbeat bool `mapstructure:"minimal_heartbeat"` // Send only node ID, storage usage and uptime in heartbeats
+
+	MinRegisterInterval time.Duration `mapstructure:"min_register_interval"` // Minimum spacing between registration attempts, across restarts
+	RegisterStateFile   string        `mapstructure:"register_state_file"`   // Records the last attempt; default ~/.wabisaby/last-register
+	TLS                 TLSConfig     `mapstructure:"tls"`
+
+	PeerCache     bool   `mapstructure:"peer_cache"`      // Cache coordinator peers and use them while the coordinator is unreachable
+	PeerCacheFile string `mapstructure:"peer_cache_file"` // Last-known-good peer list; default ~/.wabisaby/peers.json
+
+	ReconnectBaseDelay time.Duration `mapstructure:"reconnect_base_delay"` // First delay between reconnect attempts after the coordinator becomes unavailable
+	ReconnectMaxDelay  time.Duration `mapstructure:"reconnect_max_delay"`  // Cap on the exponentially growing reconnect delay
+
+	KeepaliveTime    time.Duration `mapstructure:"keepalive_time"`    // Ping the coordinator after this long without activity (0 disables keepalive)
+	KeepaliveTimeout time.Duration `mapstructure:"keepalive_timeout"` // Close the connection when a ping is not answered within this
+}
+
+// TLSConfig holds TLS settings for the coordinator connection.
+type TLSConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	MinVersion   string   `mapstructure:"min_version"`   // "1.2" (default) or "1.3"
+	CipherSuites []string `mapstructure:"cipher_suites"` // Optional allowlist of IANA cipher suite names
+
+	CAFile             string `mapstructure:"ca_cert_file"`         // PEM CA bundle verifying the coordinator; system roots if empty
+	ClientCertFile     string `mapstructure:"client_cert_file"`     // PEM client certificate for mutual TLS (optional)
+	ClientKeyFile      string `mapstructure:"client_key_file"`      // PEM key for client_cert_file
+	ServerNameOverride string `mapstructure:"server_name_override"` // Host name to verify the coordinator certificate against
+}
+
+// Options returns the TLS settings as tlsconfig options.
+func (c TLSConfig) Options() tlsconfig.Options {
+	return tlsconfig.Options{
+		MinVersion:   c.MinVersion,
+		CipherSuites: c.CipherSuites,
+		CAFile:       c.CAFile,
+		CertFile:     c.ClientCertFile,
+		KeyFile:      c.ClientKeyFile,
+		ServerName:   c.ServerNameOverride,
+	}
 }
 
 // IPFSConfig holds IPFS daemon settings.
 type IPFSConfig struct {
-	APIURL  string `mapstructure:"api_url"`
-	DataDir string `mapstructure:"data_dir"`
+	APIURL            string                `mapstructure:"api_url"`
+	DataDir           string                `mapstructure:"data_dir"`
+	RepoPath          string                `mapstructure:"repo_path"`           // IPFS repo (IPFS_PATH) used verbatim; default data_dir/.ipfs
+	KuboVersion       string                `mapstructure:"kubo_version"`        // kubo release installed into data_dir when no ipfs binary is found
+	StrictVersion     bool                  `mapstructure:"strict_version"`      // Refuse an installed ipfs binary that is not kubo_version
+	SwarmKey          string                `mapstructure:"swarm_key"`           // Private network key; generated into the repo if empty
+	Pubsub            bool                  `mapstructure:"pubsub"`              // Start the daemon with the pubsub experiment enabled
+	DaemonArgs        []string              `mapstructure:"daemon_args"`         // Extra ipfs daemon flags, e.g. --routing=dhtclient
+	AutoRestart       bool                  `mapstructure:"auto_restart"`        // Restart the daemon with backoff after it exits unexpectedly
+	GatewayURL        string                `mapstructure:"gateway_url"`         // Optional read-only gateway reported to the coordinator for retrieval routing
+	MaxPeers          int                   `mapstructure:"max_peers"`           // Max coordinator-provided peers to connect to (0 = all)
+	PreferSameRegion  bool                  `mapstructure:"prefer_same_region"`  // Connect to peers in this node's region first
+	MinPeersForTasks  int                   `mapstructure:"min_peers_for_tasks"` // Pause task acceptance below this many swarm peers (0 disables)
+	Diagnostics       IPFSDiagnosticsConfig `mapstructure:"diagnostics"`
+	Experimental      map[string]bool       `mapstructure:"experimental"`        // kubo experimental feature flags applied during setup
+	ConfigOverlayFile string                `mapstructure:"config_overlay_file"` // JSON deep-merged into the kubo config during setup
+	PreserveIdentity  bool                  `mapstructure:"preserve_identity"`   // Keep the peer identity under data_dir and restore it into a reinitialized repo
+	RepairRepo        bool                  `mapstructure:"repair_repo"`         // Move a partially initialized repo aside and reinitialize it
+	ServePriority     string                `mapstructure:"serve_priority"`      // balanced, serve or ingest; empty leaves bitswap/connmgr settings untouched
+	AdoptDaemon       bool                  `mapstructure:"adopt_daemon"`        // Use an IPFS daemon already serving api_url instead of failing to start one
+	TraceRequests     bool                  `mapstructure:"trace_requests"`      // Log every IPFS API request at debug level
+	TraceRedactArgs   bool                  `mapstructure:"trace_redact_args"`   // Redact CIDs and other "arg" values from traced URLs
+	ReverifyAfter     time.Duration         `mapstructure:"reverify_after"`      // Re-check pins this long after reporting success (0 disables)
+	CircuitBreaker    IPFSBreakerConfig     `mapstructure:"circuit_breaker"`
+}
+
+// IPFSBreakerConfig controls the per-endpoint circuit breaker on IPFS API calls.
+type IPFSBreakerConfig struct {
+	FailureThreshold int           `mapstructure:"failure_threshold"` // Consecutive failures that open an endpoint's breaker (0 disables)
+	Cooldown         time.Duration `mapstructure:"cooldown"`          // How long an open breaker rejects calls before probing the daemon
+}
+
+// IPFSDiagnosticsConfig controls the diagnostics gathered when a pin fails.
+type IPFSDiagnosticsConfig struct {
+	OnPinFailure  bool `mapstructure:"on_pin_failure"` // Collect swarm peer count and repo stats on pin failure
+	FindProviders bool `mapstructure:"find_providers"` // Also query the DHT for the CID's providers (slow)
 }
 
 // NodeIdentityConfig holds node identity (name, region, wallet).
 type NodeIdentityConfig struct {
-	Name          string `mapstructure:"name"`
-	Region        string `mapstructure:"region"`
-	WalletAddress string `mapstructure:"wallet_address"`
+	Name                string            `mapstructure:"name"`
+	NameSuffix          string            `mapstructure:"name_suffix"`   // Append a stable suffix to disambiguate names: none, peer_id, identity_key
+	NameStrategy        string            `mapstructure:"name_strategy"` // How the name is chosen: hostname (name, else host+user), identity_key, peer_id
+	Region              string            `mapstructure:"region"`
+	Group               string            `mapstructure:"group"`             // Optional logical group (e.g. archive-cluster-1) for coordinator placement policies
+	RegionMap           map[string]string `mapstructure:"region_map"`        // Time zone prefix -> region overrides used when region is auto-detected
+	RegionFromCloud     bool              `mapstructure:"region_from_cloud"` // Query AWS/GCP/Azure instance metadata for the region
+	WalletAddress       string            `mapstructure:"wallet_address"`
+	MaxMultiaddrs       int               `mapstructure:"max_multiaddrs"`       // Cap on advertised multiaddrs (public addresses kept first); 0 = unlimited
+	RequireReachable    bool              `mapstructure:"require_reachable"`    // Refuse to register if AutoNAT reports the node unreachable
+	ReachabilityTimeout time.Duration     `mapstructure:"reachability_timeout"` // Max wait for AutoNAT to determine reachability
+	IdentityKeyFile     string            `mapstructure:"identity_key_file"`    // PEM PKCS#8 Ed25519 key used to sign pin reports; signing disabled if empty
+	StorageMedia        string            `mapstructure:"storage_media"`        // ssd, hdd, nvme or network; reported at registration for tiered placement
+	DetectStorageMedia  bool              `mapstructure:"detect_storage_media"` // Detect the media of data_dir (Linux); storage_media is the fallback
 }
 
 // StorageConfig holds storage capacity settings.
 type StorageConfig struct {
-	CapacityGB int64 `mapstructure:"capacity_gb"`
+	Capacity   string `mapstructure:"capacity"`    // Human-readable size, e.g. "2TB", "500GB", "100GiB"
+	CapacityGB int64  `mapstructure:"capacity_gb"` // Deprecated: use Capacity. Interpreted as GiB (2^30 bytes), as it always was.
+
+	// Re-detection of auto-detected capacity while running (ignored when capacity is configured explicitly).
+	RecheckInterval time.Duration `mapstructure:"recheck_interval"` // How often to re-detect capacity (0 disables)
+	ChangeThreshold float64       `mapstructure:"change_threshold"` // Relative change required before advertising a new capacity
+
+	// Repo garbage collection under disk pressure.
+	GCHighWatermark float64       `mapstructure:"gc_high_watermark"` // Run repo GC above this fraction of the storage limit (0 disables)
+	GCMinInterval   time.Duration `mapstructure:"gc_min_interval"`   // Minimum time between watermark-triggered GCs
+
+	InventoryFile string `mapstructure:"inventory_file"` // Pin inventory (pin times, retention deadlines); default ~/.wabisaby/inventory.json
+
+	// CapacityBytes is the resolved capacity in bytes (from Capacity, CapacityGB, or auto-detection).
+	CapacityBytes int64 `mapstructure:"-"`
+	// AutoDetected is true when CapacityBytes came from disk auto-detection.
+	AutoDetected bool `mapstructure:"-"`
 }
 
 // IntervalsConfig holds heartbeat and poll intervals.
 type IntervalsConfig struct {
-	Heartbeat time.Duration `mapstructure:"heartbeat"`
-	Poll      time.Duration `mapstructure:"poll"`
+	Heartbeat      time.Duration `mapstructure:"heartbeat"`
+	HeartbeatGrace time.Duration `mapstructure:"heartbeat_grace"` // Skip a restarted loop's immediate heartbeat if one was sent this recently
+	Poll           time.Duration `mapstructure:"poll"`
+	MaxPollBackoff time.Duration `mapstructure:"max_poll_backoff"` // Cap on how long a coordinator back-off hint may delay the next poll
+	Reconcile      time.Duration `mapstructure:"reconcile"`        // Pin inventory reconciliation (expiry sweep, coordinator assignments)
+	PeerRefresh    time.Duration `mapstructure:"peer_refresh"`     // Re-fetch and connect coordinator peers, updating the per-region peer counts
+
+	// Cap on the heartbeat interval, doubled per consecutive failed heartbeat (0 keeps the fixed interval).
+	MaxHeartbeatBackoff time.Duration `mapstructure:"max_heartbeat_backoff"`
 }
 
 // LogConfig holds logging settings.
 type LogConfig struct {
-	Level string `mapstructure:"level"`
+	Level      string            `mapstructure:"level"`
+	Format     string            `mapstructure:"format"`     // text or json
+	Attributes map[string]string `mapstructure:"attributes"` // Static attributes added to every log line, e.g. datacenter
+}
+
+// ContentConfig holds content policy settings.
+type ContentConfig struct {
+	BlocklistFile    string        `mapstructure:"blocklist_file"`    // Local file of CIDs / "prefix:" entries the node refuses to pin
+	BlocklistURL     string        `mapstructure:"blocklist_url"`     // Remote blocklist in the same format, merged with the file
+	BlocklistRefresh time.Duration `mapstructure:"blocklist_refresh"` // How often the blocklist is reloaded
+	AlwaysPin        []string      `mapstructure:"always_pin"`        // Operator-pinned CIDs that never expire, even if a task carries a retention TTL
+}
+
+// StartupConfig holds settings controlling the startup sequence.
+type StartupConfig struct {
+	RegisterFirst bool `mapstructure:"register_first"` // Register with the coordinator before bringing up IPFS
+}
+
+// HTTPConfig holds settings shared by every HTTP server the node exposes (health, metrics, admin).
+type HTTPConfig struct {
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout"`
+	ReadTimeout       time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout      time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout       time.Duration `mapstructure:"idle_timeout"`
+}
+
+// AdminConfig holds settings for the admin HTTP API.
+type AdminConfig struct {
+	ListenAddr string          `mapstructure:"listen_addr"` // e.g. "127.0.0.1:9102"; empty disables the admin API
+	Token      string          `mapstructure:"token"`       // Bearer token required on every admin request
+	TLS        ServerTLSConfig `mapstructure:"tls"`
+}
+
+// HealthConfig holds settings for the health probe server.
+type HealthConfig struct {
+	Addr string          `mapstructure:"addr"` // Default ":9090"; empty disables /healthz and /readyz
+	TLS  ServerTLSConfig `mapstructure:"tls"`
+}
+
+// ServerTLSConfig holds TLS settings for an HTTP endpoint the node serves. The endpoint serves plain
+// HTTP unless cert_file is set.
+type ServerTLSConfig struct {
+	CertFile     string   `mapstructure:"cert_file"`     // PEM server certificate
+	KeyFile      string   `mapstructure:"key_file"`      // PEM key for cert_file
+	MinVersion   string   `mapstructure:"min_version"`   // "1.2" (default) or "1.3"
+	CipherSuites []string `mapstructure:"cipher_suites"` // Optional allowlist of IANA cipher suite names
+}
+
+// Enabled reports whether the endpoint serves HTTPS.
+func (c ServerTLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// Build returns the endpoint's *tls.Config, or nil when TLS is not enabled.
+func (c ServerTLSConfig) Build() (*tls.Config, error) {
+	if !c.Enabled() {
+		return nil, nil
+	}
+	return tlsconfig.Build(tlsconfig.Options{
+		MinVersion:   c.MinVersion,
+		CipherSuites: c.CipherSuites,
+		CertFile:     c.CertFile,
+		KeyFile:      c.KeyFile,
+	})
+}
+
+// MetricsConfig holds settings for the Prometheus metrics endpoint.
+type MetricsConfig struct {
+	Enabled bool `mapstructure:"enabled"` // Serve /metrics on the health server (health.addr)
+}
+
+// Timeouts returns the configured server timeouts.
+func (c HTTPConfig) Timeouts() httpserver.Timeouts {
+	return httpserver.Timeouts{
+		ReadHeader: c.ReadHeaderTimeout,
+		Read:       c.ReadTimeout,
+		Write:      c.WriteTimeout,
+		Idle:       c.IdleTimeout,
+	}
+}
+
+// ReconcileConfig controls the pin scan run on every reconcile interval.
+type ReconcileConfig struct {
+	BatchSize  int           `mapstructure:"batch_size"`  // Pins per batch; bounds memory use
+	BatchPause time.Duration `mapstructure:"batch_pause"` // Pause between batches to yield to other work
+	VerifyPins int           `mapstructure:"verify_pins"` // Random inventory pins checked block by block per interval (0 disables)
+}
+
+// RuntimeConfig holds Go runtime settings for the node process.
+type RuntimeConfig struct {
+	GoMemLimit string `mapstructure:"gomemlimit"` // Soft heap limit, e.g. "1GiB"; empty keeps GOMEMLIMIT from the environment
+
+	// GoMemLimitBytes is GoMemLimit in bytes (0 = not configured).
+	GoMemLimitBytes int64 `mapstructure:"-"`
+}
+
+// FeaturesConfig holds local overrides of coordinator-controlled feature flags.
+type FeaturesConfig struct {
+	Disable []string `mapstructure:"disable"` // Flags kept off even when the coordinator enables them
+}
+
+// TasksConfig holds pin task handling settings.
+type TasksConfig struct {
+	MaxAttempts        int              `mapstructure:"max_attempts"`          // Failed pin attempts per CID before it is reported abandoned (0 = unlimited)
+	MaxPinRetries      int              `mapstructure:"max_pin_retries"`       // Retries of a transiently failed pin within a task, with exponential backoff
+	MaxPinDuration     time.Duration    `mapstructure:"max_pin_duration"`      // Preempt a single pin running longer than this (0 disables)
+	PinTimeout         time.Duration    `mapstructure:"pin_timeout"`           // Fail a pin attempt running longer than this, then retry it (0 = no limit)
+	MaxInflightPinTime time.Duration    `mapstructure:"max_inflight_pin_time"` // Preempt the oldest pins while in-flight pin time exceeds this (0 disables)
+	MaxConcurrentPins  int              `mapstructure:"max_concurrent_pins"`   // Pins run at once; further tasks are queued (0 = unlimited)
+	PinNames           bool             `mapstructure:"pin_names"`             // Name IPFS pins after their task (kubo 0.26+)
+	VerifyPins         bool             `mapstructure:"verify_pins"`           // Check the whole DAG is stored locally before reporting a pin as pinned
+	StateFile          string           `mapstructure:"state_file"`            // Per-task pin state kept across restarts; default <ipfs.data_dir>/wabisaby-tasks.json
+	Accept             TaskAcceptConfig `mapstructure:"accept"`
+}
+
+// TaskAcceptConfig holds the rules a pin task must match to be accepted; other tasks are declined.
+type TaskAcceptConfig struct {
+	MaxSize        string   `mapstructure:"max_size"`        // Largest content accepted, e.g. "10GB"; empty = no limit
+	RequiredLabels []string `mapstructure:"required_labels"` // Labels a task must all carry
+	ExcludedLabels []string `mapstructure:"excluded_labels"` // Labels a task must not carry
+
+	// MaxSizeBytes is MaxSize in bytes (0 = no limit).
+	MaxSizeBytes int64 `mapstructure:"-"`
+}
+
+// ShutdownConfig holds settings for the ordered shutdown sequence.
+type ShutdownConfig struct {
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"` // Max wait for in-flight pins to finish and report
+	Deregister   bool          `mapstructure:"deregister"`    // Deregister from the coordinator before stopping heartbeats
+}
+
+// EventsConfig holds settings for the optional event webhook.
+type EventsConfig struct {
+	WebhookURL   string        `mapstructure:"webhook_url"`   // POST target for event payloads; disabled if empty
+	Timeout      time.Duration `mapstructure:"timeout"`       // Per-attempt webhook timeout
+	MaxRetries   int           `mapstructure:"max_retries"`   // Retries with exponential backoff after a failed delivery
+	DedupeWindow time.Duration `mapstructure:"dedupe_window"` // Repeated events of the same type within this window are dropped
 }
 
 // LoadNodeConfig loads storage node configuration from config file and environment variables.
@@ -81,12 +353,65 @@ func LoadNodeConfig() *NodeConfig {
 
 	// Nested defaults (viper uses dot for nesting)
 	viper.SetDefault("coordinator.address", "localhost:50052")
+	viper.SetDefault("coordinator.dial_timeout", 10*time.Second)
+	viper.SetDefault("coordinator.tls.min_version", "1.2")
+	viper.SetDefault("coordinator.min_register_interval", 30*time.Second)
+	viper.SetDefault("coordinator.compression", "none")
+	viper.SetDefault("coordinator.peer_cache", true)
+	viper.SetDefault("coordinator.reconnect_base_delay", time.Second)
+	viper.SetDefault("coordinator.reconnect_max_delay", 30*time.Second)
+	viper.SetDefault("coordinator.keepalive_time", 20*time.Second)
+	viper.SetDefault("coordinator.keepalive_timeout", 10*time.Second)
 	viper.SetDefault("ipfs.api_url", "http://localhost:5001")
+	viper.SetDefault("ipfs.kubo_version", ipfs.DefaultKuboVersion)
+	viper.SetDefault("ipfs.pubsub", true)
+	viper.SetDefault("ipfs.auto_restart", true)
+	viper.SetDefault("ipfs.prefer_same_region", true)
+	viper.SetDefault("ipfs.min_peers_for_tasks", 1)
+	viper.SetDefault("ipfs.diagnostics.on_pin_failure", true)
+	viper.SetDefault("ipfs.circuit_breaker.failure_threshold", 5)
+	viper.SetDefault("ipfs.circuit_breaker.cooldown", 30*time.Second)
 	viper.SetDefault("node.name", "wabisaby-community-node")
-	viper.SetDefault("storage.capacity_gb", 100)
+	viper.SetDefault("node.name_suffix", "none")
+	viper.SetDefault("node.name_strategy", "hostname")
+	viper.SetDefault("node.max_multiaddrs", 16)
+	viper.SetDefault("node.reachability_timeout", 2*time.Minute)
 	viper.SetDefault("intervals.heartbeat", 1*time.Minute)
+	viper.SetDefault("intervals.heartbeat_grace", 5*time.Second)
 	viper.SetDefault("intervals.poll", 30*time.Second)
+	viper.SetDefault("intervals.max_poll_backoff", 10*time.Minute)
+	viper.SetDefault("intervals.max_heartbeat_backoff", 10*time.Minute)
+	viper.SetDefault("intervals.reconcile", 10*time.Minute)
+	viper.SetDefault("intervals.peer_refresh", 5*time.Minute)
 	viper.SetDefault("log.level", "info")
+	viper.SetDefault("log.format", "text")
+	viper.SetDefault("storage.recheck_interval", 1*time.Hour)
+	viper.SetDefault("storage.change_threshold", 0.05)
+	viper.SetDefault("storage.gc_high_watermark", 0.9)
+	viper.SetDefault("storage.gc_min_interval", 30*time.Minute)
+	viper.SetDefault("content.blocklist_refresh", 1*time.Hour)
+	viper.SetDefault("events.timeout", 5*time.Second)
+	viper.SetDefault("events.max_retries", 3)
+	viper.SetDefault("events.dedupe_window", 1*time.Minute)
+	viper.SetDefault("shutdown.drain_timeout", 30*time.Second)
+	viper.SetDefault("tasks.max_attempts", 5)
+	viper.SetDefault("tasks.max_pin_retries", 2)
+	viper.SetDefault("tasks.pin_timeout", 30*time.Minute)
+	viper.SetDefault("tasks.pin_names", true)
+	viper.SetDefault("tasks.verify_pins", false)
+	viper.SetDefault("tasks.max_concurrent_pins", 4)
+	viper.SetDefault("http.read_header_timeout", httpserver.DefaultTimeouts.ReadHeader)
+	viper.SetDefault("http.read_timeout", httpserver.DefaultTimeouts.Read)
+	viper.SetDefault("http.write_timeout", httpserver.DefaultTimeouts.Write)
+	viper.SetDefault("http.idle_timeout", httpserver.DefaultTimeouts.Idle)
+	viper.SetDefault("health.addr", ":9090")
+	viper.SetDefault("health.tls.min_version", "1.2")
+	viper.SetDefault("admin.tls.min_version", "1.2")
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("reconcile.batch_size", 1000)
+	viper.SetDefault("reconcile.batch_pause", 100*time.Millisecond)
+	viper.SetDefault("reconcile.verify_pins", 10)
+	viper.SetDefault("shutdown.deregister", true)
 
 	if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
@@ -96,10 +421,15 @@ func LoadNodeConfig() *NodeConfig {
 		}
 	}
 
+	// Replace secret references (env://VAR, vault://path#field) with their values before decoding.
+	resolveSecretRefs()
+
 	var config NodeConfig
 	if err := viper.Unmarshal(&config); err != nil {
 		log.Fatalf("Unable to decode into struct: %v", err)
 	}
+	// Problems found while resolving values; reported together with Validate's.
+	var errs []error
 
 	// Auth: fallback to legacy env
 	if config.Auth.Token == "" {
@@ -118,21 +448,45 @@ func LoadNodeConfig() *NodeConfig {
 		config.Coordinator.Address = os.Getenv("WABISABY_COORDINATOR_ADDR")
 	}
 
-	// Auto-detect storage capacity if not provided
-	if config.Storage.CapacityGB == 0 {
-		capacityGB := detectStorageCapacity()
-		if capacityGB > 0 {
-			config.Storage.CapacityGB = capacityGB
+	// Resolve storage capacity: explicit size string, deprecated GB alias, or auto-detection
+	switch {
+	case config.Storage.Capacity != "":
+		// Parse errors are reported by Validate.
+		config.Storage.CapacityBytes, _ = ParseSize(config.Storage.Capacity)
+	case config.Storage.CapacityGB > 0:
+		log.Println("storage.capacity_gb is deprecated and counts GiB (2^30 bytes); use storage.capacity instead, e.g. \"500GiB\", or \"500GB\" for decimal units")
+		config.Storage.CapacityBytes = config.Storage.CapacityGB << 30
+	default:
+		capacityBytes := detectStorageCapacity()
+		if capacityBytes > 0 {
+			config.Storage.CapacityBytes = capacityBytes
+			config.Storage.AutoDetected = true
 		} else {
-			config.Storage.CapacityGB = 100
+			config.Storage.CapacityBytes = 100 * 1_000_000_000
 		}
 	}
 
 	if config.Node.Region == "" {
-		config.Node.Region = detectRegion()
+		config.Node.Region = detectRegion(config.Node.RegionMap, config.Node.RegionFromCloud)
 	}
-	if config.Node.Name == "" {
-		config.Node.Name = generateNodeName()
+	switch config.Node.NameStrategy {
+	case "hostname":
+		if config.Node.Name == "" {
+			config.Node.Name = generateNodeName()
+		}
+	case "identity_key":
+		// A missing identity_key_file is reported by Validate.
+		if config.Node.IdentityKeyFile != "" {
+			signer, err := identity.LoadSigner(config.Node.IdentityKeyFile)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("node.name_strategy identity_key: %w", err))
+			} else {
+				config.Node.Name = identity.NodeName(signer.PublicKey())
+			}
+		}
+	case "peer_id":
+		// The peer ID is only known once IPFS is up; the agent derives the name before registering.
+		config.Node.Name = ""
 	}
 
 	if config.IPFS.APIURL == "" {
@@ -142,39 +496,273 @@ func LoadNodeConfig() *NodeConfig {
 		homeDir, _ := os.UserHomeDir()
 		config.IPFS.DataDir = filepath.Join(homeDir, ".wabisaby", "ipfs")
 	}
+	if config.Coordinator.RegisterStateFile == "" {
+		homeDir, _ := os.UserHomeDir()
+		config.Coordinator.RegisterStateFile = filepath.Join(homeDir, ".wabisaby", "last-register")
+	}
+	if config.Coordinator.PeerCacheFile == "" {
+		homeDir, _ := os.UserHomeDir()
+		config.Coordinator.PeerCacheFile = filepath.Join(homeDir, ".wabisaby", "peers.json")
+	}
+	if config.Storage.InventoryFile == "" {
+		homeDir, _ := os.UserHomeDir()
+		config.Storage.InventoryFile = filepath.Join(homeDir, ".wabisaby", "inventory.json")
+	}
+	if config.Tasks.StateFile == "" {
+		config.Tasks.StateFile = filepath.Join(config.IPFS.DataDir, "wabisaby-tasks.json")
+	}
+	if config.Tasks.Accept.MaxSize != "" {
+		// Parse errors are reported by Validate.
+		config.Tasks.Accept.MaxSizeBytes, _ = ParseSize(config.Tasks.Accept.MaxSize)
+	}
+	if config.Runtime.GoMemLimit != "" {
+		config.Runtime.GoMemLimitBytes, _ = ParseSize(config.Runtime.GoMemLimit)
+	}
+	if config.Node.DetectStorageMedia {
+		if media, err := diskstat.MediaType(config.IPFS.DataDir); err == nil {
+			config.Node.StorageMedia = media
+		} else {
+			log.Printf("Storage media detection unavailable (%v), using node.storage_media %q", err, config.Node.StorageMedia)
+		}
+	}
 
+	if err := errors.Join(append(errs, config.Validate())...); err != nil {
+		log.Fatalf("Invalid node configuration:\n%v", err)
+	}
 	return &config
 }
 
-// detectStorageCapacity detects available disk space and returns capacity in GB.
-func detectStorageCapacity() int64 {
-	var stat syscall.Statfs_t
-	wd, err := os.Getwd()
-	if err != nil {
-		return 0
+// Validate checks the resolved configuration and returns every problem found, joined into one error, so
+// all of them can be fixed in a single pass. It returns nil for a valid configuration.
+func (c *NodeConfig) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
 	}
-	if err := syscall.Statfs(wd, &stat); err != nil {
-		return 0
+
+	if c.Coordinator.Address == "" {
+		fail("coordinator.address is required (or set WABISABY_COORDINATOR_ADDR)")
+	}
+	if c.Storage.Capacity != "" {
+		if n, err := ParseSize(c.Storage.Capacity); err != nil {
+			fail("storage.capacity: %v", err)
+		} else if n <= 0 {
+			fail("storage.capacity %q: must be greater than zero", c.Storage.Capacity)
+		}
+	}
+	if c.Storage.CapacityGB < 0 {
+		fail("storage.capacity_gb %d: must not be negative", c.Storage.CapacityGB)
+	} else if c.Storage.CapacityGB > math.MaxInt64>>30 {
+		fail("storage.capacity_gb %d: too large", c.Storage.CapacityGB)
+	}
+	if c.Intervals.Heartbeat <= 0 {
+		fail("intervals.heartbeat %s: must be greater than zero", c.Intervals.Heartbeat)
+	}
+	if c.Intervals.Poll <= 0 {
+		fail("intervals.poll %s: must be greater than zero", c.Intervals.Poll)
+	}
+	if c.Intervals.HeartbeatGrace < 0 || c.Intervals.MaxPollBackoff < 0 || c.Intervals.Reconcile < 0 || c.Intervals.MaxHeartbeatBackoff < 0 || c.Intervals.PeerRefresh < 0 {
+		fail("intervals.heartbeat_grace, max_poll_backoff, max_heartbeat_backoff, reconcile and peer_refresh must not be negative")
+	}
+	if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+		fail("log.level: %v", err)
+	}
+	switch c.Log.Format {
+	case "text", "json":
+	default:
+		fail("log.format %q: must be text or json", c.Log.Format)
+	}
+	if err := validateHTTPURL(c.IPFS.APIURL); err != nil {
+		fail("ipfs.api_url: %v", err)
+	}
+	switch c.Node.NameStrategy {
+	case "hostname", "peer_id":
+	case "identity_key":
+		if c.Node.IdentityKeyFile == "" {
+			fail("node.name_strategy identity_key requires node.identity_key_file")
+		}
+	default:
+		fail("node.name_strategy %q: must be hostname, identity_key or peer_id", c.Node.NameStrategy)
+	}
+	if c.Coordinator.TLS.Enabled {
+		if _, err := tlsconfig.Build(c.Coordinator.TLS.Options()); err != nil {
+			fail("coordinator.tls: %v", err)
+		}
+	}
+	if c.Reconcile.BatchSize < 1 {
+		fail("reconcile: batch_size (%d) must be at least 1", c.Reconcile.BatchSize)
+	}
+	if c.Storage.GCHighWatermark < 0 || c.Storage.GCHighWatermark > 1 {
+		fail("storage.gc_high_watermark must be between 0 and 1, got %v", c.Storage.GCHighWatermark)
+	}
+	if c.Reconcile.VerifyPins < 0 {
+		fail("reconcile.verify_pins must not be negative")
+	}
+	if c.Tasks.Accept.MaxSize != "" {
+		if _, err := ParseSize(c.Tasks.Accept.MaxSize); err != nil {
+			fail("tasks.accept.max_size: %v", err)
+		}
+	}
+	if c.Runtime.GoMemLimit != "" {
+		if n, err := ParseSize(c.Runtime.GoMemLimit); err != nil {
+			fail("runtime.gomemlimit: %v", err)
+		} else if n <= 0 {
+			fail("runtime.gomemlimit %q: must be greater than zero", c.Runtime.GoMemLimit)
+		}
+	}
+	if c.Coordinator.ReconnectBaseDelay <= 0 || c.Coordinator.ReconnectMaxDelay < c.Coordinator.ReconnectBaseDelay {
+		fail("coordinator reconnect delays: reconnect_base_delay (%s) must be positive and at most reconnect_max_delay (%s)",
+			c.Coordinator.ReconnectBaseDelay, c.Coordinator.ReconnectMaxDelay)
+	}
+	if kt := c.Coordinator.KeepaliveTime; kt != 0 && (kt < 10*time.Second || c.Coordinator.KeepaliveTimeout <= 0) {
+		fail("coordinator keepalive: keepalive_time (%s) must be 0 or at least 10s, with a positive keepalive_timeout (%s)",
+			kt, c.Coordinator.KeepaliveTimeout)
+	}
+	for key := range c.Log.Attributes {
+		if sensitiveAttribute.MatchString(key) {
+			fail("log.attributes key %q: attributes are logged on every line and must not hold credentials", key)
+		}
+	}
+	if c.Admin.ListenAddr != "" && c.Admin.Token == "" {
+		fail("admin.token is required when admin.listen_addr is set")
+	}
+	if _, err := c.Admin.TLS.Build(); err != nil {
+		fail("admin.tls: %v", err)
+	}
+	if _, err := c.Health.TLS.Build(); err != nil {
+		fail("health.tls: %v", err)
+	}
+	if c.Tasks.MaxAttempts < 0 {
+		fail("tasks.max_attempts %d: must be 0 or greater", c.Tasks.MaxAttempts)
+	}
+	if c.Tasks.MaxPinRetries < 0 {
+		fail("tasks.max_pin_retries %d: must be 0 or greater", c.Tasks.MaxPinRetries)
+	}
+	if c.Tasks.PinTimeout < 0 {
+		fail("tasks.pin_timeout %s: must be 0 or greater", c.Tasks.PinTimeout)
+	}
+	if c.Tasks.MaxConcurrentPins < 0 {
+		fail("tasks.max_concurrent_pins %d: must be 0 or greater", c.Tasks.MaxConcurrentPins)
+	}
+	if c.IPFS.CircuitBreaker.FailureThreshold < 0 {
+		fail("ipfs.circuit_breaker.failure_threshold %d: must be 0 or greater", c.IPFS.CircuitBreaker.FailureThreshold)
+	}
+	if c.IPFS.MinPeersForTasks < 0 {
+		fail("ipfs.min_peers_for_tasks %d: must be 0 or greater", c.IPFS.MinPeersForTasks)
 	}
-	availableBytes := stat.Bavail * uint64(stat.Bsize)
-	usableBytes := availableBytes * 80 / 100
-	return int64(usableBytes / (1024 * 1024 * 1024))
+	if c.Content.BlocklistURL != "" {
+		if err := validateHTTPURL(c.Content.BlocklistURL); err != nil {
+			fail("content.blocklist_url: %v", err)
+		}
+	}
+	if c.Node.StorageMedia != "" && !diskstat.ValidMedia(c.Node.StorageMedia) {
+		fail("node.storage_media %q: must be ssd, hdd, nvme or network", c.Node.StorageMedia)
+	}
+	if c.Node.Group != "" && !validGroupName(c.Node.Group) {
+		fail("node.group %q: use 1-63 lowercase letters, digits and hyphens, starting and ending with a letter or digit", c.Node.Group)
+	}
+	switch c.Coordinator.Compression {
+	case "none", "gzip":
+	default:
+		fail("coordinator.compression %q: must be none or gzip", c.Coordinator.Compression)
+	}
+	switch c.Node.NameSuffix {
+	case "none", "peer_id", "identity_key":
+	default:
+		fail("node.name_suffix %q: must be none, peer_id or identity_key", c.Node.NameSuffix)
+	}
+	if err := ipfs.ValidateServePriority(c.IPFS.ServePriority); err != nil {
+		fail("ipfs.serve_priority: %v", err)
+	}
+	if err := ipfs.ValidateExperimentalFeatures(c.IPFS.Experimental); err != nil {
+		fail("ipfs.experimental: %v", err)
+	}
+	if c.IPFS.ConfigOverlayFile != "" {
+		if _, err := ipfs.LoadConfigOverlay(c.IPFS.ConfigOverlayFile); err != nil {
+			fail("ipfs.config_overlay_file: %v", err)
+		}
+	}
+	if c.Events.WebhookURL != "" {
+		if err := validateHTTPURL(c.Events.WebhookURL); err != nil {
+			fail("events.webhook_url: %v", err)
+		}
+	}
+	if _, err := ipfs.DaemonArgs(c.IPFS.Pubsub, c.IPFS.DaemonArgs); err != nil {
+		fail("ipfs.daemon_args: %v", err)
+	}
+	if c.IPFS.SwarmKey != "" {
+		if _, err := ipfs.NormalizeSwarmKey(c.IPFS.SwarmKey); err != nil {
+			fail("ipfs.swarm_key: %v", err)
+		}
+	}
+	if c.IPFS.GatewayURL != "" {
+		if err := validateHTTPURL(c.IPFS.GatewayURL); err != nil {
+			fail("ipfs.gateway_url: %v", err)
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func detectRegion() string {
-	tz := os.Getenv("TZ")
-	if tz != "" {
-		if strings.Contains(tz, "America") {
-			return "us"
+// resolveSecretRefs resolves every config value that is a secret reference and overrides it in viper.
+// A failed resolution is fatal and names the config key.
+func resolveSecretRefs() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, key := range viper.AllKeys() {
+		value, ok := viper.Get(key).(string)
+		if !ok || !secrets.IsReference(value) {
+			continue
 		}
-		if strings.Contains(tz, "Europe") {
-			return "eu"
+		if strings.HasPrefix(key, "log.attributes.") {
+			// Log attributes end up on every log line; a secret must never be resolved into one.
+			log.Fatalf("Invalid %s: log attributes cannot be secret references", key)
 		}
-		if strings.Contains(tz, "Asia") {
-			return "asia"
+		secret, err := secrets.Resolve(ctx, value)
+		if err != nil {
+			log.Fatalf("Failed to resolve secret for %s: %v", key, err)
 		}
+		viper.Set(key, secret)
+	}
+}
+
+// validateHTTPURL checks that raw is an absolute http(s) URL with a host.
+func validateHTTPURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q: scheme must be http or https", raw)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q: missing host", raw)
+	}
+	return nil
+}
+
+// groupNamePattern matches a DNS-label style group name, so groups are safe to use as identifiers and labels.
+var groupNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// validGroupName reports whether name is a valid node.group.
+func validGroupName(name string) bool {
+	return groupNamePattern.MatchString(name)
+}
+
+// sensitiveAttribute matches log attribute keys that suggest a credential.
+var sensitiveAttribute = regexp.MustCompile(`(?i)token|secret|password|passwd|credential|private|api_?key`)
+
+// detectStorageCapacity detects available disk space and returns usable capacity in bytes. The agent adds
+// the IPFS repo size once the daemon is running, as it does when re-detecting.
+func detectStorageCapacity() int64 {
+	wd, err := os.Getwd()
+	if err != nil {
+		return 0
+	}
+	usableBytes, err := diskstat.UsableBytes(wd)
+	if err != nil {
+		return 0
 	}
-	return "unknown"
+	return usableBytes
 }
 
 func generateNodeName() string {
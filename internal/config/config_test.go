@@ -0,0 +1,27 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestGenerateNodeNameHostname(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip("no hostname:", err)
+	}
+	name := generateNodeName()
+	if !strings.HasPrefix(name, "wabisaby-node-") {
+		t.Fatalf("generateNodeName = %q, want the wabisaby-node- prefix", name)
+	}
+	if want := strings.ToLower(strings.ReplaceAll(hostname, " ", "-")); !strings.Contains(name, want) {
+		t.Fatalf("generateNodeName = %q, want it to contain the hostname %q", name, want)
+	}
+	if name != strings.ToLower(name) || strings.Contains(name, " ") {
+		t.Fatalf("generateNodeName = %q, want lowercase without spaces", name)
+	}
+}
@@ -0,0 +1,269 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultRegionMap maps IANA time zone prefixes to regions. The longest matching prefix wins, so an entry
+// for a specific zone (e.g. "America/Sao_Paulo") overrides its area ("America").
+var defaultRegionMap = map[string]string{
+	"America":    "us",
+	"US":         "us",
+	"Canada":     "us",
+	"Europe":     "eu",
+	"Atlantic":   "eu",
+	"Arctic":     "eu",
+	"Asia":       "asia",
+	"Indian":     "asia",
+	"Africa":     "africa",
+	"Australia":  "oceania",
+	"Pacific":    "oceania",
+	"Antarctica": "antarctica",
+
+	// South America shares the "America" area with North America, so its zones are listed one by one.
+	"America/Araguaina":    "sa",
+	"America/Argentina":    "sa",
+	"America/Asuncion":     "sa",
+	"America/Bahia":        "sa",
+	"America/Belem":        "sa",
+	"America/Boa_Vista":    "sa",
+	"America/Bogota":       "sa",
+	"America/Buenos_Aires": "sa",
+	"America/Campo_Grande": "sa",
+	"America/Caracas":      "sa",
+	"America/Cayenne":      "sa",
+	"America/Cuiaba":       "sa",
+	"America/Eirunepe":     "sa",
+	"America/Fortaleza":    "sa",
+	"America/Guayaquil":    "sa",
+	"America/Guyana":       "sa",
+	"America/La_Paz":       "sa",
+	"America/Lima":         "sa",
+	"America/Maceio":       "sa",
+	"America/Manaus":       "sa",
+	"America/Montevideo":   "sa",
+	"America/Noronha":      "sa",
+	"America/Paramaribo":   "sa",
+	"America/Porto_Velho":  "sa",
+	"America/Punta_Arenas": "sa",
+	"America/Recife":       "sa",
+	"America/Rio_Branco":   "sa",
+	"America/Santarem":     "sa",
+	"America/Santiago":     "sa",
+	"America/Sao_Paulo":    "sa",
+	"Atlantic/Stanley":     "sa",
+	"Brazil":               "sa",
+	"Chile":                "sa",
+	"Pacific/Easter":       "sa",
+	"Pacific/Galapagos":    "sa",
+}
+
+// cloudRegionMap maps AWS, GCP and Azure region name prefixes to the regions used for time zones, so nodes
+// detecting their region either way can be compared. The longest matching prefix wins.
+var cloudRegionMap = map[string]string{
+	// AWS and GCP
+	"us-":            "us",
+	"ca-":            "us",
+	"mx-":            "us",
+	"northamerica-":  "us",
+	"sa-":            "sa",
+	"southamerica-":  "sa",
+	"eu-":            "eu",
+	"europe-":        "eu",
+	"ap-":            "asia",
+	"asia-":          "asia",
+	"cn-":            "asia",
+	"il-":            "asia",
+	"me-":            "asia",
+	"af-":            "africa",
+	"africa-":        "africa",
+	"ap-southeast-2": "oceania",
+	"ap-southeast-4": "oceania",
+	"ap-southeast-6": "oceania",
+	"australia":      "oceania", // also Azure's australiaeast etc.
+
+	// Azure
+	"eastus":         "us",
+	"westus":         "us",
+	"centralus":      "us",
+	"northcentralus": "us",
+	"southcentralus": "us",
+	"westcentralus":  "us",
+	"canada":         "us",
+	"mexico":         "us",
+	"brazil":         "sa",
+	"chile":          "sa",
+	"westeurope":     "eu",
+	"northeurope":    "eu",
+	"austria":        "eu",
+	"belgium":        "eu",
+	"denmark":        "eu",
+	"finland":        "eu",
+	"france":         "eu",
+	"germany":        "eu",
+	"italy":          "eu",
+	"norway":         "eu",
+	"poland":         "eu",
+	"spain":          "eu",
+	"sweden":         "eu",
+	"switzerland":    "eu",
+	"uk":             "eu",
+	"eastasia":       "asia",
+	"southeastasia":  "asia",
+	"centralindia":   "asia",
+	"southindia":     "asia",
+	"westindia":      "asia",
+	"jioindia":       "asia",
+	"china":          "asia",
+	"indonesia":      "asia",
+	"israel":         "asia",
+	"japan":          "asia",
+	"korea":          "asia",
+	"malaysia":       "asia",
+	"qatar":          "asia",
+	"taiwan":         "asia",
+	"uae":            "asia",
+	"southafrica":    "africa",
+	"newzealand":     "oceania",
+}
+
+// detectRegion determines the node's region: from the cloud provider's metadata service when enabled and
+// the provider's region is known to cloudRegionMap, otherwise from the local time zone mapped through defaultRegionMap overlaid with overrides.
+// It returns "unknown" as a last resort.
+func detectRegion(overrides map[string]string, fromCloud bool) string {
+	if fromCloud {
+		if region := regionForCloud(detectCloudRegion()); region != "" {
+			return region
+		}
+	}
+	if region := regionForTimeZone(localTimeZone(), overrides); region != "" {
+		return region
+	}
+	return "unknown"
+}
+
+// regionForTimeZone returns the region for tz using the longest matching prefix from overrides or the
+// default map, or "" if nothing matches. Matching is case-insensitive since config keys are lowercased.
+func regionForTimeZone(tz string, overrides map[string]string) string {
+	if tz == "" {
+		return ""
+	}
+	tz = strings.ToLower(tz)
+	best, bestLen := "", -1
+	match := func(m map[string]string, override bool) {
+		for prefix, region := range m {
+			p := strings.ToLower(prefix)
+			longer := len(p) > bestLen || override && len(p) == bestLen
+			if longer && (tz == p || strings.HasPrefix(tz, p+"/")) {
+				best, bestLen = region, len(p)
+			}
+		}
+	}
+	match(defaultRegionMap, false)
+	match(overrides, true) // an override of equal length wins over the default
+	return best
+}
+
+// regionForCloud returns the region for a cloud provider region name using the longest matching prefix of
+// cloudRegionMap, or "" if nothing matches.
+func regionForCloud(name string) string {
+	name = strings.ToLower(name)
+	best, bestLen := "", 0
+	for prefix, region := range cloudRegionMap {
+		if len(prefix) > bestLen && strings.HasPrefix(name, prefix) {
+			best, bestLen = region, len(prefix)
+		}
+	}
+	return best
+}
+
+// localTimeZone returns the IANA name of the local time zone from TZ or the /etc/localtime symlink.
+func localTimeZone() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		return tz
+	}
+	target, err := filepath.EvalSymlinks("/etc/localtime")
+	if err != nil {
+		return ""
+	}
+	if _, zone, ok := strings.Cut(target, "zoneinfo/"); ok {
+		return zone
+	}
+	return ""
+}
+
+// detectCloudRegion queries the AWS, GCP and Azure instance metadata services and returns the provider's
+// region name (e.g. "us-east-1", "europe-west1", "westeurope"), or "" when not running on these clouds.
+func detectCloudRegion() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	client := &http.Client{Timeout: time.Second}
+	for _, detect := range []func(context.Context, *http.Client) string{awsRegion, gcpRegion, azureRegion} {
+		if region := detect(ctx, client); region != "" {
+			return region
+		}
+	}
+	return ""
+}
+
+// awsRegion reads the region from the EC2 instance metadata service (IMDSv2).
+func awsRegion(ctx context.Context, client *http.Client) string {
+	token := metadataGet(ctx, client, http.MethodPut, "http://169.254.169.254/latest/api/token",
+		map[string]string{"X-aws-ec2-metadata-token-ttl-seconds": "60"})
+	if token == "" {
+		return ""
+	}
+	return metadataGet(ctx, client, http.MethodGet, "http://169.254.169.254/latest/meta-data/placement/region",
+		map[string]string{"X-aws-ec2-metadata-token": token})
+}
+
+// gcpRegion derives the region from the instance zone ("projects/<n>/zones/us-central1-a" -> "us-central1").
+func gcpRegion(ctx context.Context, client *http.Client) string {
+	zone := metadataGet(ctx, client, http.MethodGet, "http://metadata.google.internal/computeMetadata/v1/instance/zone",
+		map[string]string{"Metadata-Flavor": "Google"})
+	zone = zone[strings.LastIndex(zone, "/")+1:]
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return ""
+}
+
+// azureRegion reads the VM location from the Azure instance metadata service.
+func azureRegion(ctx context.Context, client *http.Client) string {
+	return metadataGet(ctx, client, http.MethodGet,
+		"http://169.254.169.254/metadata/instance/compute/location?api-version=2021-02-01&format=text",
+		map[string]string{"Metadata": "true"})
+}
+
+// metadataGet performs a metadata request and returns the trimmed body, or "" on any failure.
+func metadataGet(ctx context.Context, client *http.Client, method, url string, headers map[string]string) string {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return ""
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(body))
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package config
+
+import "testing"
+
+func TestRegionForTimeZone(t *testing.T) {
+	tests := []struct {
+		tz   string
+		want string
+	}{
+		{"America/New_York", "us"},
+		{"America/Argentina/Buenos_Aires", "sa"},
+		{"America/Sao_Paulo", "sa"},
+		{"America/Bogota", "sa"},
+		{"America/Mexico_City", "us"},
+		{"US/Pacific", "us"},
+		{"Europe/Berlin", "eu"},
+		{"Atlantic/Reykjavik", "eu"},
+		{"Asia/Tokyo", "asia"},
+		{"Indian/Maldives", "asia"},
+		{"Africa/Lagos", "africa"},
+		{"Australia/Sydney", "oceania"},
+		{"Pacific/Auckland", "oceania"},
+		{"Antarctica/McMurdo", "antarctica"},
+		{"Etc/UTC", ""},
+		{"UTC", ""},
+		{"", ""},
+		{"Americana/Nowhere", ""},
+	}
+	for _, tt := range tests {
+		if got := regionForTimeZone(tt.tz, nil); got != tt.want {
+			t.Errorf("regionForTimeZone(%q) = %q, want %q", tt.tz, got, tt.want)
+		}
+	}
+}
+
+func TestRegionForTimeZoneOverrides(t *testing.T) {
+	// Keys arrive lowercased from the config file.
+	overrides := map[string]string{
+		"america/sao_paulo": "sa",
+		"europe":            "eu-central",
+		"etc":               "eu",
+	}
+	tests := []struct {
+		tz   string
+		want string
+	}{
+		{"America/Sao_Paulo", "sa"},
+		{"America/Chicago", "us"},
+		{"Europe/Paris", "eu-central"},
+		{"Etc/UTC", "eu"},
+		{"Asia/Singapore", "asia"},
+	}
+	for _, tt := range tests {
+		if got := regionForTimeZone(tt.tz, overrides); got != tt.want {
+			t.Errorf("regionForTimeZone(%q) = %q, want %q", tt.tz, got, tt.want)
+		}
+	}
+}
+
+func TestRegionForCloud(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"us-east-1", "us"},
+		{"ca-central-1", "us"},
+		{"sa-east-1", "sa"},
+		{"eu-west-1", "eu"},
+		{"ap-northeast-1", "asia"},
+		{"ap-southeast-2", "oceania"},
+		{"af-south-1", "africa"},
+		{"us-central1", "us"},
+		{"northamerica-northeast1", "us"},
+		{"southamerica-east1", "sa"},
+		{"europe-west1", "eu"},
+		{"asia-southeast1", "asia"},
+		{"australia-southeast1", "oceania"},
+		{"africa-south1", "africa"},
+		{"eastus2", "us"},
+		{"westeurope", "eu"},
+		{"uksouth", "eu"},
+		{"brazilsouth", "sa"},
+		{"southeastasia", "asia"},
+		{"australiaeast", "oceania"},
+		{"southafricanorth", "africa"},
+		{"WestEurope", "eu"},
+		{"mars-north-1", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := regionForCloud(tt.name); got != tt.want {
+			t.Errorf("regionForCloud(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDetectRegionFromTZ(t *testing.T) {
+	t.Setenv("TZ", ":Africa/Nairobi")
+	if got := detectRegion(nil, false); got != "africa" {
+		t.Errorf("detectRegion with TZ=:Africa/Nairobi = %q, want africa", got)
+	}
+	t.Setenv("TZ", "Etc/GMT+5")
+	if got := detectRegion(nil, false); got != "unknown" {
+		t.Errorf("detectRegion with an unmapped time zone = %q, want unknown", got)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package config
+
+import "testing"
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"1024", 1024},
+		{"512B", 512},
+		{"1KB", 1_000},
+		{"1KiB", 1_024},
+		{"500MB", 500_000_000},
+		{"500MiB", 500 << 20},
+		{"500GB", 500_000_000_000},
+		{"100GiB", 100 << 30},
+		{"2TB", 2_000_000_000_000},
+		{"2TiB", 2 << 40},
+		{"1PB", 1_000_000_000_000_000},
+		{"1.5 TB", 1_500_000_000_000},
+		{"  100gib ", 100 << 30},
+		{"0.5GiB", 512 << 20},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.in)
+		if err != nil {
+			t.Errorf("ParseSize(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseSizeDecimalVersusBinary(t *testing.T) {
+	gb, _ := ParseSize("1GB")
+	gib, _ := ParseSize("1GiB")
+	if gb != 1_000_000_000 || gib != 1_073_741_824 {
+		t.Fatalf("1GB = %d and 1GiB = %d, want 10^9 and 2^30", gb, gib)
+	}
+}
+
+func TestParseSizeInvalid(t *testing.T) {
+	for _, in := range []string{
+		"",
+		"   ",
+		"GB",
+		"-5GB",
+		"1.2.3GB",
+		"10XB",
+		"10 G B",
+		"100000000PB",
+		"8192PiB",
+	} {
+		if got, err := ParseSize(in); err == nil {
+			t.Errorf("ParseSize(%q) = %d, want an error", in, got)
+		}
+	}
+}
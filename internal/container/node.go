@@ -7,57 +7,292 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"maps"
+	"math"
 	"os"
+	"runtime/debug"
+	"slices"
 	"time"
 
+	"github.com/wabisaby/wabisaby-node/internal/admin"
 	"github.com/wabisaby/wabisaby-node/internal/agent"
 	"github.com/wabisaby/wabisaby-node/internal/config"
+	"github.com/wabisaby/wabisaby-node/internal/events"
+	"github.com/wabisaby/wabisaby-node/internal/health"
 	"github.com/wabisaby/wabisaby-node/internal/ipfs"
+	"github.com/wabisaby/wabisaby-node/internal/logging"
+	"github.com/wabisaby/wabisaby-node/internal/metrics"
+	"github.com/wabisaby/wabisaby-node/internal/stats"
 	"go.uber.org/fx"
 )
 
-// ProvideNodeLogger provides a structured logger for the node based on config.
+// nodeVersion is the version of the node software, logged and attached to every log line.
+const nodeVersion = "1.0.0"
+
+// ProvideNodeLogger provides a structured logger for the node based on config, writing text or JSON
+// (log.format). Every line carries the node name, region, version and the configured log.attributes; the
+// agent adds the node ID once it has registered (see logging.SetNodeID).
 func ProvideNodeLogger(cfg *config.NodeConfig) *slog.Logger {
-	level := slog.LevelInfo
-	if cfg.Log.Level == "debug" {
-		level = slog.LevelDebug
+	level, _ := logging.ParseLevel(cfg.Log.Level) // log.level is validated at load
+	opts := &slog.HandlerOptions{Level: level, ReplaceAttr: logging.ReplaceLevelName}
+	var inner slog.Handler = slog.NewTextHandler(os.Stdout, opts)
+	if cfg.Log.Format == "json" {
+		inner = slog.NewJSONHandler(os.Stdout, opts)
+	}
+	handler := logging.NewHandler(inner)
+	attrs := []any{"region", cfg.Node.Region, "version", nodeVersion}
+	if cfg.Node.Name != "" {
+		attrs = append([]any{"node_name", cfg.Node.Name}, attrs...)
 	}
-	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+	for _, key := range slices.Sorted(maps.Keys(cfg.Log.Attributes)) {
+		attrs = append(attrs, key, cfg.Log.Attributes[key])
+	}
+	return slog.New(handler).With(attrs...)
 }
 
 // ProvideIPFSManager provides the IPFS lifecycle manager.
 func ProvideIPFSManager(cfg *config.NodeConfig, logger *slog.Logger) *ipfs.IPFSManager {
 	managerCfg := ipfs.ManagerConfig{
-		BinaryPath: "", // Auto-detect
-		DataDir:    cfg.IPFS.DataDir,
-		APIURL:     cfg.IPFS.APIURL,
-		Logger:     logger,
+		BinaryPath:    "", // Auto-detect
+		DataDir:       cfg.IPFS.DataDir,
+		RepoPath:      cfg.IPFS.RepoPath,
+		APIURL:        cfg.IPFS.APIURL,
+		Experimental:  cfg.IPFS.Experimental,
+		OverlayFile:   cfg.IPFS.ConfigOverlayFile,
+		PreserveID:    cfg.IPFS.PreserveIdentity,
+		RepairRepo:    cfg.IPFS.RepairRepo,
+		ServePriority: cfg.IPFS.ServePriority,
+		AdoptDaemon:   cfg.IPFS.AdoptDaemon,
+		ClientOptions: ipfsClientOptions(cfg, logger),
+		KuboVersion:   cfg.IPFS.KuboVersion,
+		SwarmKey:      cfg.IPFS.SwarmKey,
+		Pubsub:        cfg.IPFS.Pubsub,
+		DaemonArgs:    cfg.IPFS.DaemonArgs,
+		AutoRestart:   cfg.IPFS.AutoRestart,
+		Logger:        logger,
+
+		// The pinned download version is also the version an installed binary is expected to report
+		ExpectedKuboVersion: cfg.IPFS.KuboVersion,
+		StrictVersion:       cfg.IPFS.StrictVersion,
 	}
 	return ipfs.NewIPFSManager(managerCfg)
 }
 
+// ipfsClientOptions returns the IPFS API client options derived from config, shared by the manager's
+// clients and the agent's (see IPFSManager.ClientOptions).
+func ipfsClientOptions(cfg *config.NodeConfig, logger *slog.Logger) []ipfs.ClientOption {
+	if !cfg.IPFS.TraceRequests {
+		return nil
+	}
+	return []ipfs.ClientOption{ipfs.WithRequestTracing(logger, cfg.IPFS.TraceRedactArgs)}
+}
+
+// ProvideEventNotifier provides the event webhook notifier and runs its delivery loop for the app lifetime.
+// On stop, queued events (including the shutdown event) are flushed until the stop context expires.
+func ProvideEventNotifier(lc fx.Lifecycle, cfg *config.NodeConfig, logger *slog.Logger) *events.Notifier {
+	notifier := events.NewNotifier(events.Config{
+		WebhookURL:   cfg.Events.WebhookURL,
+		Timeout:      cfg.Events.Timeout,
+		MaxRetries:   cfg.Events.MaxRetries,
+		DedupeWindow: cfg.Events.DedupeWindow,
+		NodeName:     cfg.Node.Name,
+		Logger:       logger,
+	})
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			go func() {
+				defer close(stopped)
+				notifier.Run(runCtx)
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			notifier.Close()
+			select {
+			case <-stopped:
+			case <-ctx.Done():
+			}
+			cancel()
+			return nil
+		},
+	})
+	return notifier
+}
+
 // ProvideNodeAgent provides the storage node agent.
 func ProvideNodeAgent(
 	cfg *config.NodeConfig,
 	ipfsManager *ipfs.IPFSManager,
+	notifier *events.Notifier,
+	collector *stats.Collector,
 	logger *slog.Logger,
 ) *agent.Agent {
 	agentCfg := agent.AgentConfig{
-		CoordinatorAddr:   cfg.Coordinator.Address,
-		AuthToken:         cfg.Auth.Token,
-		RefreshToken:      cfg.Auth.RefreshToken,
-		KeycloakTokenURL:  cfg.Auth.KeycloakTokenURL,
-		KeycloakClientID:  cfg.Auth.KeycloakClientID,
-		IPFSAPIURL:        cfg.IPFS.APIURL,
-		IPFSDataDir:       cfg.IPFS.DataDir,
-		NodeName:          cfg.Node.Name,
-		Region:            cfg.Node.Region,
-		WalletAddress:     cfg.Node.WalletAddress,
-		CapacityBytes:     cfg.Storage.CapacityGB * 1024 * 1024 * 1024,
-		HeartbeatInterval: cfg.Intervals.Heartbeat,
-		PollInterval:      cfg.Intervals.Poll,
-	}
-	return agent.NewAgent(agentCfg, ipfsManager, logger)
+		CoordinatorAddr:         cfg.Coordinator.Address,
+		DialTimeout:             cfg.Coordinator.DialTimeout,
+		LazyDial:                cfg.Coordinator.LazyDial,
+		TLSEnabled:              cfg.Coordinator.TLS.Enabled,
+		TLSMinVersion:           cfg.Coordinator.TLS.MinVersion,
+		TLSCipherSuites:         cfg.Coordinator.TLS.CipherSuites,
+		TLSCAFile:               cfg.Coordinator.TLS.CAFile,
+		TLSClientCertFile:       cfg.Coordinator.TLS.ClientCertFile,
+		TLSClientKeyFile:        cfg.Coordinator.TLS.ClientKeyFile,
+		TLSServerName:           cfg.Coordinator.TLS.ServerNameOverride,
+		AuthToken:               cfg.Auth.Token,
+		RefreshToken:            cfg.Auth.RefreshToken,
+		KeycloakTokenURL:        cfg.Auth.KeycloakTokenURL,
+		KeycloakClientID:        cfg.Auth.KeycloakClientID,
+		IPFSAPIURL:              cfg.IPFS.APIURL,
+		IPFSDataDir:             cfg.IPFS.DataDir,
+		IPFSGatewayURL:          cfg.IPFS.GatewayURL,
+		IPFSBreakerThreshold:    cfg.IPFS.CircuitBreaker.FailureThreshold,
+		IPFSBreakerCooldown:     cfg.IPFS.CircuitBreaker.Cooldown,
+		NodeName:                cfg.Node.Name,
+		NameSuffix:              cfg.Node.NameSuffix,
+		Region:                  cfg.Node.Region,
+		Group:                   cfg.Node.Group,
+		WalletAddress:           cfg.Node.WalletAddress,
+		MaxMultiaddrs:           cfg.Node.MaxMultiaddrs,
+		RequireReachable:        cfg.Node.RequireReachable,
+		ReachabilityTimeout:     cfg.Node.ReachabilityTimeout,
+		MaxPeers:                cfg.IPFS.MaxPeers,
+		PreferSameRegion:        cfg.IPFS.PreferSameRegion,
+		MinPeersForTasks:        cfg.IPFS.MinPeersForTasks,
+		DiagnosePinFailures:     cfg.IPFS.Diagnostics.OnPinFailure,
+		DiagnoseFindProviders:   cfg.IPFS.Diagnostics.FindProviders,
+		ReverifyAfter:           cfg.IPFS.ReverifyAfter,
+		IdentityKeyFile:         cfg.Node.IdentityKeyFile,
+		StorageMedia:            cfg.Node.StorageMedia,
+		CapacityBytes:           cfg.Storage.CapacityBytes,
+		CapacityAutoDetect:      cfg.Storage.AutoDetected,
+		CapacityRecheckInterval: cfg.Storage.RecheckInterval,
+		CapacityChangeThreshold: cfg.Storage.ChangeThreshold,
+		GCHighWatermark:         cfg.Storage.GCHighWatermark,
+		GCMinInterval:           cfg.Storage.GCMinInterval,
+		HeartbeatInterval:       cfg.Intervals.Heartbeat,
+		HeartbeatGrace:          cfg.Intervals.HeartbeatGrace,
+		ReconnectBaseDelay:      cfg.Coordinator.ReconnectBaseDelay,
+		ReconnectMaxDelay:       cfg.Coordinator.ReconnectMaxDelay,
+		KeepaliveTime:           cfg.Coordinator.KeepaliveTime,
+		KeepaliveTimeout:        cfg.Coordinator.KeepaliveTimeout,
+		PollInterval:            cfg.Intervals.Poll,
+		MaxPollBackoff:          cfg.Intervals.MaxPollBackoff,
+		MaxHeartbeatBackoff:     cfg.Intervals.MaxHeartbeatBackoff,
+		AckTasks:                cfg.Coordinator.AckTasks,
+		Compression:             cfg.Coordinator.Compression,
+		DisabledFeatures:        cfg.Features.Disable,
+		MaxTaskAttempts:         cfg.Tasks.MaxAttempts,
+		MaxPinRetries:           cfg.Tasks.MaxPinRetries,
+		MaxPinDuration:          cfg.Tasks.MaxPinDuration,
+		PinTimeout:              cfg.Tasks.PinTimeout,
+		MaxInflightPinTime:      cfg.Tasks.MaxInflightPinTime,
+		MaxConcurrentPins:       cfg.Tasks.MaxConcurrentPins,
+		PinNames:                cfg.Tasks.PinNames,
+		VerifyPins:              cfg.Tasks.VerifyPins,
+		AcceptMaxSizeBytes:      cfg.Tasks.Accept.MaxSizeBytes,
+		AcceptRequiredLabels:    cfg.Tasks.Accept.RequiredLabels,
+		AcceptExcludedLabels:    cfg.Tasks.Accept.ExcludedLabels,
+		MinimalHeartbeat:        cfg.Coordinator.MinimalHeartbeat,
+		MinRegisterInterval:     cfg.Coordinator.MinRegisterInterval,
+		RegisterStateFile:       cfg.Coordinator.RegisterStateFile,
+		PeerCache:               cfg.Coordinator.PeerCache,
+		PeerCacheFile:           cfg.Coordinator.PeerCacheFile,
+		RegisterFirst:           cfg.Startup.RegisterFirst,
+		BlocklistFile:           cfg.Content.BlocklistFile,
+		BlocklistURL:            cfg.Content.BlocklistURL,
+		BlocklistRefresh:        cfg.Content.BlocklistRefresh,
+		InventoryFile:           cfg.Storage.InventoryFile,
+		TaskStateFile:           cfg.Tasks.StateFile,
+		AlwaysPin:               cfg.Content.AlwaysPin,
+		ReconcileInterval:       cfg.Intervals.Reconcile,
+		PeerRefreshInterval:     cfg.Intervals.PeerRefresh,
+		ReconcileBatchSize:      cfg.Reconcile.BatchSize,
+		ReconcileBatchPause:     cfg.Reconcile.BatchPause,
+		ReconcileVerifyPins:     cfg.Reconcile.VerifyPins,
+		ShutdownDrainTimeout:    cfg.Shutdown.DrainTimeout,
+		DeregisterOnShutdown:    cfg.Shutdown.Deregister,
+	}
+	return agent.NewAgent(agentCfg, ipfsManager, notifier, collector, logger)
+}
+
+// StartAdminServer runs the admin API when admin.listen_addr is set. GET /stats serves the stats
+// collector's snapshot, GET /pins the agent's named pins; POST /shutdown triggers the same graceful
+// shutdown as SIGTERM through the fx Shutdowner.
+func StartAdminServer(lc fx.Lifecycle, cfg *config.NodeConfig, collector *stats.Collector, nodeAgent *agent.Agent, shutdowner fx.Shutdowner, logger *slog.Logger) error {
+	if cfg.Admin.ListenAddr == "" {
+		return nil
+	}
+	tlsCfg, err := cfg.Admin.TLS.Build()
+	if err != nil {
+		return fmt.Errorf("admin.tls: %w", err)
+	}
+	server := admin.New(admin.Config{
+		ListenAddr: cfg.Admin.ListenAddr,
+		Token:      cfg.Admin.Token,
+		Timeouts:   cfg.HTTP.Timeouts(),
+		TLS:        tlsCfg,
+		Stats:      collector.Snapshot,
+		Pins:       nodeAgent.NamedPins,
+		Logger:     logger,
+	}, func() error { return shutdowner.Shutdown() })
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error { return server.Start() },
+		OnStop:  server.Stop,
+	})
+	return nil
+}
+
+// ProvideMetrics provides the Prometheus metrics exported from the stats collector, or nil when
+// metrics.enabled is off.
+func ProvideMetrics(cfg *config.NodeConfig, collector *stats.Collector) *metrics.Metrics {
+	if !cfg.Metrics.Enabled {
+		return nil
+	}
+	return metrics.New(collector)
+}
+
+// StartHealthServer serves /healthz and /readyz, and /metrics when metrics are enabled, when health.addr
+// is set. /readyz reports the agent's component health and fails while any component is critical.
+func StartHealthServer(lc fx.Lifecycle, cfg *config.NodeConfig, nodeAgent *agent.Agent, m *metrics.Metrics, logger *slog.Logger) error {
+	if cfg.Health.Addr == "" {
+		return nil
+	}
+	tlsCfg, err := cfg.Health.TLS.Build()
+	if err != nil {
+		return fmt.Errorf("health.tls: %w", err)
+	}
+	healthCfg := health.Config{
+		ListenAddr: cfg.Health.Addr,
+		Timeouts:   cfg.HTTP.Timeouts(),
+		TLS:        tlsCfg,
+		Logger:     logger,
+	}
+	if m != nil {
+		healthCfg.Metrics = m.Handler()
+	}
+	server := health.NewServer(healthCfg, nodeAgent.Health)
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error { return server.Start() },
+		OnStop:  server.Stop,
+	})
+	return nil
+}
+
+// ApplyMemoryLimit sets the Go soft memory limit from runtime.gomemlimit and logs the effective limit.
+// Without the setting the runtime keeps the limit from the GOMEMLIMIT environment variable, if any.
+func ApplyMemoryLimit(cfg *config.NodeConfig, logger *slog.Logger) {
+	if cfg.Runtime.GoMemLimitBytes > 0 {
+		debug.SetMemoryLimit(cfg.Runtime.GoMemLimitBytes)
+	}
+	limit := debug.SetMemoryLimit(-1)
+	if limit == math.MaxInt64 {
+		logger.Info("go memory limit not set", "gogc", os.Getenv("GOGC"))
+		return
+	}
+	logger.Info("go memory limit set", "limit_bytes", limit, "gogc", os.Getenv("GOGC"))
 }
 
 // StartNodeAgent starts the node agent and handles graceful shutdown.
@@ -65,9 +300,10 @@ func StartNodeAgent(
 	lc fx.Lifecycle,
 	cfg *config.NodeConfig,
 	nodeAgent *agent.Agent,
+	notifier *events.Notifier,
 	logger *slog.Logger,
 ) {
-	logger.Info("starting WabiSaby storage node", "name", cfg.Node.Name, "version", "1.0.0")
+	logger.Info("starting WabiSaby storage node")
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -83,12 +319,29 @@ func StartNodeAgent(
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
+			notifier.Notify(events.Shutdown, "storage node shutting down", nil)
+			if err := nodeAgent.Stop(ctx); err != nil {
+				logger.Warn("agent shutdown finished with error", "error", err)
+			}
 			logger.Info("storage node shutdown successful")
 			return nil
 		},
 	})
 }
 
+// shutdownMargin is the time allowed on top of shutdown.drain_timeout for deregistering, stopping the IPFS
+// daemon and flushing events.
+const shutdownMargin = 30 * time.Second
+
+// ShutdownTimeout returns how long stopping the node may take: the pin drain timeout plus time for the
+// shutdown steps after it, and at least one minute.
+func ShutdownTimeout(cfg *config.NodeConfig) time.Duration {
+	if cfg == nil {
+		return time.Minute
+	}
+	return max(cfg.Shutdown.DrainTimeout+shutdownMargin, time.Minute)
+}
+
 // NodeModule provides all node-specific dependencies.
 // This module is standalone and does not require CommonModule since
 // the node is community-deployable and doesn't need core app dependencies.
@@ -97,9 +350,15 @@ var NodeModule = fx.Module("node",
 		config.LoadNodeConfig,
 		ProvideNodeLogger,
 		ProvideIPFSManager,
+		ProvideEventNotifier,
+		stats.NewCollector,
+		ProvideMetrics,
 		ProvideNodeAgent,
 	),
 	fx.Invoke(
+		ApplyMemoryLimit,
 		StartNodeAgent,
+		StartAdminServer,
+		StartHealthServer,
 	),
 )
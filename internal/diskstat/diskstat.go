@@ -0,0 +1,18 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package diskstat reports free disk space in a platform-independent way.
+package diskstat
+
+// UsableFraction is the share of free disk space offered as storage capacity; the rest is left for the OS.
+const UsableFraction = 0.8
+
+// UsableBytes returns UsableFraction of the space available to unprivileged users on the filesystem
+// containing path.
+func UsableBytes(path string) (int64, error) {
+	avail, err := AvailableBytes(path)
+	if err != nil {
+		return 0, err
+	}
+	return int64(float64(avail) * UsableFraction), nil
+}
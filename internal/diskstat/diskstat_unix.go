@@ -0,0 +1,17 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+//go:build !windows
+
+package diskstat
+
+import "syscall"
+
+// AvailableBytes returns the bytes available to unprivileged users on the filesystem containing path.
+func AvailableBytes(path string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Bavail * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,21 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+//go:build windows
+
+package diskstat
+
+import "golang.org/x/sys/windows"
+
+// AvailableBytes returns the bytes available to the current user on the volume containing path.
+func AvailableBytes(path string) (uint64, error) {
+	p, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var freeToCaller, total, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(p, &freeToCaller, &total, &totalFree); err != nil {
+		return 0, err
+	}
+	return freeToCaller, nil
+}
@@ -0,0 +1,26 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package diskstat
+
+import "errors"
+
+// Storage media types reported to the coordinator for tiered placement.
+const (
+	MediaSSD     = "ssd"
+	MediaHDD     = "hdd"
+	MediaNVMe    = "nvme"
+	MediaNetwork = "network"
+)
+
+// ErrMediaUnknown is returned when the media type of a path cannot be determined on this platform.
+var ErrMediaUnknown = errors.New("storage media type cannot be detected")
+
+// ValidMedia reports whether media is one of the supported media types.
+func ValidMedia(media string) bool {
+	switch media {
+	case MediaSSD, MediaHDD, MediaNVMe, MediaNetwork:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package diskstat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Filesystem magic numbers of network filesystems (see statfs(2)).
+var networkFilesystems = map[uint32]bool{
+	0x6969:     true, // NFS
+	0xff534d42: true, // CIFS
+	0xfe534d42: true, // SMB2
+	0x517b:     true, // SMB
+	0x00c36400: true, // Ceph
+	0x47504653: true, // GPFS
+	0x013111a8: true, // IBRIX
+}
+
+// MediaType returns the storage media type backing path: network for network filesystems, otherwise
+// nvme, ssd or hdd from the block device's sysfs attributes. A path that does not exist yet is resolved
+// through its nearest existing parent.
+func MediaType(path string) (string, error) {
+	path = existingParent(path)
+
+	var fs unix.Statfs_t
+	if err := unix.Statfs(path, &fs); err != nil {
+		return "", err
+	}
+	if networkFilesystems[uint32(fs.Type)] {
+		return MediaNetwork, nil
+	}
+
+	var st unix.Stat_t
+	if err := unix.Stat(path, &st); err != nil {
+		return "", err
+	}
+	dev := uint64(st.Dev)
+	devPath, err := filepath.EvalSymlinks(fmt.Sprintf("/sys/dev/block/%d:%d", unix.Major(dev), unix.Minor(dev)))
+	if err != nil {
+		// No block device (tmpfs, overlay, ...).
+		return "", ErrMediaUnknown
+	}
+	// Partitions have no queue attributes; use the whole disk.
+	if _, err := os.Stat(filepath.Join(devPath, "partition")); err == nil {
+		devPath = filepath.Dir(devPath)
+	}
+	if strings.HasPrefix(filepath.Base(devPath), "nvme") {
+		return MediaNVMe, nil
+	}
+	rotational, err := os.ReadFile(filepath.Join(devPath, "queue", "rotational"))
+	if err != nil {
+		return "", ErrMediaUnknown
+	}
+	if strings.TrimSpace(string(rotational)) == "1" {
+		return MediaHDD, nil
+	}
+	return MediaSSD, nil
+}
+
+// existingParent returns path or its nearest ancestor that exists.
+func existingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
@@ -0,0 +1,11 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+//go:build !linux
+
+package diskstat
+
+// MediaType is only implemented on Linux; elsewhere it returns ErrMediaUnknown.
+func MediaType(path string) (string, error) {
+	return "", ErrMediaUnknown
+}
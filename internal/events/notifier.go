@@ -0,0 +1,207 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Type identifies a significant node event delivered to the webhook.
+type Type string
+
+const (
+	Registered             Type = "node.registered"
+	CapacityDegraded       Type = "capacity.degraded"
+	CapacityCritical       Type = "capacity.critical"
+	IPFSDaemonCrashed      Type = "ipfs.daemon_crashed"
+	IPFSDaemonRestarted    Type = "ipfs.daemon_restarted"
+	CoordinatorDisconnect  Type = "coordinator.disconnected"
+	CoordinatorReconnected Type = "coordinator.reconnected"
+	Shutdown               Type = "node.shutdown"
+	TasksPaused            Type = "tasks.paused"
+	TasksResumed           Type = "tasks.resumed"
+)
+
+// Event is the JSON payload POSTed to the webhook.
+type Event struct {
+	Type     Type           `json:"type"`
+	Time     time.Time      `json:"time"`
+	NodeName string         `json:"node_name"`
+	NodeID   string         `json:"node_id,omitempty"`
+	Message  string         `json:"message,omitempty"`
+	Fields   map[string]any `json:"fields,omitempty"`
+}
+
+// Config holds webhook notifier settings.
+type Config struct {
+	WebhookURL   string        // Endpoint receiving event POSTs; notifier is disabled if empty
+	Timeout      time.Duration // Per-attempt HTTP timeout
+	MaxRetries   int           // Retries after the first failed attempt
+	DedupeWindow time.Duration // Repeated events of the same type within this window are dropped
+	NodeName     string        // Included in every payload
+	Logger       *slog.Logger
+}
+
+// Notifier delivers events to an operator-supplied webhook asynchronously. Events are queued and sent by a
+// background goroutine so a slow or failing endpoint never stalls the caller; when the queue is full events
+// are dropped. A nil or disabled Notifier is a no-op.
+type Notifier struct {
+	cfg        Config
+	httpClient *http.Client
+	queue      chan Event
+	done       chan struct{}
+
+	mu       sync.Mutex
+	nodeID   string
+	lastSent map[Type]time.Time
+}
+
+// NewNotifier creates a webhook notifier. It returns a disabled notifier when cfg.WebhookURL is empty.
+func NewNotifier(cfg Config) *Notifier {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 5 * time.Second
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	return &Notifier{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		queue:      make(chan Event, 64),
+		done:       make(chan struct{}),
+		lastSent:   make(map[Type]time.Time),
+	}
+}
+
+// Enabled reports whether events are delivered anywhere.
+func (n *Notifier) Enabled() bool {
+	return n != nil && n.cfg.WebhookURL != ""
+}
+
+// SetNodeID sets the coordinator-assigned node ID included in subsequent events.
+func (n *Notifier) SetNodeID(nodeID string) {
+	if n == nil {
+		return
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.nodeID = nodeID
+}
+
+// Notify queues an event for delivery. It never blocks; duplicates within the dedupe window and events
+// arriving while the queue is full are dropped.
+func (n *Notifier) Notify(typ Type, message string, fields map[string]any) {
+	if !n.Enabled() {
+		return
+	}
+
+	now := time.Now()
+	n.mu.Lock()
+	if last, ok := n.lastSent[typ]; ok && now.Sub(last) < n.cfg.DedupeWindow {
+		n.mu.Unlock()
+		return
+	}
+	n.lastSent[typ] = now
+	nodeID := n.nodeID
+	n.mu.Unlock()
+
+	event := Event{
+		Type:     typ,
+		Time:     now.UTC(),
+		NodeName: n.cfg.NodeName,
+		NodeID:   nodeID,
+		Message:  message,
+		Fields:   fields,
+	}
+	select {
+	case n.queue <- event:
+	default:
+		n.cfg.Logger.Warn("event queue full, dropping event", "type", typ)
+	}
+}
+
+// Run delivers queued events until Close is called, then drains what is left in the queue.
+func (n *Notifier) Run(ctx context.Context) {
+	if !n.Enabled() {
+		return
+	}
+	for {
+		select {
+		case event := <-n.queue:
+			n.deliver(ctx, event)
+		case <-n.done:
+			for {
+				select {
+				case event := <-n.queue:
+					n.deliver(ctx, event)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Close stops accepting new deliveries once the queue has been drained by Run.
+func (n *Notifier) Close() {
+	if !n.Enabled() {
+		return
+	}
+	close(n.done)
+}
+
+// deliver POSTs a single event, retrying with exponential backoff.
+func (n *Notifier) deliver(ctx context.Context, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		n.cfg.Logger.Warn("failed to encode event", "type", event.Type, "error", err)
+		return
+	}
+
+	backoff := 1 * time.Second
+	for attempt := 0; ; attempt++ {
+		err = n.post(ctx, body)
+		if err == nil {
+			return
+		}
+		if attempt >= n.cfg.MaxRetries {
+			n.cfg.Logger.Warn("event webhook delivery failed", "type", event.Type, "attempts", attempt+1, "error", err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post performs one webhook request.
+func (n *Notifier) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package health tracks the node's degraded subsystems. Each monitor reports the severity of its own
+// component; the overall status is the worst of them, so a node can keep working while telling the
+// coordinator and operators exactly what is wrong.
+package health
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Severity is how badly a component is affected.
+type Severity int
+
+const (
+	SeverityOK       Severity = iota // Working normally
+	SeverityDegraded                 // Working with reduced performance or margin
+	SeverityCritical                 // Not working; the node cannot do its job through this component
+)
+
+// String returns "ok", "degraded" or "critical".
+func (s Severity) String() string {
+	switch s {
+	case SeverityDegraded:
+		return "degraded"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "ok"
+	}
+}
+
+// MarshalText encodes the severity as its string form in JSON.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Components reported by the node's monitors.
+const (
+	ComponentIPFS        = "ipfs"        // IPFS API slow or failing
+	ComponentPeers       = "peers"       // Too few swarm peers to accept tasks
+	ComponentCapacity    = "capacity"    // Storage nearly or completely full
+	ComponentCoordinator = "coordinator" // Coordinator connection lost or flapping
+	ComponentDisk        = "disk"        // Node state directory not writable (e.g. read-only filesystem)
+	ComponentDaemon      = "ipfs_daemon" // IPFS daemon not started or not ready
+	ComponentHeartbeat   = "heartbeat"   // No successful heartbeat within two heartbeat intervals
+)
+
+// Component is the health of one subsystem.
+type Component struct {
+	Name     string    `json:"name"`
+	Severity Severity  `json:"severity"`
+	Message  string    `json:"message,omitempty"`
+	Since    time.Time `json:"since"` // When the component entered its current severity
+}
+
+// Status is the aggregated health of the node.
+type Status struct {
+	Overall    Severity    `json:"status"`
+	Components []Component `json:"components"` // Sorted by name
+}
+
+// Failed returns the names of the critical components.
+func (s Status) Failed() []string {
+	var out []string
+	for _, c := range s.Components {
+		if c.Severity >= SeverityCritical {
+			out = append(out, c.Name)
+		}
+	}
+	return out
+}
+
+// Degraded returns the components that are not OK.
+func (s Status) Degraded() []Component {
+	var out []Component
+	for _, c := range s.Components {
+		if c.Severity != SeverityOK {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// Tracker holds the latest health of each component. It is safe for concurrent use.
+type Tracker struct {
+	mu         sync.RWMutex
+	components map[string]Component
+	now        func() time.Time
+}
+
+// NewTracker returns an empty tracker; components appear as their monitors first report.
+func NewTracker() *Tracker {
+	return &Tracker{components: make(map[string]Component), now: time.Now}
+}
+
+// Set records the severity and message of a component and reports whether its severity changed. The
+// message may change without resetting Since.
+func (t *Tracker) Set(name string, severity Severity, message string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prev, known := t.components[name]
+	changed := known && prev.Severity != severity || !known && severity != SeverityOK
+	since := prev.Since
+	if !known || prev.Severity != severity {
+		since = t.now()
+	}
+	t.components[name] = Component{Name: name, Severity: severity, Message: message, Since: since}
+	return changed
+}
+
+// OK marks a component as healthy and reports whether it was degraded before.
+func (t *Tracker) OK(name string) bool {
+	return t.Set(name, SeverityOK, "")
+}
+
+// Status returns a snapshot of every component and the overall severity (the worst component's).
+func (t *Tracker) Status() Status {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	status := Status{Components: make([]Component, 0, len(t.components))}
+	for _, c := range t.components {
+		status.Components = append(status.Components, c)
+		status.Overall = max(status.Overall, c.Severity)
+	}
+	slices.SortFunc(status.Components, func(a, b Component) int { return strings.Compare(a.Name, b.Name) })
+	return status
+}
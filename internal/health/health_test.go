@@ -0,0 +1,80 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package health
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTrackerCombinesComponents(t *testing.T) {
+	tr := NewTracker()
+	tr.Set(ComponentIPFS, SeverityDegraded, "IPFS API slow")
+	tr.Set(ComponentPeers, SeverityDegraded, "1 swarm peer connected")
+	tr.OK(ComponentDisk)
+
+	s := tr.Status()
+	if s.Overall != SeverityDegraded {
+		t.Fatalf("overall = %s, want degraded", s.Overall)
+	}
+	if failed := s.Failed(); len(failed) != 0 {
+		t.Fatalf("failed = %v, want none", failed)
+	}
+	var degraded []string
+	for _, c := range s.Degraded() {
+		degraded = append(degraded, c.Name)
+	}
+	if want := []string{ComponentIPFS, ComponentPeers}; !reflect.DeepEqual(degraded, want) {
+		t.Fatalf("degraded = %v, want %v", degraded, want)
+	}
+
+	// One critical component makes the node critical; the degraded ones are still reported.
+	tr.Set(ComponentCapacity, SeverityCritical, "storage full")
+	s = tr.Status()
+	if s.Overall != SeverityCritical {
+		t.Fatalf("overall = %s, want critical", s.Overall)
+	}
+	if failed, want := s.Failed(), []string{ComponentCapacity}; !reflect.DeepEqual(failed, want) {
+		t.Fatalf("failed = %v, want %v", failed, want)
+	}
+	if n := len(s.Degraded()); n != 3 {
+		t.Fatalf("%d components not OK, want 3", n)
+	}
+
+	tr.OK(ComponentCapacity)
+	tr.OK(ComponentIPFS)
+	tr.OK(ComponentPeers)
+	if s := tr.Status(); s.Overall != SeverityOK || len(s.Degraded()) != 0 {
+		t.Fatalf("overall = %s with %d degraded components after recovery, want ok", s.Overall, len(s.Degraded()))
+	}
+}
+
+func TestTrackerSetReportsSeverityChanges(t *testing.T) {
+	tr := NewTracker()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tr.now = func() time.Time { return now }
+
+	if tr.OK(ComponentIPFS) {
+		t.Fatal("first OK reported as a change")
+	}
+	if !tr.Set(ComponentIPFS, SeverityDegraded, "slow") {
+		t.Fatal("ok → degraded not reported as a change")
+	}
+	since := now
+	now = now.Add(time.Minute)
+	if tr.Set(ComponentIPFS, SeverityDegraded, "still slow") {
+		t.Fatal("message change reported as a severity change")
+	}
+	c := tr.Status().Components[0]
+	if c.Message != "still slow" || !c.Since.Equal(since) {
+		t.Fatalf("component = %+v, want the new message and the original since", c)
+	}
+	if !tr.Set(ComponentIPFS, SeverityCritical, "down") {
+		t.Fatal("degraded → critical not reported as a change")
+	}
+	if !tr.OK(ComponentIPFS) {
+		t.Fatal("recovery not reported as a change")
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package health
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/wabisaby/wabisaby-node/internal/httpserver"
+)
+
+// Config configures the health server.
+type Config struct {
+	ListenAddr string              // Address to listen on, e.g. "127.0.0.1:9090"
+	Timeouts   httpserver.Timeouts // Server timeouts
+	Metrics    http.Handler        // Served at /metrics when non-nil
+	TLS        *tls.Config         // Serve HTTPS with this config when non-nil
+	Logger     *slog.Logger
+}
+
+// Server serves the node's liveness and readiness probes, and optionally its metrics.
+type Server struct {
+	cfg    Config
+	srv    *http.Server
+	status func() Status
+}
+
+// NewServer returns a health server reporting status. GET /healthz answers 200 while the process is up;
+// GET /readyz answers 200 unless a component is critical, then 503 listing the critical components under
+// "failed". Both return JSON.
+func NewServer(cfg Config, status func() Status) *Server {
+	s := &Server{cfg: cfg, status: status}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.HandleFunc("GET /readyz", s.handleReadyz)
+	if cfg.Metrics != nil {
+		mux.Handle("GET /metrics", cfg.Metrics)
+	}
+	s.srv = httpserver.New(cfg.ListenAddr, mux, cfg.Timeouts)
+	return s
+}
+
+// Start listens on the configured address and serves in the background.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.cfg.ListenAddr)
+	if err != nil {
+		return err
+	}
+	if s.cfg.TLS != nil {
+		ln = tls.NewListener(ln, s.cfg.TLS)
+	}
+	go func() {
+		if err := s.srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.cfg.Logger.Error("health server stopped", "error", err)
+		}
+	}()
+	s.cfg.Logger.Info("health server listening", "addr", ln.Addr().String(), "tls", s.cfg.TLS != nil)
+	return nil
+}
+
+// Stop gracefully shuts the server down, letting in-flight requests finish until ctx expires.
+func (s *Server) Stop(ctx context.Context) error {
+	return s.srv.Shutdown(ctx)
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "up"})
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, _ *http.Request) {
+	status := s.status()
+	body := struct {
+		Status
+		Failed []string `json:"failed,omitempty"`
+	}{Status: status, Failed: status.Failed()}
+	code := http.StatusOK
+	if len(body.Failed) > 0 {
+		code = http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, body)
+}
+
+func writeJSON(w http.ResponseWriter, code int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
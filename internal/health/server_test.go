@@ -0,0 +1,137 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package health
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/tlsconfig"
+)
+
+func TestServerRefusesTLSBelowMinVersion(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+	tlsCfg, err := tlsconfig.Build(tlsconfig.Options{MinVersion: "1.2", CertFile: certFile, KeyFile: keyFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reserve a free port for the server.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	s := NewServer(Config{
+		ListenAddr: addr,
+		TLS:        tlsCfg,
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}, func() Status { return Status{} })
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop(context.Background())
+
+	dial := func(maxVersion uint16) error {
+		dialer := &net.Dialer{Timeout: 5 * time.Second}
+		conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{
+			InsecureSkipVerify: true,
+			MinVersion:         tls.VersionTLS10,
+			MaxVersion:         maxVersion,
+		})
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+	if err := dial(tls.VersionTLS11); err == nil {
+		t.Fatal("TLS 1.1 handshake succeeded with min_version 1.2")
+	}
+	if err := dial(tls.VersionTLS12); err != nil {
+		t.Fatalf("TLS 1.2 handshake failed: %v", err)
+	}
+}
+
+func TestReadyzFailsOnlyWhenCritical(t *testing.T) {
+	tr := NewTracker()
+	tr.Set(ComponentIPFS, SeverityDegraded, "IPFS API slow")
+	tr.Set(ComponentPeers, SeverityDegraded, "1 swarm peer connected")
+	s := NewServer(Config{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))}, tr.Status)
+
+	readyz := func() (int, []string) {
+		rec := httptest.NewRecorder()
+		s.srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var body struct {
+			Failed []string `json:"failed"`
+		}
+		if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return rec.Code, body.Failed
+	}
+
+	if code, failed := readyz(); code != http.StatusOK || len(failed) != 0 {
+		t.Fatalf("degraded node: /readyz = %d failed %v, want 200", code, failed)
+	}
+	tr.Set(ComponentDisk, SeverityCritical, "read-only filesystem")
+	if code, failed := readyz(); code != http.StatusServiceUnavailable || len(failed) != 1 || failed[0] != ComponentDisk {
+		t.Fatalf("critical disk: /readyz = %d failed %v, want 503 failing disk", code, failed)
+	}
+}
+
+// writeSelfSignedCert writes a self-signed certificate for 127.0.0.1 and its key as PEM files.
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package httpserver constructs the HTTP servers the node exposes (health, metrics, admin) with
+// hardened timeouts, so no endpoint runs with http.Server's unlimited defaults.
+package httpserver
+
+import (
+	"net/http"
+	"time"
+)
+
+// Timeouts holds the server timeouts applied to every exposed endpoint.
+type Timeouts struct {
+	ReadHeader time.Duration // Max time to read request headers (slowloris protection)
+	Read       time.Duration // Max time to read the entire request, including the body
+	Write      time.Duration // Max time from the end of the request headers to the end of the response
+	Idle       time.Duration // Max time a keep-alive connection may sit idle
+}
+
+// DefaultTimeouts are safe defaults for the node's small, local endpoints.
+var DefaultTimeouts = Timeouts{
+	ReadHeader: 5 * time.Second,
+	Read:       10 * time.Second,
+	Write:      30 * time.Second,
+	Idle:       60 * time.Second,
+}
+
+// New returns an http.Server for addr and handler with the given timeouts. Zero timeouts fall back to
+// DefaultTimeouts rather than to unlimited.
+func New(addr string, handler http.Handler, t Timeouts) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: orDefault(t.ReadHeader, DefaultTimeouts.ReadHeader),
+		ReadTimeout:       orDefault(t.Read, DefaultTimeouts.Read),
+		WriteTimeout:      orDefault(t.Write, DefaultTimeouts.Write),
+		IdleTimeout:       orDefault(t.Idle, DefaultTimeouts.Idle),
+	}
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d <= 0 {
+		return def
+	}
+	return d
+}
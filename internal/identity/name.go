@@ -0,0 +1,21 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package identity
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+)
+
+// nodeNameLength is the number of base32 characters in a derived node name (50 bits of the digest).
+const nodeNameLength = 10
+
+// NodeName derives a stable node name of the form wabisaby-<base32 prefix> from seed (an identity public
+// key or an IPFS peer ID). Identical seeds give identical names; nothing about the host or user leaks.
+func NodeName(seed []byte) string {
+	sum := sha256.Sum256(seed)
+	encoded := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(sum[:])
+	return "wabisaby-" + strings.ToLower(encoded[:nodeNameLength])
+}
@@ -0,0 +1,43 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package identity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"regexp"
+	"testing"
+)
+
+var nodeNamePattern = regexp.MustCompile(`^wabisaby-[a-z2-7]{10}$`)
+
+func TestNodeNameFromPeerID(t *testing.T) {
+	peerID := []byte("12D3KooWGzBqYzBdt1Dn6XVPbDMhsxqWT4DdJc3hNFCvG9zBbcQp")
+	name := NodeName(peerID)
+	if !nodeNamePattern.MatchString(name) {
+		t.Fatalf("NodeName = %q, want wabisaby-<10 base32 chars>", name)
+	}
+	if again := NodeName(peerID); again != name {
+		t.Fatalf("NodeName not stable: %q then %q", name, again)
+	}
+	if other := NodeName([]byte("12D3KooWQYhTNQdmr3ArTeUHRYzFg94BKyTkoWBDWez9kSCVe2Xo")); other == name {
+		t.Fatalf("different peer IDs share the name %q", name)
+	}
+}
+
+func TestNodeNameFromIdentityKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewSigner(key)
+	name := NodeName(signer.PublicKey())
+	if !nodeNamePattern.MatchString(name) {
+		t.Fatalf("NodeName = %q, want wabisaby-<10 base32 chars>", name)
+	}
+	// The same key loaded again (e.g. after a restart) gives the same name.
+	if again := NodeName(NewSigner(key).PublicKey()); again != name {
+		t.Fatalf("NodeName not stable for the same key: %q then %q", name, again)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package identity
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// PinStatusClaim is the set of pin report fields covered by a signature.
+type PinStatusClaim struct {
+	NodeID      string
+	TaskID      string
+	Status      int32
+	PinnedBytes int64
+	Timestamp   int64 // Unix seconds
+}
+
+// CanonicalBytes returns the deterministic encoding that is signed: a version tag followed by each field,
+// strings as uint32 big-endian length + bytes and integers as fixed-width big-endian.
+func (c PinStatusClaim) CanonicalBytes() []byte {
+	buf := []byte("wabisaby-pin-status-v1")
+	buf = appendString(buf, c.NodeID)
+	buf = appendString(buf, c.TaskID)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(c.Status))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(c.PinnedBytes))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(c.Timestamp))
+	return buf
+}
+
+func appendString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(s)))
+	return append(buf, s...)
+}
+
+// Signer signs node reports with the node's Ed25519 identity key. A nil Signer is valid and signs nothing.
+type Signer struct {
+	key ed25519.PrivateKey
+}
+
+// LoadSigner reads a PEM-encoded PKCS#8 Ed25519 private key (as produced by
+// `openssl genpkey -algorithm ed25519`). An empty path returns a nil Signer.
+func LoadSigner(path string) (*Signer, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read identity key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("identity key %s: no PEM block found", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse identity key: %w", err)
+	}
+	key, ok := parsed.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("identity key %s: not an Ed25519 key", path)
+	}
+	return &Signer{key: key}, nil
+}
+
+// NewSigner creates a Signer from an in-memory key.
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{key: key}
+}
+
+// PublicKey returns the public half of the identity key, or nil for a nil Signer.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	if s == nil {
+		return nil
+	}
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// SignPinStatus signs the canonical encoding of claim. It returns nil when no key is configured.
+func (s *Signer) SignPinStatus(claim PinStatusClaim) []byte {
+	if s == nil {
+		return nil
+	}
+	return ed25519.Sign(s.key, claim.CanonicalBytes())
+}
+
+// VerifyPinStatus reports whether sig is a valid signature of claim by pub.
+func VerifyPinStatus(pub ed25519.PublicKey, claim PinStatusClaim, sig []byte) bool {
+	return ed25519.Verify(pub, claim.CanonicalBytes(), sig)
+}
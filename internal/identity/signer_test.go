@@ -0,0 +1,73 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package identity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSignPinStatusVerifies(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := NewSigner(key)
+	claim := PinStatusClaim{NodeID: "node-1", TaskID: "task-1", Status: 1, PinnedBytes: 4096, Timestamp: 1700000000}
+
+	sig := signer.SignPinStatus(claim)
+	if !ed25519.Verify(signer.PublicKey(), claim.CanonicalBytes(), sig) {
+		t.Fatal("signature does not verify against the signer's public key")
+	}
+	if !VerifyPinStatus(signer.PublicKey(), claim, sig) {
+		t.Fatal("VerifyPinStatus rejected a valid signature")
+	}
+
+	tampered := claim
+	tampered.PinnedBytes = 8192
+	if VerifyPinStatus(signer.PublicKey(), tampered, sig) {
+		t.Fatal("signature verifies for a claim with different pinned bytes")
+	}
+}
+
+func TestNilSignerSignsNothing(t *testing.T) {
+	var signer *Signer
+	if sig := signer.SignPinStatus(PinStatusClaim{TaskID: "task-1"}); sig != nil {
+		t.Fatalf("nil signer returned signature %x", sig)
+	}
+	if pub := signer.PublicKey(); pub != nil {
+		t.Fatalf("nil signer returned public key %x", pub)
+	}
+}
+
+func TestLoadSigner(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "identity.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := LoadSigner(path)
+	if err != nil {
+		t.Fatalf("LoadSigner: %v", err)
+	}
+	if !pub.Equal(signer.PublicKey()) {
+		t.Fatal("loaded signer has a different public key")
+	}
+	if signer, err := LoadSigner(""); signer != nil || err != nil {
+		t.Fatalf("LoadSigner(\"\") = %v, %v; want nil, nil", signer, err)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package inventory keeps a persistent record of the content pinned by this node, including when it
+// was pinned and when (if ever) its retention expires, and of repeated pin failures per CID.
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/fsutil"
+)
+
+// Record describes a single pinned CID.
+type Record struct {
+	CID       string    `json:"cid"`
+	TaskID    string    `json:"task_id"`              // Pin task that created the record
+	PinnedAt  time.Time `json:"pinned_at"`            // When the pin completed
+	ExpiresAt time.Time `json:"expires_at,omitzero"`  // Retention deadline; zero means the pin never expires
+	SizeBytes int64     `json:"size_bytes,omitempty"` // Total size of the pinned DAG; 0 if unknown
+	Name      string    `json:"name,omitempty"`       // Name of the IPFS pin; empty for unnamed pins
+	seenAt    time.Time // When a pin scan last found the CID pinned (see MarkSeen); not persisted
+}
+
+// Expired reports whether the record's retention has elapsed at now.
+func (r Record) Expired(now time.Time) bool {
+	return !r.ExpiresAt.IsZero() && !now.Before(r.ExpiresAt)
+}
+
+// maxFailureHistory is the number of most recent failure messages kept per CID.
+const maxFailureHistory = 5
+
+// Failures tracks consecutive failed pin attempts for a CID.
+type Failures struct {
+	CID         string    `json:"cid"`
+	Attempts    int       `json:"attempts"`
+	LastAttempt time.Time `json:"last_attempt"`
+	History     []string  `json:"history"` // Most recent failure messages, oldest first
+}
+
+// fileFormat is the on-disk representation of the inventory.
+type fileFormat struct {
+	Pins     []Record   `json:"pins"`
+	Failures []Failures `json:"failures,omitempty"`
+}
+
+// Inventory is a concurrency-safe set of pin records keyed by CID. When backed by a file, every change
+// is written through atomically so the inventory survives restarts.
+type Inventory struct {
+	path     string
+	mu       sync.Mutex
+	records  map[string]Record
+	failures map[string]Failures
+}
+
+// Open loads the inventory stored at path. A missing file yields an empty inventory; an empty path
+// yields an in-memory inventory that is never persisted.
+func Open(path string) (*Inventory, error) {
+	inv := &Inventory{path: path, records: make(map[string]Record), failures: make(map[string]Failures)}
+	if path == "" {
+		return inv, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return inv, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read inventory: %w", err)
+	}
+	var file fileFormat
+	if err := json.Unmarshal(data, &file); err != nil {
+		// Earlier versions stored only the pin records as a bare array.
+		if arrErr := json.Unmarshal(data, &file.Pins); arrErr != nil {
+			return nil, fmt.Errorf("parse inventory %s: %w", path, err)
+		}
+	}
+	for _, rec := range file.Pins {
+		inv.records[rec.CID] = rec
+	}
+	for _, f := range file.Failures {
+		inv.failures[f.CID] = f
+	}
+	return inv, nil
+}
+
+// Add records rec, replacing any existing record for the same CID, and persists the inventory.
+func (inv *Inventory) Add(rec Record) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	inv.records[rec.CID] = rec
+	return inv.saveLocked()
+}
+
+// Remove deletes the record for cid, if any, and persists the inventory.
+func (inv *Inventory) Remove(cid string) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if _, ok := inv.records[cid]; !ok {
+		return nil
+	}
+	delete(inv.records, cid)
+	return inv.saveLocked()
+}
+
+// Contains reports whether cid has a pin record.
+func (inv *Inventory) Contains(cid string) bool {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	_, ok := inv.records[cid]
+	return ok
+}
+
+// Get returns the pin record for cid, if any.
+func (inv *Inventory) Get(cid string) (Record, bool) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	rec, ok := inv.records[cid]
+	return rec, ok
+}
+
+// Newest returns the most recently pinned record, if any.
+func (inv *Inventory) Newest() (Record, bool) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	var newest Record
+	found := false
+	for _, rec := range inv.records {
+		if !found || rec.PinnedAt.After(newest.PinnedAt) {
+			newest, found = rec, true
+		}
+	}
+	return newest, found
+}
+
+// Records returns a copy of all pin records.
+func (inv *Inventory) Records() []Record {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	records := make([]Record, 0, len(inv.records))
+	for _, rec := range inv.records {
+		records = append(records, rec)
+	}
+	return records
+}
+
+// MarkSeen notes that a pin scan found cid pinned at the given time and reports whether cid has a record.
+// Marks are kept in memory only.
+func (inv *Inventory) MarkSeen(cid string, at time.Time) bool {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	rec, ok := inv.records[cid]
+	if ok {
+		rec.seenAt = at
+		inv.records[cid] = rec
+	}
+	return ok
+}
+
+// Unseen returns the records pinned before since that no pin scan has marked seen (see MarkSeen) since
+// then, i.e. the pins a scan started at since found missing.
+func (inv *Inventory) Unseen(since time.Time) []Record {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	var unseen []Record
+	for _, rec := range inv.records {
+		if rec.PinnedAt.Before(since) && rec.seenAt.Before(since) {
+			unseen = append(unseen, rec)
+		}
+	}
+	return unseen
+}
+
+// Named returns the records whose pin name starts with prefix, sorted by CID. An empty prefix matches
+// every named record.
+func (inv *Inventory) Named(prefix string) []Record {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	var named []Record
+	for _, rec := range inv.records {
+		if rec.Name != "" && strings.HasPrefix(rec.Name, prefix) {
+			named = append(named, rec)
+		}
+	}
+	sort.Slice(named, func(i, j int) bool { return named[i].CID < named[j].CID })
+	return named
+}
+
+// Expired returns the records whose retention has elapsed at now, oldest deadline first.
+func (inv *Inventory) Expired(now time.Time) []Record {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	var expired []Record
+	for _, rec := range inv.records {
+		if rec.Expired(now) {
+			expired = append(expired, rec)
+		}
+	}
+	sort.Slice(expired, func(i, j int) bool { return expired[i].ExpiresAt.Before(expired[j].ExpiresAt) })
+	return expired
+}
+
+// RecordFailure counts a failed pin attempt for cid with its error message and returns the updated
+// failure state.
+func (inv *Inventory) RecordFailure(cid, message string, at time.Time) (Failures, error) {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	f := inv.failures[cid]
+	f.CID = cid
+	f.Attempts++
+	f.LastAttempt = at
+	f.History = append(f.History, message)
+	if len(f.History) > maxFailureHistory {
+		f.History = append([]string(nil), f.History[len(f.History)-maxFailureHistory:]...)
+	}
+	inv.failures[cid] = f
+	return f, inv.saveLocked()
+}
+
+// FailuresFor returns the failure state for cid; Attempts is zero if no failure is recorded.
+func (inv *Inventory) FailuresFor(cid string) Failures {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	return inv.failures[cid]
+}
+
+// ClearFailures resets the failure state for cid, e.g. after a successful pin.
+func (inv *Inventory) ClearFailures(cid string) error {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	if _, ok := inv.failures[cid]; !ok {
+		return nil
+	}
+	delete(inv.failures, cid)
+	return inv.saveLocked()
+}
+
+// Len returns the number of pinned CIDs in the inventory.
+func (inv *Inventory) Len() int {
+	inv.mu.Lock()
+	defer inv.mu.Unlock()
+	return len(inv.records)
+}
+
+// saveLocked writes the inventory to its file. inv.mu must be held.
+func (inv *Inventory) saveLocked() error {
+	if inv.path == "" {
+		return nil
+	}
+	file := fileFormat{Pins: make([]Record, 0, len(inv.records))}
+	for _, rec := range inv.records {
+		file.Pins = append(file.Pins, rec)
+	}
+	sort.Slice(file.Pins, func(i, j int) bool { return file.Pins[i].CID < file.Pins[j].CID })
+	for _, f := range inv.failures {
+		file.Failures = append(file.Failures, f)
+	}
+	sort.Slice(file.Failures, func(i, j int) bool { return file.Failures[i].CID < file.Failures[j].CID })
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode inventory: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(inv.path), 0o755); err != nil {
+		return fmt.Errorf("create inventory directory: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(inv.path, data, 0o644); err != nil {
+		return fmt.Errorf("write inventory: %w", err)
+	}
+	return nil
+}
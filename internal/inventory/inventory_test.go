@@ -0,0 +1,105 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenTruncatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	inv, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inv.Add(Record{CID: "bafy1", TaskID: "task-1", PinnedAt: time.Now(), SizeBytes: 1024}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[:len(data)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path); err == nil {
+		t.Fatal("Open accepted a truncated inventory file")
+	}
+}
+
+func TestOpenRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "inventory.json")
+	inv, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inv.Add(Record{CID: "bafy1", TaskID: "task-1", PinnedAt: time.Now(), SizeBytes: 1024}); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, ok := reopened.Get("bafy1")
+	if !ok || rec.SizeBytes != 1024 {
+		t.Fatalf("Get(bafy1) = %+v, %v; want the saved record", rec, ok)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	inv, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec := range []Record{
+		{CID: "bafy3", Name: "wabisaby-task-3"},
+		{CID: "bafy1", Name: "wabisaby-task-1"},
+		{CID: "bafy2", Name: "archive"},
+		{CID: "bafy4"},
+	} {
+		if err := inv.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := inv.Named("wabisaby-task-")
+	if len(got) != 2 || got[0].CID != "bafy1" || got[1].CID != "bafy3" {
+		t.Fatalf("Named(prefix) = %+v, want bafy1 and bafy3", got)
+	}
+	if got := inv.Named(""); len(got) != 3 {
+		t.Fatalf("Named(\"\") returned %d records, want the 3 named ones", len(got))
+	}
+}
+
+func TestUnseen(t *testing.T) {
+	inv, err := Open("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scan := time.Now()
+	for _, rec := range []Record{
+		{CID: "bafy-seen", PinnedAt: scan.Add(-time.Hour)},
+		{CID: "bafy-missing", PinnedAt: scan.Add(-time.Hour)},
+		{CID: "bafy-new", PinnedAt: scan.Add(time.Second)}, // Pinned while the scan ran
+	} {
+		if err := inv.Add(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !inv.MarkSeen("bafy-seen", scan) || inv.MarkSeen("bafy-unknown", scan) {
+		t.Fatal("MarkSeen did not report which CIDs have records")
+	}
+	if got := inv.Unseen(scan); len(got) != 1 || got[0].CID != "bafy-missing" {
+		t.Fatalf("Unseen = %+v, want only bafy-missing", got)
+	}
+	if got := inv.Unseen(scan.Add(time.Minute)); len(got) != 3 {
+		t.Fatalf("Unseen after a later scan = %+v, want all 3 records", got)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	neturl "net/url"
+)
+
+// ErrGatewayURL is returned (wrapped) when the configured API URL serves the IPFS HTTP gateway rather
+// than the RPC API, typically because ipfs.api_url points at port 8080 instead of 5001.
+var ErrGatewayURL = errors.New("URL serves the IPFS gateway, not the RPC API")
+
+// probeCID is the empty identity CID. Its content is inlined in the CID, so any gateway serves it
+// without touching the network.
+const probeCID = "bafkqaaa"
+
+// defaultAPIPort is the port Kubo's RPC API listens on by default.
+const defaultAPIPort = "5001"
+
+// CheckAPI verifies that the client's URL serves the IPFS RPC API by calling /api/v0/version. If it does
+// not, and the URL answers like an IPFS gateway instead, the returned error wraps ErrGatewayURL and
+// suggests the likely API URL. Other failures are returned as from Version.
+func (c *Client) CheckAPI(ctx context.Context) error {
+	_, err := c.Version(ctx)
+	if err == nil || errors.Is(err, ErrUnauthorized) {
+		return err
+	}
+	if !c.servesGateway(ctx) {
+		return err
+	}
+	return gatewayURLError(c.apiURL)
+}
+
+// gatewayURLError returns the ErrGatewayURL error for apiURL, including the suggested API URL.
+func gatewayURLError(apiURL string) error {
+	return fmt.Errorf("%s: %w; set ipfs.api_url to the RPC API, likely %s", apiURL, ErrGatewayURL, suggestAPIURL(apiURL))
+}
+
+// servesGateway reports whether the client's URL resolves an /ipfs/ path the way a gateway does. The RPC
+// API has no /ipfs/ route and answers 404.
+func (c *Client) servesGateway(ctx context.Context) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+"/ipfs/"+probeCID, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	return resp.StatusCode == http.StatusOK || resp.Header.Get("X-Ipfs-Path") != ""
+}
+
+// suggestAPIURL returns apiURL with its port replaced by the default RPC API port.
+func suggestAPIURL(apiURL string) string {
+	u, err := neturl.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return "http://127.0.0.1:" + defaultAPIPort
+	}
+	u.Host = net.JoinHostPort(u.Hostname(), defaultAPIPort)
+	u.Path = ""
+	return u.String()
+}
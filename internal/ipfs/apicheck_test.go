@@ -0,0 +1,79 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckAPI(t *testing.T) {
+	api := http.NewServeMux()
+	api.HandleFunc("POST /api/v0/version", func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{"Version":"0.32.1"}`)
+	})
+
+	// Kubo's gateway has no RPC routes and serves /ipfs/ paths with an X-Ipfs-Path header.
+	gateway := http.NewServeMux()
+	gateway.HandleFunc("/api/", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "404 page not found", http.StatusNotFound)
+	})
+	gateway.HandleFunc("GET /ipfs/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Ipfs-Path", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	})
+
+	other := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	})
+
+	tests := []struct {
+		name        string
+		handler     http.Handler
+		wantErr     bool
+		wantGateway bool
+	}{
+		{"rpc api", api, false, false},
+		{"gateway", gateway, true, true},
+		{"other server", other, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+
+			err := NewClient(srv.URL).CheckAPI(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckAPI = %v, want error %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrGatewayURL) != tt.wantGateway {
+				t.Fatalf("CheckAPI = %v, want ErrGatewayURL %v", err, tt.wantGateway)
+			}
+			if tt.wantGateway && !strings.Contains(err.Error(), ":5001") {
+				t.Errorf("CheckAPI = %v, want a suggested URL on port 5001", err)
+			}
+		})
+	}
+}
+
+func TestSuggestAPIURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"http://127.0.0.1:8080", "http://127.0.0.1:5001"},
+		{"http://ipfs.internal:8080/", "http://ipfs.internal:5001"},
+		{"https://[::1]:8080", "https://[::1]:5001"},
+		{"not a url", "http://127.0.0.1:5001"},
+	}
+	for _, tt := range tests {
+		if got := suggestAPIURL(tt.in); got != tt.want {
+			t.Errorf("suggestAPIURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned (wrapped) when a call is short-circuited because its endpoint's circuit
+// breaker is open. The daemon was not contacted, so the call can be retried once the breaker closes.
+var ErrCircuitOpen = errors.New("IPFS API circuit breaker open")
+
+// Circuit breaker states, as reported by BreakerStates and to the state change callback.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// BreakerConfig configures the per-endpoint circuit breaker.
+type BreakerConfig struct {
+	FailureThreshold int           // Consecutive failures that open an endpoint's breaker
+	Cooldown         time.Duration // How long an open breaker short-circuits calls before letting a probe through
+	// OnStateChange, if set, is called with the endpoint (e.g. "pin/add") and its new state on every
+	// transition. It runs with the breaker's lock held and must not call back into the client.
+	OnStateChange func(endpoint, state string)
+}
+
+// WithCircuitBreaker gives each IPFS API endpoint a circuit breaker. After cfg.FailureThreshold
+// consecutive failures (transport errors, 502/503/504, or other 5xx responses that are not IPFS API
+// errors) the endpoint's calls fail immediately with ErrCircuitOpen for cfg.Cooldown; a single probe is
+// then let through, closing the breaker on success and reopening it on failure. A threshold below 1
+// disables the breaker.
+func WithCircuitBreaker(cfg BreakerConfig) ClientOption {
+	return func(c *Client) {
+		if cfg.FailureThreshold < 1 {
+			return
+		}
+		next := c.httpClient.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		c.breaker = &breakerTransport{next: next, cfg: cfg, now: time.Now, endpoints: make(map[string]*breaker)}
+		c.httpClient.Transport = c.breaker
+	}
+}
+
+// CircuitOpen reports whether calls to endpoint (e.g. "pin/add") are currently being short-circuited.
+// A half-open breaker counts as open while its probe is in flight.
+func (c *Client) CircuitOpen(endpoint string) bool {
+	if c.breaker == nil {
+		return false
+	}
+	return !c.breaker.wouldAllow(endpoint)
+}
+
+// BreakerStates returns the state of every endpoint's circuit breaker that has seen a call. It is nil
+// when the breaker is disabled.
+func (c *Client) BreakerStates() map[string]string {
+	if c.breaker == nil {
+		return nil
+	}
+	return c.breaker.states()
+}
+
+// breaker is the state of a single endpoint's circuit breaker.
+type breaker struct {
+	state    string
+	failures int       // Consecutive failures while closed
+	openedAt time.Time // When the breaker last opened
+	probing  bool      // A half-open probe is in flight
+}
+
+// breakerTransport tracks a circuit breaker per IPFS API endpoint and short-circuits requests to
+// endpoints whose breaker is open.
+type breakerTransport struct {
+	next http.RoundTripper
+	cfg  BreakerConfig
+	now  func() time.Time
+
+	mu        sync.Mutex
+	endpoints map[string]*breaker
+}
+
+// RoundTrip performs the request if the endpoint's breaker allows it and records the outcome.
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := endpointOf(req)
+	if !t.allow(endpoint) {
+		return nil, fmt.Errorf("%s: %w", endpoint, ErrCircuitOpen)
+	}
+	resp, err := t.next.RoundTrip(req)
+	switch {
+	case err != nil && req.Context().Err() != nil:
+		// The caller gave up; that says nothing about the daemon's health.
+		t.record(endpoint, context.Canceled)
+	case err != nil:
+		t.record(endpoint, err)
+	case daemonFailure(resp):
+		t.record(endpoint, fmt.Errorf("status %d", resp.StatusCode))
+	default:
+		t.record(endpoint, nil)
+	}
+	return resp, err
+}
+
+// maxAPIErrorBody bounds how much of a 5xx response body daemonFailure reads to classify it.
+const maxAPIErrorBody = 64 << 10
+
+// daemonFailure reports whether resp says the daemon itself is unhealthy: a 502, 503 or 504, or any other
+// 5xx whose body is not a kubo API error. Kubo answers request-level errors such as "not pinned" with a
+// 500 and a {"Message","Code","Type":"error"} body; those say nothing about the daemon's health. The
+// part of the body read to decide is put back so the caller still sees all of it.
+func daemonFailure(resp *http.Response) bool {
+	switch {
+	case resp.StatusCode < http.StatusInternalServerError:
+		return false
+	case resp.StatusCode == http.StatusBadGateway, resp.StatusCode == http.StatusServiceUnavailable,
+		resp.StatusCode == http.StatusGatewayTimeout:
+		return true
+	}
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, maxAPIErrorBody))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+	var apiErr apiError
+	return json.Unmarshal(head, &apiErr) != nil || apiErr.Type != "error" || apiErr.Message == ""
+}
+
+// endpointOf returns the API command of req, e.g. "pin/add" for /api/v0/pin/add.
+func endpointOf(req *http.Request) string {
+	return strings.TrimPrefix(req.URL.Path, "/api/v0/")
+}
+
+// get returns endpoint's breaker, creating a closed one if needed. t.mu must be held.
+func (t *breakerTransport) get(endpoint string) *breaker {
+	b, ok := t.endpoints[endpoint]
+	if !ok {
+		b = &breaker{state: BreakerClosed}
+		t.endpoints[endpoint] = b
+	}
+	return b
+}
+
+// allow reports whether a request to endpoint may proceed, moving an open breaker whose cooldown has
+// elapsed to half-open and admitting the request as its probe.
+func (t *breakerTransport) allow(endpoint string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.get(endpoint)
+	switch b.state {
+	case BreakerOpen:
+		if t.now().Sub(b.openedAt) < t.cfg.Cooldown {
+			return false
+		}
+		t.transition(endpoint, b, BreakerHalfOpen)
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// wouldAllow is allow without side effects.
+func (t *breakerTransport) wouldAllow(endpoint string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.endpoints[endpoint]
+	if !ok {
+		return true
+	}
+	switch b.state {
+	case BreakerOpen:
+		return t.now().Sub(b.openedAt) >= t.cfg.Cooldown
+	case BreakerHalfOpen:
+		return !b.probing
+	default:
+		return true
+	}
+}
+
+// record updates endpoint's breaker with the outcome of a request it allowed. context.Canceled is
+// neutral: it releases a half-open probe without counting as a success or failure.
+func (t *breakerTransport) record(endpoint string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b := t.get(endpoint)
+	b.probing = false
+	switch {
+	case errors.Is(err, context.Canceled):
+	case err == nil:
+		b.failures = 0
+		if b.state != BreakerClosed {
+			t.transition(endpoint, b, BreakerClosed)
+		}
+	case b.state == BreakerHalfOpen:
+		b.openedAt = t.now()
+		t.transition(endpoint, b, BreakerOpen)
+	default:
+		b.failures++
+		if b.state == BreakerClosed && b.failures >= t.cfg.FailureThreshold {
+			b.openedAt = t.now()
+			t.transition(endpoint, b, BreakerOpen)
+		}
+	}
+}
+
+// transition moves b to state and notifies the callback. t.mu must be held.
+func (t *breakerTransport) transition(endpoint string, b *breaker, state string) {
+	b.state = state
+	if state == BreakerOpen {
+		b.failures = 0
+	}
+	if t.cfg.OnStateChange != nil {
+		t.cfg.OnStateChange(endpoint, state)
+	}
+}
+
+// states returns a copy of every endpoint's breaker state.
+func (t *breakerTransport) states() map[string]string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	states := make(map[string]string, len(t.endpoints))
+	for endpoint, b := range t.endpoints {
+		states[endpoint] = b.state
+	}
+	return states
+}
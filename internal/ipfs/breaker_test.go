@@ -0,0 +1,158 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	var failing atomic.Bool
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			http.Error(w, "overloaded", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"Version":"0.32.1"}`)
+	}))
+	defer srv.Close()
+
+	var transitions []string
+	c := NewClient(srv.URL, WithCircuitBreaker(BreakerConfig{
+		FailureThreshold: 2,
+		Cooldown:         time.Minute,
+		OnStateChange:    func(endpoint, state string) { transitions = append(transitions, endpoint+" "+state) },
+	}))
+	now := time.Now()
+	c.breaker.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	// Closed → open after FailureThreshold consecutive failures.
+	failing.Store(true)
+	for range 2 {
+		if _, err := c.Version(ctx); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Version = %v, want a daemon error", err)
+		}
+	}
+	if !c.CircuitOpen("version") {
+		t.Fatal("breaker not open after the failure threshold")
+	}
+
+	// Open: calls are short-circuited without contacting the daemon.
+	before := calls.Load()
+	if _, err := c.Version(ctx); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Version while open = %v, want ErrCircuitOpen", err)
+	}
+	if calls.Load() != before {
+		t.Fatal("open breaker let a call through")
+	}
+
+	// Half-open after the cooldown: a failed probe reopens the breaker.
+	now = now.Add(time.Minute)
+	if c.CircuitOpen("version") {
+		t.Fatal("breaker still open after the cooldown")
+	}
+	if _, err := c.Version(ctx); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("failed probe = %v, want a daemon error", err)
+	}
+	if !c.CircuitOpen("version") {
+		t.Fatal("breaker not reopened by a failed probe")
+	}
+
+	// Half-open → closed by a successful probe.
+	now = now.Add(time.Minute)
+	failing.Store(false)
+	if _, err := c.Version(ctx); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if got := c.BreakerStates()["version"]; got != BreakerClosed {
+		t.Fatalf("state after a successful probe = %s, want %s", got, BreakerClosed)
+	}
+
+	want := []string{
+		"version open",
+		"version half_open", "version open",
+		"version half_open", "version closed",
+	}
+	if !reflect.DeepEqual(transitions, want) {
+		t.Fatalf("transitions = %v, want %v", transitions, want)
+	}
+}
+
+func TestCircuitBreakerPerEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v0/repo/stat" {
+			http.Error(w, "overloaded", http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprint(w, `{"Version":"0.32.1"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, WithCircuitBreaker(BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute}))
+	if _, err := c.RepoStat(context.Background()); err == nil {
+		t.Fatal("RepoStat succeeded against a failing endpoint")
+	}
+	if !c.CircuitOpen("repo/stat") {
+		t.Fatal("repo/stat breaker not open")
+	}
+	if c.CircuitOpen("version") {
+		t.Fatal("a repo/stat failure opened the version breaker")
+	}
+	if _, err := c.Version(context.Background()); err != nil {
+		t.Fatalf("Version: %v", err)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	c := NewClient("http://127.0.0.1:1", WithCircuitBreaker(BreakerConfig{FailureThreshold: 0}))
+	if c.breaker != nil || c.BreakerStates() != nil || c.CircuitOpen("pin/add") {
+		t.Fatal("a threshold below 1 did not disable the breaker")
+	}
+}
+
+func TestCircuitBreakerIgnoresAPIErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"Message":"not pinned or pinned indirectly","Code":0,"Type":"error"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, WithCircuitBreaker(BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute}))
+	for range 3 {
+		if err := c.Unpin(context.Background(), "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"); err != nil {
+			t.Fatalf("Unpin: %v", err)
+		}
+	}
+	if c.CircuitOpen("pin/rm") {
+		t.Fatal("a 500 \"not pinned\" API error opened the breaker")
+	}
+}
+
+func TestCircuitBreakerCountsGatewayErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprint(w, `{"Message":"daemon busy","Code":0,"Type":"error"}`)
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, WithCircuitBreaker(BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute}))
+	if _, err := c.Version(context.Background()); err == nil {
+		t.Fatal("Version succeeded against a 503")
+	}
+	if !c.CircuitOpen("version") {
+		t.Fatal("a 503 did not open the breaker")
+	}
+}
@@ -6,27 +6,103 @@ package ipfs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"net/http"
+	neturl "net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
 // Client provides an interface to the IPFS HTTP API.
-// It contains only the methods required by the storage node (Version, ID, RepoStat, Pin).
+// It contains only the methods required by the storage node.
 type Client struct {
 	apiURL     string
 	httpClient *http.Client
+	breaker    *breakerTransport // Per-endpoint circuit breakers; nil when disabled
+
+	noPinNames atomic.Bool // The daemon rejected the pin/add name option
+}
+
+// ClientOption configures optional Client behavior.
+type ClientOption func(*Client)
+
+// WithRequestTracing logs every API request at debug level (method, URL, status, duration) to logger.
+// With redactArgs, "arg" query values such as CIDs are redacted from the logged URL.
+func WithRequestTracing(logger *slog.Logger, redactArgs bool) ClientOption {
+	return func(c *Client) {
+		next := c.httpClient.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		c.httpClient.Transport = &tracingTransport{next: next, logger: logger, redactArgs: redactArgs}
+	}
 }
 
 // NewClient creates a new IPFS HTTP API client.
-func NewClient(apiURL string) *Client {
-	return &Client{
+func NewClient(apiURL string, opts ...ClientOption) *Client {
+	c := &Client{
 		apiURL: apiURL,
 		httpClient: &http.Client{
 			Timeout: 5 * time.Minute,
 		},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// ErrUnauthorized is returned (wrapped) when the IPFS API rejects the request's credentials with 401 or 403.
+// It is not retryable: the configuration must be fixed.
+var ErrUnauthorized = errors.New("IPFS API rejected credentials")
+
+// ErrAlreadyPinned is returned (wrapped) when the IPFS API rejects a pin because the CID is already
+// pinned. Some kubo versions answer this way instead of succeeding; the content is present.
+var ErrAlreadyPinned = errors.New("IPFS content already pinned")
+
+// ErrNotPinned is returned (wrapped) when the IPFS API rejects an unpin because the CID is not pinned.
+var ErrNotPinned = errors.New("IPFS content not pinned")
+
+// ErrInvalidCID is returned (wrapped) when the IPFS API rejects a request because its CID or path cannot
+// be parsed. It is not retryable: the same request fails again.
+var ErrInvalidCID = errors.New("invalid CID")
+
+// apiError is the JSON error body of the IPFS RPC API.
+type apiError struct {
+	Message string `json:"Message"`
+	Code    int    `json:"Code"`
+	Type    string `json:"Type"`
+}
+
+// statusError builds the error for a non-200 IPFS API response, wrapping ErrUnauthorized for 401/403,
+// ErrAlreadyPinned when the API reports the CID as already pinned and ErrNotPinned when it reports it as
+// not pinned. The API's error message is used when the body parses as an API error, the raw body otherwise.
+func statusError(op string, resp *http.Response) error {
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("IPFS %s failed with status %d: %w", op, resp.StatusCode, ErrUnauthorized)
+	}
+	msg := string(bodyBytes)
+	var apiErr apiError
+	if json.Unmarshal(bodyBytes, &apiErr) == nil && apiErr.Message != "" {
+		msg = apiErr.Message
+	}
+	if strings.Contains(msg, "already pinned") {
+		return fmt.Errorf("IPFS %s failed with status %d: %s: %w", op, resp.StatusCode, msg, ErrAlreadyPinned)
+	}
+	if strings.Contains(msg, "not pinned") {
+		return fmt.Errorf("IPFS %s failed with status %d: %s: %w", op, resp.StatusCode, msg, ErrNotPinned)
+	}
+	if strings.Contains(msg, "invalid cid") || strings.Contains(msg, "invalid path") {
+		return fmt.Errorf("IPFS %s failed with status %d: %s: %w", op, resp.StatusCode, msg, ErrInvalidCID)
+	}
+	return fmt.Errorf("IPFS %s failed with status %d: %s", op, resp.StatusCode, msg)
 }
 
 // RepoStatResult holds IPFS repository statistics returned from /repo/stat.
@@ -35,27 +111,35 @@ type RepoStatResult struct {
 	StorageMax uint64 `json:"StorageMax"`
 }
 
-// Pin pins a CID to the local IPFS node.
+// Pin pins a CID to the local IPFS node. An "already pinned" answer from the API counts as success.
 func (c *Client) Pin(ctx context.Context, cid string) error {
+	return c.pinAdd(ctx, cid, "")
+}
+
+// pinAdd calls pin/add for cid, naming the pin when name is non-empty. Fetching large content can take
+// far longer than other API calls, so the client-wide timeout does not apply: the pin is bounded by ctx.
+func (c *Client) pinAdd(ctx context.Context, cid, name string) error {
 	url := fmt.Sprintf("%s/api/v0/pin/add?arg=%s", c.apiURL, cid)
+	if name != "" {
+		url += "&name=" + neturl.QueryEscape(name)
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	client := *c.httpClient
+	client.Timeout = 0
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, err := io.ReadAll(resp.Body)
-		bodyStr := ""
-		if err == nil {
-			bodyStr = string(bodyBytes)
+		if err := statusError("pin", resp); !errors.Is(err, ErrAlreadyPinned) {
+			return err
 		}
-		return fmt.Errorf("IPFS pin failed with status %d: %s", resp.StatusCode, bodyStr)
 	}
 
 	return nil
@@ -76,8 +160,7 @@ func (c *Client) ID(ctx context.Context) (string, []string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", nil, fmt.Errorf("IPFS id failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return "", nil, statusError("id", resp)
 	}
 
 	var result struct {
@@ -106,8 +189,7 @@ func (c *Client) Version(ctx context.Context) (string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("IPFS version failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return "", statusError("version", resp)
 	}
 
 	var result struct {
@@ -135,8 +217,7 @@ func (c *Client) RepoStat(ctx context.Context) (*RepoStatResult, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("IPFS repo stat failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+		return nil, statusError("repo stat", resp)
 	}
 
 	var result RepoStatResult
@@ -146,3 +227,420 @@ func (c *Client) RepoStat(ctx context.Context) (*RepoStatResult, error) {
 
 	return &result, nil
 }
+
+// DagSize returns the total size in bytes of the DAG rooted at cid, using dag/stat. The DAG must be
+// available locally or it is fetched, so call it only for pinned content.
+func (c *Client) DagSize(ctx context.Context, cid string) (int64, error) {
+	url := fmt.Sprintf("%s/api/v0/dag/stat?arg=%s&progress=false", c.apiURL, neturl.QueryEscape(cid))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, statusError("dag stat", resp)
+	}
+
+	// Newer kubo versions report TotalSize; older ones Size.
+	var result struct {
+		TotalSize int64 `json:"TotalSize"`
+		Size      int64 `json:"Size"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if result.TotalSize > 0 {
+		return result.TotalSize, nil
+	}
+	return result.Size, nil
+}
+
+// SwarmPeerCount returns the number of peers the local IPFS node is currently connected to.
+func (c *Client) SwarmPeerCount(ctx context.Context) (int, error) {
+	url := fmt.Sprintf("%s/api/v0/swarm/peers", c.apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, statusError("swarm peers", resp)
+	}
+
+	var result struct {
+		Peers []json.RawMessage `json:"Peers"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return len(result.Peers), nil
+}
+
+// BitswapStatResult holds the node-wide bitswap counters returned from /stats/bitswap.
+type BitswapStatResult struct {
+	BlocksReceived uint64
+	BlocksSent     uint64
+	DataReceived   uint64
+	DataSent       uint64
+}
+
+// BitswapStat queries the local IPFS node for its bitswap counters.
+func (c *Client) BitswapStat(ctx context.Context) (*BitswapStatResult, error) {
+	fields, err := c.statFields(ctx, "stats/bitswap", "bitswap stat")
+	if err != nil {
+		return nil, err
+	}
+	return &BitswapStatResult{
+		BlocksReceived: fields.uint("BlocksReceived", "Blocks_Received"),
+		BlocksSent:     fields.uint("BlocksSent", "Blocks_Sent"),
+		DataReceived:   fields.uint("DataReceived", "Data_Received"),
+		DataSent:       fields.uint("DataSent", "Data_Sent"),
+	}, nil
+}
+
+// BandwidthStatResult holds the node's total transferred bytes and current transfer rates (bytes per
+// second) returned from /stats/bw.
+type BandwidthStatResult struct {
+	TotalIn  uint64
+	TotalOut uint64
+	RateIn   float64
+	RateOut  float64
+}
+
+// BandwidthStat queries the local IPFS node for its bandwidth counters.
+func (c *Client) BandwidthStat(ctx context.Context) (*BandwidthStatResult, error) {
+	fields, err := c.statFields(ctx, "stats/bw", "bandwidth stat")
+	if err != nil {
+		return nil, err
+	}
+	return &BandwidthStatResult{
+		TotalIn:  fields.uint("TotalIn", "Total_In"),
+		TotalOut: fields.uint("TotalOut", "Total_Out"),
+		RateIn:   fields.float("RateIn", "Rate_In"),
+		RateOut:  fields.float("RateOut", "Rate_Out"),
+	}, nil
+}
+
+// statFields calls the stats endpoint at path and returns the top-level fields of its JSON response.
+func (c *Client) statFields(ctx context.Context, path, op string) (statFields, error) {
+	url := fmt.Sprintf("%s/api/v0/%s", c.apiURL, path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(op, resp)
+	}
+
+	var fields statFields
+	if err := json.NewDecoder(resp.Body).Decode(&fields); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return fields, nil
+}
+
+// statFields are the fields of a stats response. Lookups accept alternative names and ignore case, and a
+// field that is missing or not a number reads as zero, so a kubo release that renames or drops a counter
+// degrades that counter instead of failing the whole call.
+type statFields map[string]json.RawMessage
+
+func (f statFields) float(names ...string) float64 {
+	for _, name := range names {
+		for key, raw := range f {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+			var v float64
+			if json.Unmarshal(raw, &v) == nil {
+				return v
+			}
+		}
+	}
+	return 0
+}
+
+func (f statFields) uint(names ...string) uint64 {
+	for _, name := range names {
+		for key, raw := range f {
+			if !strings.EqualFold(key, name) {
+				continue
+			}
+			// Decoded as uint64 first so large counters keep their precision.
+			var v uint64
+			if json.Unmarshal(raw, &v) == nil {
+				return v
+			}
+			var fv float64
+			if json.Unmarshal(raw, &fv) == nil && fv > 0 {
+				return uint64(fv)
+			}
+		}
+	}
+	return 0
+}
+
+// routingProviderEvent is the QueryEventType kubo uses for a found provider in routing/findprovs output.
+const routingProviderEvent = 4
+
+// FindProvidersCount queries the DHT for providers of cid and returns how many distinct providers were found,
+// stopping after maxProviders. The query is bounded by ctx.
+func (c *Client) FindProvidersCount(ctx context.Context, cid string, maxProviders int) (int, error) {
+	url := fmt.Sprintf("%s/api/v0/routing/findprovs?arg=%s&num-providers=%d", c.apiURL, cid, maxProviders)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, statusError("findprovs", resp)
+	}
+
+	// The response is a stream of JSON query events, one per line.
+	providers := make(map[string]struct{})
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event struct {
+			Type      int `json:"Type"`
+			Responses []struct {
+				ID string `json:"ID"`
+			} `json:"Responses"`
+		}
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF || ctx.Err() != nil {
+				break
+			}
+			return len(providers), fmt.Errorf("failed to decode response: %w", err)
+		}
+		if event.Type != routingProviderEvent {
+			continue
+		}
+		for _, r := range event.Responses {
+			providers[r.ID] = struct{}{}
+		}
+	}
+
+	return len(providers), nil
+}
+
+// SwarmConnect opens a connection to the peer at multiaddr. If ctx is canceled or times out, the
+// context error is returned unwrapped so callers can tell cancellation apart from an unreachable peer.
+func (c *Client) SwarmConnect(ctx context.Context, multiaddr string) error {
+	reqURL := fmt.Sprintf("%s/api/v0/swarm/connect?arg=%s", c.apiURL, neturl.QueryEscape(multiaddr))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError("swarm connect", resp)
+	}
+
+	return nil
+}
+
+// Reachability values reported by Client.Reachability.
+const (
+	ReachabilityUnknown = "unknown"
+	ReachabilityPublic  = "public"
+	ReachabilityPrivate = "private"
+)
+
+// Reachability returns the node's AutoNAT reachability ("public", "private" or "unknown") as reported by
+// /api/v0/swarm/addrs/autonat. Kubo versions without this endpoint yield "unknown" and no error.
+func (c *Client) Reachability(ctx context.Context) (string, error) {
+	url := fmt.Sprintf("%s/api/v0/swarm/addrs/autonat", c.apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return ReachabilityUnknown, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return ReachabilityUnknown, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Older kubo answers unknown commands with 404 (or 400/500 with "unknown command").
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusBadRequest {
+		return ReachabilityUnknown, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ReachabilityUnknown, statusError("autonat", resp)
+	}
+
+	var result struct {
+		Reachability string `json:"Reachability"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return ReachabilityUnknown, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	switch strings.ToLower(result.Reachability) {
+	case ReachabilityPublic:
+		return ReachabilityPublic, nil
+	case ReachabilityPrivate:
+		return ReachabilityPrivate, nil
+	default:
+		return ReachabilityUnknown, nil
+	}
+}
+
+// Unpin removes the recursive pin for the given CID. The blocks are reclaimed on the next repo GC. A CID
+// that is not pinned counts as success, so unpinning is idempotent.
+func (c *Client) Unpin(ctx context.Context, cid string) error {
+	url := fmt.Sprintf("%s/api/v0/pin/rm?arg=%s", c.apiURL, neturl.QueryEscape(cid))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		if err := statusError("unpin", resp); !errors.Is(err, ErrNotPinned) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsPinned reports whether the given CID is pinned recursively, using pin/ls.
+func (c *Client) IsPinned(ctx context.Context, cid string) (bool, error) {
+	url := fmt.Sprintf("%s/api/v0/pin/ls?arg=%s&type=recursive", c.apiURL, neturl.QueryEscape(cid))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return true, nil
+	}
+	// kubo answers 500 with an "is not pinned" message for CIDs without a pin.
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusInternalServerError && strings.Contains(string(bodyBytes), "not pinned") {
+		return false, nil
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return false, fmt.Errorf("IPFS pin ls failed with status %d: %w", resp.StatusCode, ErrUnauthorized)
+	}
+	return false, fmt.Errorf("IPFS pin ls failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+}
+
+// ListPins returns every recursively pinned CID, sorted, decoded from pin/ls's Keys map. The whole pinset
+// is held in memory; use StreamPins for large pinsets.
+func (c *Client) ListPins(ctx context.Context) ([]string, error) {
+	url := fmt.Sprintf("%s/api/v0/pin/ls?type=recursive", c.apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("pin ls", resp)
+	}
+
+	var result struct {
+		Keys map[string]struct {
+			Type string `json:"Type"`
+		} `json:"Keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return slices.Sorted(maps.Keys(result.Keys)), nil
+}
+
+// StreamPins calls fn for every recursively pinned CID. The pin/ls output is streamed and decoded
+// incrementally, so the pinset is never held in memory. Iteration stops at the first error from fn.
+// Listing a large pinset can outlast the client-wide timeout, so like pin/add it is bounded by ctx only.
+func (c *Client) StreamPins(ctx context.Context, fn func(cid string) error) error {
+	url := fmt.Sprintf("%s/api/v0/pin/ls?type=recursive&stream=true", c.apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := *c.httpClient
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError("pin ls", resp)
+	}
+
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var entry struct {
+			Cid string `json:"Cid"`
+		}
+		if err := dec.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+		if err := fn(entry.Cid); err != nil {
+			return err
+		}
+	}
+	// kubo reports errors that occur mid-stream in a trailer.
+	if streamErr := resp.Trailer.Get("X-Stream-Error"); streamErr != "" {
+		return fmt.Errorf("IPFS pin ls failed: %s", streamErr)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRejectedCredentials(t *testing.T) {
+	for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden} {
+		var gatewayProbes atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet {
+				gatewayProbes.Add(1)
+			}
+			http.Error(w, "unauthorized", code)
+		}))
+		c := NewClient(srv.URL)
+		ctx := context.Background()
+
+		if _, err := c.Version(ctx); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("status %d: Version = %v, want ErrUnauthorized", code, err)
+		}
+		if err := c.Pin(ctx, "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("status %d: Pin = %v, want ErrUnauthorized", code, err)
+		}
+		if _, err := c.RepoStat(ctx); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("status %d: RepoStat = %v, want ErrUnauthorized", code, err)
+		}
+		if err := c.CheckAPI(ctx); !errors.Is(err, ErrUnauthorized) {
+			t.Errorf("status %d: CheckAPI = %v, want ErrUnauthorized", code, err)
+		}
+		if n := gatewayProbes.Load(); n != 0 {
+			t.Errorf("status %d: CheckAPI probed for a gateway %d times after rejected credentials", code, n)
+		}
+		srv.Close()
+	}
+}
+
+func TestStartDaemonRejectedCredentials(t *testing.T) {
+	m, _ := newFakeDaemonManager(t)
+	t.Setenv(fakeDaemonStatusEnv, strconv.Itoa(http.StatusUnauthorized))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := m.StartDaemon(ctx)
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("StartDaemon = %v, want ErrUnauthorized", err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("StartDaemon kept retrying after the API rejected its credentials")
+	}
+	if m.IsReady() {
+		t.Fatal("daemon ready although the API rejected the credentials")
+	}
+}
+
+func TestPinAlreadyPinned(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		io.WriteString(w, `{"Message":"pin: bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi already pinned recursively","Code":0,"Type":"error"}`)
+	}))
+	defer srv.Close()
+
+	if err := NewClient(srv.URL).Pin(context.Background(), "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"); err != nil {
+		t.Fatalf("Pin = %v, want success for already pinned content", err)
+	}
+}
+
+func TestStreamPinsOutlastsClientTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		for _, cid := range []string{"bafy-a", "bafy-b"} {
+			time.Sleep(100 * time.Millisecond)
+			fmt.Fprintf(w, "{\"Cid\":%q,\"Type\":\"recursive\"}\n", cid)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL)
+	c.httpClient.Timeout = 50 * time.Millisecond
+	var got []string
+	err := c.StreamPins(context.Background(), func(cid string) error {
+		got = append(got, cid)
+		return nil
+	})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("StreamPins = %v, %v; want both pins despite the client-wide timeout", got, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.StreamPins(ctx, func(string) error { return nil }); err == nil {
+		t.Fatal("StreamPins outlived its context")
+	}
+}
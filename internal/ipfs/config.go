@@ -4,10 +4,17 @@
 package ipfs
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/wabisaby/wabisaby-node/internal/fsutil"
 )
 
 const (
@@ -21,6 +28,36 @@ type PrivateNetworkConfig struct {
 	NetworkName    string   // Network identifier
 }
 
+// swarmKeyHeader starts a kubo private network key file (swarm.key); the hex-encoded key follows.
+const swarmKeyHeader = "/key/swarm/psk/1.0.0/\n/base16/\n"
+
+// GenerateSwarmKey returns a new random 256-bit private network key in the swarm.key file format.
+func GenerateSwarmKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("generate swarm key: %w", err)
+	}
+	return swarmKeyHeader + hex.EncodeToString(key) + "\n", nil
+}
+
+// NormalizeSwarmKey returns key in the swarm.key file format. key is either the contents of a swarm.key
+// file or just the 64 hex characters of the key.
+func NormalizeSwarmKey(key string) (string, error) {
+	var hexKey string
+	switch fields := strings.Fields(key); {
+	case len(fields) == 1:
+		hexKey = fields[0]
+	case len(fields) == 3 && fields[0] == "/key/swarm/psk/1.0.0/" && fields[1] == "/base16/":
+		hexKey = fields[2]
+	default:
+		return "", fmt.Errorf("not a /key/swarm/psk/1.0.0/ base16 swarm key")
+	}
+	if b, err := hex.DecodeString(hexKey); err != nil || len(b) != 32 {
+		return "", fmt.Errorf("swarm key must be 64 hex characters")
+	}
+	return swarmKeyHeader + strings.ToLower(hexKey) + "\n", nil
+}
+
 // ConfigureSwarmKey writes the swarm key to the IPFS config directory.
 func ConfigureSwarmKey(repoPath, swarmKey string) error {
 	if swarmKey == "" {
@@ -28,7 +65,7 @@ func ConfigureSwarmKey(repoPath, swarmKey string) error {
 	}
 
 	swarmKeyPath := filepath.Join(repoPath, "swarm.key")
-	if err := os.WriteFile(swarmKeyPath, []byte(swarmKey), 0o600); err != nil {
+	if err := fsutil.WriteFileAtomic(swarmKeyPath, []byte(swarmKey), 0o600); err != nil {
 		return fmt.Errorf("failed to write swarm key: %w", err)
 	}
 
@@ -67,13 +104,28 @@ func ConfigureBootstrapPeers(repoPath string, bootstrapPeers []string) error {
 		return fmt.Errorf("failed to marshal IPFS config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, updatedConfig, 0o644); err != nil {
+	if err := fsutil.WriteFileAtomic(configPath, updatedConfig, 0o644); err != nil {
 		return fmt.Errorf("failed to write IPFS config: %w", err)
 	}
 
 	return nil
 }
 
+// ReadBootstrapPeers returns the bootstrap peers listed in the IPFS config.
+func ReadBootstrapPeers(repoPath string) ([]string, error) {
+	configData, err := os.ReadFile(filepath.Join(repoPath, "config"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IPFS config: %w", err)
+	}
+	var config struct {
+		Bootstrap []string
+	}
+	if err := json.Unmarshal(configData, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse IPFS config: %w", err)
+	}
+	return config.Bootstrap, nil
+}
+
 // RemovePublicBootstrap removes default public IPFS bootstrap nodes from config.
 func RemovePublicBootstrap(repoPath string) error {
 	configPath := filepath.Join(repoPath, "config")
@@ -134,9 +186,156 @@ func RemovePublicBootstrap(repoPath string) error {
 		return fmt.Errorf("failed to marshal IPFS config: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, updatedConfig, 0o644); err != nil {
+	if err := fsutil.WriteFileAtomic(configPath, updatedConfig, 0o644); err != nil {
 		return fmt.Errorf("failed to write IPFS config: %w", err)
 	}
 
 	return nil
 }
+
+// experimentalFeatures maps the lower-cased names accepted in ipfs.experimental to their location in the
+// kubo config. Most live under "Experimental"; AcceleratedDHTClient moved to "Routing" in newer kubo.
+var experimentalFeatures = map[string][2]string{
+	"filestoreenabled":     {"Experimental", "FilestoreEnabled"},
+	"urlstoreenabled":      {"Experimental", "UrlstoreEnabled"},
+	"libp2pstreammounting": {"Experimental", "Libp2pStreamMounting"},
+	"p2phttpproxy":         {"Experimental", "P2pHttpProxy"},
+	"strategicproviding":   {"Experimental", "StrategicProviding"},
+	"optimisticprovide":    {"Experimental", "OptimisticProvide"},
+	"gatewayoverlibp2p":    {"Experimental", "GatewayOverLibp2p"},
+	"accelerateddhtclient": {"Routing", "AcceleratedDHTClient"},
+}
+
+// ValidateExperimentalFeatures returns an error naming every feature key that is not supported.
+func ValidateExperimentalFeatures(features map[string]bool) error {
+	var unknown []string
+	for name := range features {
+		if _, ok := experimentalFeatures[strings.ToLower(name)]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown IPFS experimental features: %s", strings.Join(unknown, ", "))
+	}
+	return nil
+}
+
+// ApplyExperimentalFeatures sets the given feature flags in the IPFS config and reports whether anything
+// changed (in which case a running daemon must be restarted to pick them up).
+func ApplyExperimentalFeatures(repoPath string, features map[string]bool) (bool, error) {
+	if len(features) == 0 {
+		return false, nil
+	}
+	if err := ValidateExperimentalFeatures(features); err != nil {
+		return false, err
+	}
+
+	configPath := filepath.Join(repoPath, "config")
+	configData, err := os.ReadFile(configPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read IPFS config: %w", err)
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(configData, &config); err != nil {
+		return false, fmt.Errorf("failed to parse IPFS config: %w", err)
+	}
+
+	changed := false
+	for name, enabled := range features {
+		loc := experimentalFeatures[strings.ToLower(name)]
+		section, _ := config[loc[0]].(map[string]interface{})
+		if section == nil {
+			section = make(map[string]interface{})
+			config[loc[0]] = section
+		}
+		if current, ok := section[loc[1]].(bool); ok && current == enabled {
+			continue
+		}
+		section[loc[1]] = enabled
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+
+	updatedConfig, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal IPFS config: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(configPath, updatedConfig, 0o644); err != nil {
+		return false, fmt.Errorf("failed to write IPFS config: %w", err)
+	}
+
+	return true, nil
+}
+
+// LoadConfigOverlay reads a JSON overlay for the IPFS config from path. The overlay must be a JSON object.
+func LoadConfigOverlay(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IPFS config overlay: %w", err)
+	}
+	var overlay map[string]interface{}
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		return nil, fmt.Errorf("IPFS config overlay %s is not a JSON object: %w", path, err)
+	}
+	return overlay, nil
+}
+
+// MergeConfig deep-merges overlay into base: nested objects are merged key by key and any other value
+// replaces the one in base. It returns the dotted paths of the values that changed, sorted.
+func MergeConfig(base, overlay map[string]interface{}) []string {
+	var changed []string
+	mergeConfig(base, overlay, "", &changed)
+	sort.Strings(changed)
+	return changed
+}
+
+func mergeConfig(base, overlay map[string]interface{}, prefix string, changed *[]string) {
+	for key, value := range overlay {
+		path := prefix + key
+		if sub, ok := value.(map[string]interface{}); ok {
+			if existing, ok := base[key].(map[string]interface{}); ok {
+				mergeConfig(existing, sub, path+".", changed)
+				continue
+			}
+		}
+		if current, ok := base[key]; ok && reflect.DeepEqual(current, value) {
+			continue
+		}
+		base[key] = value
+		*changed = append(*changed, path)
+	}
+}
+
+// ApplyConfigOverlay deep-merges overlay into the IPFS config and returns the dotted paths of the values
+// that changed (in which case a running daemon must be restarted to pick them up).
+func ApplyConfigOverlay(repoPath string, overlay map[string]interface{}) ([]string, error) {
+	configPath := filepath.Join(repoPath, "config")
+	configData, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IPFS config: %w", err)
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(configData, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse IPFS config: %w", err)
+	}
+
+	changed := MergeConfig(config, overlay)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	updatedConfig, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal IPFS config: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(configPath, updatedConfig, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write IPFS config: %w", err)
+	}
+	return changed, nil
+}
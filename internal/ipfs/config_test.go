@@ -0,0 +1,170 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func decodeJSON(t *testing.T, s string) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestMergeConfig(t *testing.T) {
+	base := decodeJSON(t, `{
+		"Addresses": {"API": "/ip4/127.0.0.1/tcp/5001", "Swarm": ["/ip4/0.0.0.0/tcp/4001"]},
+		"Datastore": {"StorageMax": "10GB", "GCPeriod": "1h"},
+		"Routing": {"Type": "dht"}
+	}`)
+	overlay := decodeJSON(t, `{
+		"Addresses": {"Swarm": ["/ip4/0.0.0.0/tcp/4002"]},
+		"Datastore": {"StorageMax": "10GB", "BloomFilterSize": 1048576},
+		"Routing": "none-object",
+		"Swarm": {"ConnMgr": {"HighWater": 200}}
+	}`)
+
+	changed := MergeConfig(base, overlay)
+	wantChanged := []string{"Addresses.Swarm", "Datastore.BloomFilterSize", "Routing", "Swarm"}
+	if !reflect.DeepEqual(changed, wantChanged) {
+		t.Fatalf("changed = %v, want %v", changed, wantChanged)
+	}
+	want := decodeJSON(t, `{
+		"Addresses": {"API": "/ip4/127.0.0.1/tcp/5001", "Swarm": ["/ip4/0.0.0.0/tcp/4002"]},
+		"Datastore": {"StorageMax": "10GB", "GCPeriod": "1h", "BloomFilterSize": 1048576},
+		"Routing": "none-object",
+		"Swarm": {"ConnMgr": {"HighWater": 200}}
+	}`)
+	if !reflect.DeepEqual(base, want) {
+		t.Fatalf("merged config = %v, want %v", base, want)
+	}
+
+	// Merging the same overlay again changes nothing.
+	if changed := MergeConfig(base, overlay); len(changed) != 0 {
+		t.Fatalf("second merge changed %v, want nothing", changed)
+	}
+}
+
+func TestApplyConfigOverlay(t *testing.T) {
+	repo := t.TempDir()
+	configPath := filepath.Join(repo, "config")
+	if err := os.WriteFile(configPath, []byte(`{"Datastore": {"StorageMax": "10GB"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	overlayPath := filepath.Join(t.TempDir(), "overlay.json")
+	if err := os.WriteFile(overlayPath, []byte(`{"Datastore": {"StorageMax": "50GB"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	overlay, err := LoadConfigOverlay(overlayPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := ApplyConfigOverlay(repo, overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Datastore.StorageMax"}; !reflect.DeepEqual(changed, want) {
+		t.Fatalf("changed = %v, want %v", changed, want)
+	}
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := decodeJSON(t, string(data)); got["Datastore"].(map[string]interface{})["StorageMax"] != "50GB" {
+		t.Fatalf("config after overlay = %s", data)
+	}
+}
+
+func TestLoadConfigOverlayInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"array.json":     `["Datastore"]`,
+		"truncated.json": `{"Datastore": {"StorageMax": `,
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadConfigOverlay(path); err == nil {
+			t.Errorf("LoadConfigOverlay(%s) accepted invalid JSON", name)
+		}
+	}
+	if _, err := LoadConfigOverlay(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("LoadConfigOverlay accepted a missing file")
+	}
+}
+
+func TestBootstrapAppliedOverOverlay(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "config"), []byte(`{"Bootstrap": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	overlay := decodeJSON(t, `{"Bootstrap": ["/dnsaddr/bootstrap.libp2p.io/p2p/QmNnooDu7bfjPFoTZYxMNLWUQJyrVwtbZg5gBMjTezGAJN"]}`)
+	if _, err := ApplyConfigOverlay(repo, overlay); err != nil {
+		t.Fatal(err)
+	}
+
+	// Setup applies the node's own bootstrap peers after the overlay.
+	peers := []string{"/ip4/10.0.0.1/tcp/4001/p2p/12D3KooWGzBqYzBdt1Dn6XVPbDMhsxqWT4DdJc3hNFCvG9zBbcQp"}
+	if err := ConfigureBootstrapPeers(repo, peers); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadBootstrapPeers(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, peers) {
+		t.Fatalf("bootstrap peers = %v, want the node's %v", got, peers)
+	}
+}
+
+func TestUpdatePrivateNetworkReportsKeyChanges(t *testing.T) {
+	repo := t.TempDir()
+	if err := os.WriteFile(filepath.Join(repo, "config"), []byte(`{"Bootstrap": []}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewIPFSManager(ManagerConfig{RepoPath: repo, Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	key := strings.Repeat("ab", 32)
+	peers := []string{"/ip4/10.0.0.1/tcp/4001/p2p/12D3KooWGzBqYzBdt1Dn6XVPbDMhsxqWT4DdJc3hNFCvG9zBbcQp"}
+
+	steps := []struct {
+		name  string
+		key   string
+		peers []string
+		want  bool
+	}{
+		{"new key", key, peers, true},
+		{"unchanged", key, peers, false},
+		{"bootstrap peers only", key, append(peers, "/ip4/10.0.0.2/tcp/4001/p2p/12D3KooWGzBqYzBdt1Dn6XVPbDMhsxqWT4DdJc3hNFCvG9zBbcQq"), false},
+		{"rotated key", strings.Repeat("cd", 32), nil, true},
+	}
+	for _, step := range steps {
+		got, err := m.UpdatePrivateNetwork(step.key, step.peers)
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got != step.want {
+			t.Fatalf("%s: keyChanged = %v, want %v", step.name, got, step.want)
+		}
+	}
+	got, err := ReadBootstrapPeers(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("bootstrap peers = %v, want the updated list written without a restart", got)
+	}
+}
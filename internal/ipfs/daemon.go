@@ -0,0 +1,180 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// pubsubFlag enables kubo's pubsub experiment.
+const pubsubFlag = "--enable-pubsub-experiment"
+
+// Delays before restarting a crashed daemon, doubling per failed attempt.
+const (
+	minDaemonRestart = 2 * time.Second
+	maxDaemonRestart = time.Minute
+)
+
+// daemonProcess is a daemon started by the manager.
+type daemonProcess struct {
+	ctx      context.Context // Context the daemon was started with; the daemon is killed when it is done
+	cmd      *exec.Cmd
+	stopping atomic.Bool   // Set before the daemon is stopped deliberately; any other exit is a crash
+	exited   chan struct{} // Closed once the process has exited and its output has been logged
+	err      error         // Result of waiting for the process; read after exited is closed
+}
+
+// running reports whether the process has not exited yet.
+func (p *daemonProcess) running() bool {
+	select {
+	case <-p.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// SetDaemonCallbacks registers functions called when the daemon exits unexpectedly (with its exit code,
+// -1 if it was killed by a signal) and when it is ready again after an automatic restart, e.g. to pause
+// task processing meanwhile. They are called from the manager's watcher goroutine and must not block.
+func (m *IPFSManager) SetDaemonCallbacks(crashed func(exitCode int, err error), restarted func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onCrash, m.onRestart = crashed, restarted
+}
+
+// watchDaemon waits for p to exit. An exit that is neither a deliberate stop nor caused by the start
+// context ending is a crash: it is logged, the daemon is marked not ready, the crash callback runs and,
+// with auto-restart enabled, the daemon is restarted.
+func (m *IPFSManager) watchDaemon(p *daemonProcess, closeLogs func()) {
+	p.err = p.cmd.Wait()
+	closeLogs()
+	// Closed before taking m.mu: StopDaemon waits for it while holding the lock.
+	close(p.exited)
+
+	m.mu.Lock()
+	current := m.daemon == p
+	if current {
+		m.daemon = nil
+		m.daemonReady = false
+	}
+	onCrash := m.onCrash
+	m.mu.Unlock()
+	if !current || p.stopping.Load() || p.ctx.Err() != nil {
+		return
+	}
+
+	exitCode := p.cmd.ProcessState.ExitCode()
+	m.logger.Error("IPFS daemon exited unexpectedly", "exit_code", exitCode, "error", p.err,
+		"auto_restart", m.autoRestart)
+	if onCrash != nil {
+		onCrash(exitCode, p.err)
+	}
+	if m.autoRestart {
+		m.restartAfterCrash()
+	}
+}
+
+// restartAfterCrash starts the daemon again, retrying with exponential backoff until it is ready or
+// StopDaemon cancels the attempts.
+func (m *IPFSManager) restartAfterCrash() {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.mu.Lock()
+	if m.restarting != nil {
+		m.mu.Unlock()
+		cancel()
+		return
+	}
+	m.restarting = cancel
+	m.mu.Unlock()
+
+	delay := minDaemonRestart
+	for attempt := 1; ; attempt++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		m.logger.Info("Restarting crashed IPFS daemon", "attempt", attempt)
+		// The daemon lives as long as ctx, so ctx is only canceled by StopDaemon, not when this returns.
+		err := m.StartDaemon(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			m.mu.Lock()
+			m.restarting = nil
+			onRestart := m.onRestart
+			m.mu.Unlock()
+			m.logger.Info("IPFS daemon restarted after crash", "attempts", attempt)
+			if onRestart != nil {
+				onRestart()
+			}
+			return
+		}
+		delay = min(delay*2, maxDaemonRestart)
+		m.logger.Warn("Failed to restart IPFS daemon", "attempt", attempt, "error", err, "retry_in", delay)
+	}
+}
+
+// DaemonArgs returns the arguments of the ipfs daemon command: "daemon", the pubsub flag when pubsub is
+// enabled, then extra. Each extra argument must be a flag, with its value attached (--routing=dhtclient),
+// and may be given once; the pubsub flag is rejected since the pubsub toggle controls it.
+func DaemonArgs(pubsub bool, extra []string) ([]string, error) {
+	args := []string{"daemon"}
+	if pubsub {
+		args = append(args, pubsubFlag)
+	}
+	seen := make(map[string]bool, len(extra))
+	for _, arg := range extra {
+		if !strings.HasPrefix(arg, "-") {
+			return nil, fmt.Errorf("daemon argument %q is not a flag; attach values as --flag=value", arg)
+		}
+		name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if "--"+name == pubsubFlag {
+			return nil, fmt.Errorf("daemon argument %q duplicates the pubsub toggle", arg)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("daemon flag --%s given more than once", name)
+		}
+		seen[name] = true
+		args = append(args, arg)
+	}
+	return args, nil
+}
+
+// daemonLog returns a writer whose output is logged line by line at level, tagged component=ipfs-daemon
+// and the stream name, so daemon output joins the node's structured log. The returned function closes the
+// writer and waits until every line has been logged; call it once the daemon command has been waited for.
+func (m *IPFSManager) daemonLog(stream string, level slog.Level) (io.Writer, func()) {
+	pr, pw := io.Pipe()
+	logger := m.logger.With("component", "ipfs-daemon", "stream", stream)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(pr)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				logger.Log(context.Background(), level, line)
+			}
+		}
+		// Keep draining after an oversized line so the daemon never blocks on a full pipe.
+		_, _ = io.Copy(io.Discard, pr)
+	}()
+	return pw, func() {
+		_ = pw.Close()
+		<-done
+	}
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// DefaultKuboVersion is the kubo release installed when no IPFS binary is found and none is configured.
+const DefaultKuboVersion = "v0.32.1"
+
+const (
+	kuboDistURL        = "https://dist.ipfs.tech/kubo" // Base URL of kubo release archives
+	maxKuboArchiveSize = 512 << 20                     // Upper bound on a downloaded release archive
+)
+
+// downloadIPFS downloads the kubo release archive for the current platform, verifies it against the
+// published checksum and installs the ipfs binary into the data dir. Partial files are removed on failure,
+// including when ctx is cancelled mid-download.
+func (m *IPFSManager) downloadIPFS(ctx context.Context) error {
+	// Determine platform
+	var platform, arch string
+	switch runtime.GOOS {
+	case "linux":
+		platform = "linux"
+	case "darwin":
+		platform = "darwin"
+	case "windows":
+		platform = "windows"
+	default:
+		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
+	}
+
+	switch runtime.GOARCH {
+	case "amd64":
+		arch = "amd64"
+	case "arm64":
+		arch = "arm64"
+	default:
+		return fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
+	}
+
+	version := m.kuboVersion
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	ext, binary := "tar.gz", "ipfs"
+	if platform == "windows" {
+		ext, binary = "zip", "ipfs.exe"
+	}
+	archiveURL := fmt.Sprintf("%s/%s/kubo_%s_%s-%s.%s", kuboDistURL, version, version, platform, arch, ext)
+
+	if err := os.MkdirAll(m.dataDir, 0o755); err != nil {
+		return fmt.Errorf("create data dir: %w", err)
+	}
+	archive, err := os.CreateTemp(m.dataDir, ".kubo-download-*")
+	if err != nil {
+		return fmt.Errorf("create download file: %w", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	m.logger.Info("Downloading IPFS (kubo)", "version", version, "url", archiveURL)
+	sha256Sum, sha512Sum := sha256.New(), sha512.New()
+	if err := fetch(ctx, archiveURL, io.MultiWriter(archive, sha256Sum, sha512Sum), maxKuboArchiveSize); err != nil {
+		return fmt.Errorf("download kubo %s: %w", version, err)
+	}
+	if err := verifyArchive(ctx, archiveURL, sha256Sum, sha512Sum); err != nil {
+		return err
+	}
+
+	dst := filepath.Join(m.dataDir, binary)
+	if err := extractBinary(archive, ext, "kubo/"+binary, dst); err != nil {
+		return fmt.Errorf("extract %s from kubo archive: %w", binary, err)
+	}
+	m.binaryPath = dst
+	m.logger.Info("IPFS (kubo) installed", "version", version, "path", dst)
+	return nil
+}
+
+// verifyArchive checks the archive digests against the checksum file published next to archiveURL, before
+// anything is extracted. The SHA-512 file published by dist.ipfs.tech is preferred; a SHA-256 file is used
+// for mirrors that only publish that. A missing checksum is an error, so an unverified archive is never
+// installed.
+func verifyArchive(ctx context.Context, archiveURL string, sha256Sum, sha512Sum hash.Hash) error {
+	for _, c := range []struct {
+		ext string
+		sum hash.Hash
+	}{{".sha512", sha512Sum}, {".sha256", sha256Sum}} {
+		var buf strings.Builder
+		err := fetch(ctx, archiveURL+c.ext, &buf, 4096)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("download kubo checksum: %w", err)
+		}
+		fields := strings.Fields(buf.String())
+		if len(fields) == 0 {
+			return fmt.Errorf("empty kubo checksum file %s", archiveURL+c.ext)
+		}
+		if got := hex.EncodeToString(c.sum.Sum(nil)); !strings.EqualFold(got, fields[0]) {
+			return fmt.Errorf("checksum mismatch for %s (%s): got %s, expected %s; refusing to install a possibly tampered archive",
+				archiveURL, strings.TrimPrefix(c.ext, "."), got, fields[0])
+		}
+		return nil
+	}
+	return fmt.Errorf("no published checksum found for %s", archiveURL)
+}
+
+// errNotFound is returned by fetch when the server answers 404.
+var errNotFound = errors.New("not found")
+
+// fetch streams the body of a GET request for url into w, failing if it exceeds limit bytes.
+func fetch(ctx context.Context, url string, w io.Writer, limit int64) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", url, errNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s: unexpected status %s", url, resp.Status)
+	}
+	n, err := io.Copy(w, io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("%s: response exceeds %d bytes", url, limit)
+	}
+	return nil
+}
+
+// extractBinary installs the archive member named name (a tar.gz or zip archive, per ext) at dst as an
+// executable.
+func extractBinary(archive *os.File, ext, name, dst string) error {
+	if ext == "zip" {
+		info, err := archive.Stat()
+		if err != nil {
+			return err
+		}
+		zr, err := zip.NewReader(archive, info.Size())
+		if err != nil {
+			return err
+		}
+		f, err := zr.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return writeExecutable(f, dst)
+	}
+
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(archive)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return fmt.Errorf("%s not found in archive", name)
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Name == name && hdr.Typeflag == tar.TypeReg {
+			return writeExecutable(tr, dst)
+		}
+	}
+}
+
+// checkVersion compares the version reported by binary with the expected kubo version. A mismatch, or a
+// binary that cannot report its version, is an error in strict mode and a warning otherwise.
+func (m *IPFSManager) checkVersion(ctx context.Context, binary string) error {
+	if m.expectedVer == "" {
+		return nil
+	}
+	want := strings.TrimPrefix(m.expectedVer, "v")
+	got, err := binaryVersion(ctx, binary)
+	switch {
+	case err != nil && m.strictVer:
+		return fmt.Errorf("query version of IPFS binary %s: %w", binary, err)
+	case err != nil:
+		m.logger.Warn("Could not query IPFS binary version", "path", binary, "error", err)
+	case got != want && m.strictVer:
+		return fmt.Errorf("IPFS binary %s is kubo %s, expected %s (strict version check enabled)", binary, got, want)
+	case got != want:
+		m.logger.Warn("IPFS binary version differs from the expected kubo version", "path", binary, "version", got, "expected", want)
+	}
+	return nil
+}
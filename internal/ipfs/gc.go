@@ -0,0 +1,67 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// RepoGC runs repo/gc, removing blocks that are neither pinned nor part of a pinned DAG, and returns the
+// number of blocks removed. Pinned content is never collected, and kubo holds pins being added for the
+// duration of the collection. The response is streamed; like pin/add, the client-wide timeout does not
+// apply and the collection is bounded by ctx.
+func (c *Client) RepoGC(ctx context.Context) (int, error) {
+	url := fmt.Sprintf("%s/api/v0/repo/gc?stream-errors=true", c.apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	client := *c.httpClient
+	client.Timeout = 0
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, statusError("repo gc", resp)
+	}
+
+	removed := 0
+	var firstErr string
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var entry struct {
+			Key   map[string]string `json:"Key"`
+			Error string            `json:"Error"`
+		}
+		if err := dec.Decode(&entry); err == io.EOF {
+			break
+		} else if err != nil {
+			return removed, fmt.Errorf("failed to decode response: %w", err)
+		}
+		switch {
+		case entry.Error != "":
+			if firstErr == "" {
+				firstErr = entry.Error
+			}
+		case entry.Key != nil:
+			removed++
+		}
+	}
+	// kubo reports errors that occur mid-stream in a trailer.
+	if streamErr := resp.Trailer.Get("X-Stream-Error"); streamErr != "" && firstErr == "" {
+		firstErr = streamErr
+	}
+	if firstErr != "" {
+		return removed, fmt.Errorf("IPFS repo gc failed: %s", firstErr)
+	}
+	return removed, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/wabisaby/wabisaby-node/internal/fsutil"
+)
+
+// identityFileName is the file under the data dir (outside the repo) holding the preserved IPFS identity.
+const identityFileName = "ipfs-identity.json"
+
+// repoIdentity is the Identity section of the kubo config: the peer ID and its private key.
+type repoIdentity struct {
+	PeerID  string `json:"PeerID"`
+	PrivKey string `json:"PrivKey"`
+}
+
+// identityPath returns where the preserved identity is stored.
+func (m *IPFSManager) identityPath() string {
+	return filepath.Join(m.dataDir, identityFileName)
+}
+
+// exportIdentity copies the repo's identity to identityPath, so a reinitialized repo can keep the peer
+// ID. The file holds the node's private key and is written with owner-only permissions. The caller must
+// hold m.repoMu.
+func (m *IPFSManager) exportIdentity() error {
+	cfg, err := readRepoConfig(m.repoPath)
+	if err != nil {
+		return err
+	}
+	var id repoIdentity
+	if raw, ok := cfg["Identity"]; ok {
+		if err := json.Unmarshal(raw, &id); err != nil {
+			return fmt.Errorf("parse IPFS identity: %w", err)
+		}
+	}
+	if id.PeerID == "" || id.PrivKey == "" {
+		return fmt.Errorf("IPFS config has no identity to preserve")
+	}
+	if existing, err := m.loadIdentity(); err == nil && existing == id {
+		return nil
+	}
+	data, err := json.MarshalIndent(id, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal IPFS identity: %w", err)
+	}
+	if err := os.MkdirAll(m.dataDir, 0o700); err != nil {
+		return fmt.Errorf("create data directory: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(m.identityPath(), data, 0o600); err != nil {
+		return fmt.Errorf("write preserved IPFS identity: %w", err)
+	}
+	m.logger.Info("IPFS identity preserved", "peer_id", id.PeerID, "path", m.identityPath())
+	return nil
+}
+
+// loadIdentity reads the preserved identity. It returns an error wrapping os.ErrNotExist if none was saved.
+func (m *IPFSManager) loadIdentity() (repoIdentity, error) {
+	var id repoIdentity
+	data, err := os.ReadFile(m.identityPath())
+	if err != nil {
+		return id, err
+	}
+	if err := json.Unmarshal(data, &id); err != nil {
+		return id, fmt.Errorf("parse preserved IPFS identity %s: %w", m.identityPath(), err)
+	}
+	if id.PeerID == "" || id.PrivKey == "" {
+		return id, fmt.Errorf("preserved IPFS identity %s is incomplete", m.identityPath())
+	}
+	return id, nil
+}
+
+// restoreIdentity replaces the identity of a freshly initialized repo with the preserved one, so the
+// node keeps its peer ID. The caller must hold m.repoMu.
+func (m *IPFSManager) restoreIdentity(id repoIdentity) error {
+	cfg, err := readRepoConfig(m.repoPath)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(id)
+	if err != nil {
+		return fmt.Errorf("marshal IPFS identity: %w", err)
+	}
+	cfg["Identity"] = raw
+	out, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal IPFS config: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(filepath.Join(m.repoPath, "config"), out, 0o600); err != nil {
+		return fmt.Errorf("write IPFS config: %w", err)
+	}
+	m.logger.Info("IPFS identity restored into new repository", "peer_id", id.PeerID)
+	return nil
+}
+
+// readRepoConfig reads the repo config keeping each top-level section raw, so sections this code does
+// not touch are written back unchanged.
+func readRepoConfig(repoPath string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(filepath.Join(repoPath, "config"))
+	if err != nil {
+		return nil, fmt.Errorf("read IPFS config: %w", err)
+	}
+	var cfg map[string]json.RawMessage
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse IPFS config: %w", err)
+	}
+	return cfg, nil
+}
@@ -6,33 +6,85 @@ package ipfs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
+	"slices"
+	"sync"
 	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/fsutil"
 )
 
 // IPFSManager manages the IPFS lifecycle: installation, initialization, configuration, and daemon management.
 type IPFSManager struct {
+	binaryPath   string
+	dataDir      string
+	repoPath     string // IPFS_PATH: RepoPath if configured, otherwise dataDir/.ipfs
+	apiURL       string
+	experimental map[string]bool
+	overlayFile  string         // JSON overlay deep-merged into the IPFS config during setup; none if empty
+	preserveID   bool           // Keep a copy of the repo identity under dataDir and restore it into new repos
+	repairRepo   bool           // Reinitialize a partially initialized repo instead of failing
+	adopt        bool           // Use an IPFS daemon already serving the API instead of failing to start one
+	serving      string         // Serve priority preset applied during setup; config left untouched if empty
+	clientOpts   []ClientOption // Options applied to API clients created by the manager
+	kuboVersion  string         // kubo release downloaded when no binary is installed
+	swarmKey     string         // Private network key written to the repo; generated if empty and none exists
+	pubsub       bool           // Start the daemon with the pubsub experiment enabled
+	autoRestart  bool           // Restart the daemon with backoff after it exits unexpectedly
+	daemonArgs   []string       // Extra flags appended to the daemon command (see DaemonArgs)
+	expectedVer  string         // kubo version an installed binary should report; unchecked if empty
+	strictVer    bool           // Refuse an installed binary reporting a different version than expectedVer
+	logger       *slog.Logger
+
+	// repoMu serializes operations that run the ipfs CLI against the repo or rewrite its files, so setup
+	// steps triggered concurrently (startup, config reload, the daemon supervisor) cannot corrupt the repo
+	// or race on the config file. It is held by EnsureInstalled (which may set binaryPath), InitializeRepo,
+	// ConfigurePrivateNetwork, UpdatePrivateNetwork, ConfigureExperimental and ConfigureServePriority while writing the config,
+	// StartDaemon while setting the API address, and Upgrade while migrating the repo. When both are
+	// needed, mu is acquired before repoMu.
+	repoMu sync.Mutex
+
+	// mu serializes daemon lifecycle operations (start, stop, restart) and guards the fields below.
+	mu          sync.Mutex
 	ipfsClient  *Client
-	binaryPath  string
-	dataDir     string
-	apiURL      string
-	logger      *slog.Logger
-	daemonCmd   *exec.Cmd
+	daemon      *daemonProcess // Daemon started by the manager; nil when none runs or the daemon was adopted
 	daemonReady bool
+	restarting  context.CancelFunc // Cancels the crash restart loop; nil when none runs
+	onCrash     func(exitCode int, err error)
+	onRestart   func()
 }
 
 // ManagerConfig holds configuration for the IPFS manager.
 type ManagerConfig struct {
-	BinaryPath string // Path to IPFS binary (if empty, will be auto-detected/installed)
-	DataDir    string // IPFS data directory (default: ~/.wabisaby/ipfs)
-	APIURL     string // IPFS API URL (default: http://localhost:5001)
-	Logger     *slog.Logger
+	BinaryPath    string          // Path to IPFS binary (if empty, will be auto-detected/installed)
+	DataDir       string          // IPFS data directory (default: ~/.wabisaby/ipfs)
+	RepoPath      string          // IPFS repo path used verbatim as IPFS_PATH (default: DataDir/.ipfs)
+	APIURL        string          // IPFS API URL (default: http://localhost:5001)
+	Experimental  map[string]bool // Experimental kubo features to enable/disable (see ApplyExperimentalFeatures)
+	OverlayFile   string          // Optional JSON overlay deep-merged into the IPFS config (see ApplyConfigOverlay)
+	PreserveID    bool            // Preserve the IPFS identity across repo reinitialization (see InitializeRepo)
+	RepairRepo    bool            // Set a partially initialized repo aside and reinitialize it (see InitializeRepo)
+	ServePriority string          // Serve priority preset: balanced, serve, ingest or empty (see ApplyServePriority)
+	AdoptDaemon   bool            // Use an IPFS daemon already serving the API (see StartDaemon)
+	ClientOptions []ClientOption  // Options applied to the manager's API clients (e.g. request tracing)
+	KuboVersion   string          // kubo release downloaded when no binary is found (default: DefaultKuboVersion)
+	SwarmKey      string          // Private network key (swarm.key contents or 64 hex chars; see ConfigurePrivateNetwork)
+	Pubsub        bool            // Start the daemon with --enable-pubsub-experiment
+	AutoRestart   bool            // Restart the daemon with backoff when it exits unexpectedly (see SetDaemonCallbacks)
+	DaemonArgs    []string        // Extra daemon flags, e.g. --routing=dhtclient or --migrate (see DaemonArgs)
+	Logger        *slog.Logger
+
+	// ExpectedKuboVersion is the version an installed binary should report (e.g. "v0.32.1"); unchecked if
+	// empty. StrictVersion refuses a binary reporting another version instead of logging a warning.
+	ExpectedKuboVersion string
+	StrictVersion       bool
 }
 
 // NewIPFSManager creates a new IPFS manager.
@@ -41,52 +93,115 @@ func NewIPFSManager(cfg ManagerConfig) *IPFSManager {
 		homeDir, _ := os.UserHomeDir()
 		cfg.DataDir = filepath.Join(homeDir, ".wabisaby", "ipfs")
 	}
+	if cfg.RepoPath == "" {
+		cfg.RepoPath = filepath.Join(cfg.DataDir, ".ipfs")
+	}
 	if cfg.APIURL == "" {
 		cfg.APIURL = "http://localhost:5001"
 	}
+	if cfg.KuboVersion == "" {
+		cfg.KuboVersion = DefaultKuboVersion
+	}
 
 	return &IPFSManager{
-		binaryPath: cfg.BinaryPath,
-		dataDir:    cfg.DataDir,
-		apiURL:     cfg.APIURL,
-		logger:     cfg.Logger,
+		binaryPath:   cfg.BinaryPath,
+		dataDir:      cfg.DataDir,
+		repoPath:     cfg.RepoPath,
+		apiURL:       cfg.APIURL,
+		experimental: cfg.Experimental,
+		overlayFile:  cfg.OverlayFile,
+		preserveID:   cfg.PreserveID,
+		repairRepo:   cfg.RepairRepo,
+		serving:      cfg.ServePriority,
+		adopt:        cfg.AdoptDaemon,
+		clientOpts:   cfg.ClientOptions,
+		kuboVersion:  cfg.KuboVersion,
+		swarmKey:     cfg.SwarmKey,
+		pubsub:       cfg.Pubsub,
+		autoRestart:  cfg.AutoRestart,
+		daemonArgs:   cfg.DaemonArgs,
+		expectedVer:  cfg.ExpectedKuboVersion,
+		strictVer:    cfg.StrictVersion,
+		logger:       cfg.Logger,
 	}
 }
 
-// EnsureInstalled checks if IPFS binary exists and downloads it if missing.
+// EnsureInstalled checks if IPFS binary exists and downloads it if missing. An installed binary that does
+// not report the expected kubo version is refused in strict mode and logged otherwise.
 func (m *IPFSManager) EnsureInstalled(ctx context.Context) error {
+	m.repoMu.Lock()
+	defer m.repoMu.Unlock()
+
 	if m.binaryPath != "" {
 		if _, err := os.Stat(m.binaryPath); err == nil {
 			m.logger.Info("IPFS binary found", "path", m.binaryPath)
-			return nil
+			return m.checkVersion(ctx, m.binaryPath)
 		}
 	}
 
 	// Try to find IPFS in PATH
 	if path, err := exec.LookPath("ipfs"); err == nil {
-		m.binaryPath = path
 		m.logger.Info("IPFS binary found in PATH", "path", path)
+		if err := m.checkVersion(ctx, path); err != nil {
+			return err
+		}
+		m.binaryPath = path
 		return nil
 	}
 
 	// Download IPFS binary
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	m.logger.Info("IPFS binary not found, downloading...")
 	return m.downloadIPFS(ctx)
 }
 
-// InitializeRepo initializes the IPFS repository if it doesn't exist.
+// InitializeRepo initializes the IPFS repository if it doesn't exist. An existing repo that is only
+// partially initialized (e.g. an interrupted init) is an error wrapping ErrPartialRepo, unless repair is
+// enabled, in which case it is moved aside and a new repo is initialized. With identity preservation
+// enabled, the identity of an existing repo is saved under the data dir, and a new repo is given the saved
+// identity so the node keeps its peer ID.
 func (m *IPFSManager) InitializeRepo(ctx context.Context) error {
-	repoPath := filepath.Join(m.dataDir, ".ipfs")
+	m.repoMu.Lock()
+	defer m.repoMu.Unlock()
+
+	repoPath := m.repoPath
 	configPath := filepath.Join(repoPath, "config")
 
 	// Check if repo already exists
 	if _, err := os.Stat(configPath); err == nil {
-		m.logger.Info("IPFS repository already initialized", "path", repoPath)
-		return nil
+		if err := checkRepo(repoPath); err != nil {
+			if !m.repairRepo {
+				return fmt.Errorf("%w; set ipfs.repair_repo to reinitialize it", err)
+			}
+			aside, moveErr := setAsidePartialRepo(repoPath)
+			if moveErr != nil {
+				return moveErr
+			}
+			m.logger.Warn("IPFS repository is partially initialized, reinitializing", "error", err, "moved_to", aside)
+		} else {
+			m.logger.Info("IPFS repository already initialized", "path", repoPath)
+			if m.preserveID {
+				return m.exportIdentity()
+			}
+			return nil
+		}
 	}
 
-	// Create data directory
-	if err := os.MkdirAll(m.dataDir, 0o755); err != nil {
+	var preserved *repoIdentity
+	if m.preserveID {
+		id, err := m.loadIdentity()
+		switch {
+		case err == nil:
+			preserved = &id
+		case !errors.Is(err, os.ErrNotExist):
+			return err
+		}
+	}
+
+	// Create the directory containing the repo
+	if err := os.MkdirAll(filepath.Dir(repoPath), 0o755); err != nil {
 		return fmt.Errorf("failed to create data directory: %w", err)
 	}
 
@@ -106,20 +221,88 @@ func (m *IPFSManager) InitializeRepo(ctx context.Context) error {
 	}
 
 	m.logger.Info("IPFS repository initialized successfully")
+	if preserved != nil {
+		return m.restoreIdentity(*preserved)
+	}
+	if m.preserveID {
+		return m.exportIdentity()
+	}
 	return nil
 }
 
-// ConfigurePrivateNetwork configures IPFS for private network with swarm key and bootstrap peers.
+// ConfigureOverlay deep-merges the configured JSON overlay into the IPFS config and logs the keys it
+// changed. It runs before ConfigurePrivateNetwork and before the API address is set, so settings the
+// node manages itself are applied over the overlay. If the config changed while the daemon is running,
+// the daemon is restarted.
+func (m *IPFSManager) ConfigureOverlay(ctx context.Context) error {
+	if m.overlayFile == "" {
+		return nil
+	}
+	overlay, err := LoadConfigOverlay(m.overlayFile)
+	if err != nil {
+		return err
+	}
+	m.repoMu.Lock()
+	changed, err := ApplyConfigOverlay(m.repoPath, overlay)
+	m.repoMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	m.logger.Info("IPFS config overlay applied", "file", m.overlayFile, "changed", changed)
+
+	m.mu.Lock()
+	running := m.daemon != nil
+	m.mu.Unlock()
+	if !running {
+		return nil
+	}
+	m.logger.Info("Restarting IPFS daemon to apply config overlay")
+	return m.RestartDaemon(ctx)
+}
+
+// ConfigurePrivateNetwork configures IPFS for private network with swarm key and bootstrap peers. An empty
+// swarmKey falls back to the configured key; without one, the repo's existing swarm.key is kept or a new
+// key is generated and persisted, so the node never joins the public network by default.
 func (m *IPFSManager) ConfigurePrivateNetwork(ctx context.Context, swarmKey string, bootstrapPeers []string) error {
-	repoPath := filepath.Join(m.dataDir, ".ipfs")
+	m.repoMu.Lock()
+	defer m.repoMu.Unlock()
+
+	repoPath := m.repoPath
 	swarmKeyPath := filepath.Join(repoPath, "swarm.key")
 
-	// Write swarm key
+	// Write swarm key: the given one, else the configured one, else keep the repo's key or generate one
+	if swarmKey == "" {
+		swarmKey = m.swarmKey
+	}
 	if swarmKey != "" {
-		if err := os.WriteFile(swarmKeyPath, []byte(swarmKey), 0o600); err != nil {
+		key, err := NormalizeSwarmKey(swarmKey)
+		if err != nil {
+			return err
+		}
+		if err := fsutil.WriteFileAtomic(swarmKeyPath, []byte(key), 0o600); err != nil {
 			return fmt.Errorf("failed to write swarm key: %w", err)
 		}
 		m.logger.Info("Swarm key configured", "path", swarmKeyPath)
+	} else if _, err := os.Stat(swarmKeyPath); errors.Is(err, os.ErrNotExist) {
+		key, err := GenerateSwarmKey()
+		if err != nil {
+			return err
+		}
+		if err := fsutil.WriteFileAtomic(swarmKeyPath, []byte(key), 0o600); err != nil {
+			return fmt.Errorf("failed to write swarm key: %w", err)
+		}
+		m.logger.Warn("Generated a new swarm key; only nodes sharing this key can peer with this node",
+			"path", swarmKeyPath)
+	} else if err != nil {
+		return fmt.Errorf("failed to check swarm key: %w", err)
+	}
+
+	// Public bootstrap nodes are not part of the private network
+	if err := RemovePublicBootstrap(repoPath); err != nil {
+		return fmt.Errorf("failed to remove public bootstrap peers: %w", err)
 	}
 
 	// Configure bootstrap peers
@@ -149,6 +332,97 @@ func (m *IPFSManager) ConfigurePrivateNetwork(ctx context.Context, swarmKey stri
 	return nil
 }
 
+// UpdatePrivateNetwork writes swarmKey and bootstrapPeers to the repo where they differ from what it holds
+// and reports whether the swarm key changed. An empty swarmKey or peer list leaves the current one in place.
+// The running daemon only picks up either when restarted (see RestartDaemon), but only a new swarm key
+// needs one: bootstrap peers matter at the next start, while a stale key cuts the node off the network.
+func (m *IPFSManager) UpdatePrivateNetwork(swarmKey string, bootstrapPeers []string) (keyChanged bool, err error) {
+	m.repoMu.Lock()
+	defer m.repoMu.Unlock()
+
+	if swarmKey != "" {
+		key, err := NormalizeSwarmKey(swarmKey)
+		if err != nil {
+			return false, err
+		}
+		swarmKeyPath := filepath.Join(m.repoPath, "swarm.key")
+		current, err := os.ReadFile(swarmKeyPath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return false, fmt.Errorf("failed to read swarm key: %w", err)
+		}
+		if string(current) != key {
+			if err := fsutil.WriteFileAtomic(swarmKeyPath, []byte(key), 0o600); err != nil {
+				return false, fmt.Errorf("failed to write swarm key: %w", err)
+			}
+			m.logger.Info("Swarm key updated", "path", swarmKeyPath)
+			keyChanged = true
+		}
+	}
+
+	if len(bootstrapPeers) > 0 {
+		current, err := ReadBootstrapPeers(m.repoPath)
+		if err != nil {
+			return keyChanged, err
+		}
+		want := slices.Sorted(slices.Values(bootstrapPeers))
+		if !slices.Equal(slices.Sorted(slices.Values(current)), want) {
+			if err := ConfigureBootstrapPeers(m.repoPath, want); err != nil {
+				return keyChanged, err
+			}
+			m.logger.Info("Bootstrap peers updated", "peers", len(want))
+		}
+	}
+	return keyChanged, nil
+}
+
+// ConfigureExperimental applies the configured experimental feature flags to the IPFS config. If they changed
+// while the daemon is running, the daemon is restarted so they take effect.
+func (m *IPFSManager) ConfigureExperimental(ctx context.Context) error {
+	m.repoMu.Lock()
+	changed, err := ApplyExperimentalFeatures(m.repoPath, m.experimental)
+	m.repoMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+	m.logger.Info("IPFS experimental features updated", "features", m.experimental)
+
+	m.mu.Lock()
+	running := m.daemon != nil
+	m.mu.Unlock()
+	if !running {
+		return nil
+	}
+	m.logger.Info("Restarting IPFS daemon to apply experimental features")
+	return m.RestartDaemon(ctx)
+}
+
+// ConfigureServePriority applies the configured serve priority preset to the IPFS config. If it changed
+// the config while the daemon is running, the daemon is restarted so it takes effect.
+func (m *IPFSManager) ConfigureServePriority(ctx context.Context) error {
+	m.repoMu.Lock()
+	changed, err := ApplyServePriority(m.repoPath, m.serving)
+	m.repoMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	m.logger.Info("IPFS serve priority applied", "priority", m.serving, "changed", changed)
+
+	m.mu.Lock()
+	running := m.daemon != nil
+	m.mu.Unlock()
+	if !running {
+		return nil
+	}
+	m.logger.Info("Restarting IPFS daemon to apply serve priority")
+	return m.RestartDaemon(ctx)
+}
+
 // apiAddrFromURL returns a Kubo multiaddr for the API (e.g. /ip4/127.0.0.1/tcp/5001) from apiURL.
 func apiAddrFromURL(apiURL string) (string, error) {
 	u, err := url.Parse(apiURL)
@@ -167,8 +441,9 @@ func apiAddrFromURL(apiURL string) (string, error) {
 }
 
 // setAPIAddressInConfig sets Addresses.API in the IPFS repo config so the daemon binds to the configured port.
+// The caller must hold m.repoMu.
 func (m *IPFSManager) setAPIAddressInConfig() error {
-	repoPath := filepath.Join(m.dataDir, ".ipfs")
+	repoPath := m.repoPath
 	configPath := filepath.Join(repoPath, "config")
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -192,100 +467,174 @@ func (m *IPFSManager) setAPIAddressInConfig() error {
 	if err != nil {
 		return fmt.Errorf("marshal IPFS config: %w", err)
 	}
-	if err := os.WriteFile(configPath, out, 0o600); err != nil {
+	if err := fsutil.WriteFileAtomic(configPath, out, 0o600); err != nil {
 		return fmt.Errorf("write IPFS config: %w", err)
 	}
 	m.logger.Info("IPFS API address configured", "api", apiAddr)
 	return nil
 }
 
-// StartDaemon starts the IPFS daemon in the background.
+// StartDaemon starts the IPFS daemon in the background and blocks until it is ready.
+// Concurrent calls are serialized; a call made while a daemon is already running is a no-op.
 func (m *IPFSManager) StartDaemon(ctx context.Context) error {
-	if m.daemonCmd != nil {
-		// Check if daemon is still running
-		if m.daemonCmd.Process != nil {
-			if err := m.daemonCmd.Process.Signal(os.Signal(nil)); err == nil {
-				m.logger.Info("IPFS daemon already running")
-				return nil
-			}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.daemon != nil && m.daemon.running() {
+		m.logger.Info("IPFS daemon already running")
+		return nil
+	}
+
+	// Something already answering on the API address as a gateway means api_url is misconfigured; the
+	// daemon could not bind there anyway.
+	if NewClient(m.apiURL, m.clientOpts...).servesGateway(ctx) {
+		return gatewayURLError(m.apiURL)
+	}
+	if existing, err := m.checkAPIPort(ctx); err != nil {
+		if !existing || !m.adopt {
+			return err
 		}
+		// The node did not start this daemon: it is neither stopped on shutdown nor restarted for config changes.
+		m.logger.Info("Adopting IPFS daemon already running", "api_url", m.apiURL)
+		m.ipfsClient = NewClient(m.apiURL, m.clientOpts...)
+		m.daemonReady = true
+		return nil
 	}
 
-	repoPath := filepath.Join(m.dataDir, ".ipfs")
-	if err := m.setAPIAddressInConfig(); err != nil {
+	m.repoMu.Lock()
+	err := m.setAPIAddressInConfig()
+	binaryPath := m.binaryPath
+	m.repoMu.Unlock()
+	if err != nil {
 		return fmt.Errorf("configure IPFS API address: %w", err)
 	}
+	args, err := DaemonArgs(m.pubsub, m.daemonArgs)
+	if err != nil {
+		return err
+	}
 	env := os.Environ()
-	env = append(env, fmt.Sprintf("IPFS_PATH=%s", repoPath))
+	env = append(env, fmt.Sprintf("IPFS_PATH=%s", m.repoPath))
 
-	cmd := exec.CommandContext(ctx, m.binaryPath, "daemon", "--enable-pubsub-experiment")
+	cmd := exec.CommandContext(ctx, binaryPath, args...)
 	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	tail := &outputTail{}
+	stdout, closeStdout := m.daemonLog("stdout", slog.LevelInfo)
+	stderr, closeStderr := m.daemonLog("stderr", slog.LevelWarn)
+	cmd.Stdout = io.MultiWriter(stdout, tail)
+	cmd.Stderr = io.MultiWriter(stderr, tail)
+	closeLogs := func() {
+		closeStdout()
+		closeStderr()
+	}
 
-	m.logger.Info("Starting IPFS daemon", "api_url", m.apiURL)
+	m.logger.Info("Starting IPFS daemon", "api_url", m.apiURL, "args", args[1:])
 	if err := cmd.Start(); err != nil {
+		closeLogs()
 		return fmt.Errorf("failed to start IPFS daemon: %w", err)
 	}
 
-	m.daemonCmd = cmd
+	p := &daemonProcess{ctx: ctx, cmd: cmd, exited: make(chan struct{})}
+	go m.watchDaemon(p, closeLogs)
+	m.daemon = p
 	m.daemonReady = false
 
 	// Block until daemon is ready so the rest of startup sees a consistent state
-	if err := m.waitForDaemonReady(ctx); err != nil {
-		_ = m.daemonCmd.Process.Kill()
-		m.daemonCmd = nil
+	if err := m.waitForDaemonReady(ctx, tail); err != nil {
+		p.stopping.Store(true)
+		_ = cmd.Process.Kill()
+		m.daemon = nil
 		return err
 	}
 	m.logger.Info("IPFS daemon is ready")
 	return nil
 }
 
-// waitForDaemonReady polls the IPFS API until the daemon is ready (or timeout/context cancel).
-func (m *IPFSManager) waitForDaemonReady(ctx context.Context) error {
+// waitForDaemonReady polls the IPFS API until the daemon is ready (or timeout/context cancel). A daemon
+// whose output shows it could not bind its address fails with ErrAPIPortInUse; on timeout the error
+// includes the daemon's last output. The caller must hold m.mu.
+func (m *IPFSManager) waitForDaemonReady(ctx context.Context, output *outputTail) error {
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
 
 	deadline := time.After(30 * time.Second)
-	client := NewClient(m.apiURL)
+	client := NewClient(m.apiURL, m.clientOpts...)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-deadline:
+			if out := output.String(); out != "" {
+				return fmt.Errorf("IPFS daemon did not become ready within 30s; last output:\n%s", out)
+			}
 			return fmt.Errorf("IPFS daemon did not become ready within 30s")
 		case <-ticker.C:
-			if _, err := client.Version(ctx); err == nil {
+			if output.bindFailed() {
+				return fmt.Errorf("%w: the IPFS daemon could not bind its addresses (another process took the API or swarm port); daemon output:\n%s",
+					ErrAPIPortInUse, output.String())
+			}
+			err := client.CheckAPI(ctx)
+			if err == nil {
 				m.daemonReady = true
 				m.ipfsClient = client
 				return nil
 			}
+			if errors.Is(err, ErrUnauthorized) {
+				return fmt.Errorf("IPFS daemon started but %w: check the IPFS API credentials", err)
+			}
+			if errors.Is(err, ErrGatewayURL) {
+				return err
+			}
 		}
 	}
 }
 
-// StopDaemon gracefully stops the IPFS daemon.
+// RestartDaemon stops the daemon and starts it again, blocking until it is ready, so IPFS config changes
+// (bootstrap peers, swarm key, ...) take effect. A daemon that is not running is started. An adopted daemon
+// is left running: the node did not start it and cannot restart it.
+func (m *IPFSManager) RestartDaemon(ctx context.Context) error {
+	m.mu.Lock()
+	adopted := m.daemon == nil && m.daemonReady
+	m.mu.Unlock()
+	if adopted {
+		m.logger.Warn("Not restarting adopted IPFS daemon; restart it manually to apply config changes")
+		return nil
+	}
+	if err := m.StopDaemon(ctx); err != nil {
+		return fmt.Errorf("stop IPFS daemon: %w", err)
+	}
+	return m.StartDaemon(ctx)
+}
+
+// StopDaemon gracefully stops the IPFS daemon, and cancels restarting it after a crash.
 func (m *IPFSManager) StopDaemon(ctx context.Context) error {
-	if m.daemonCmd == nil || m.daemonCmd.Process == nil {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.restarting != nil {
+		m.restarting()
+		m.restarting = nil
+	}
+	if m.daemon == nil {
 		return nil
 	}
+	p := m.daemon
+	defer func() {
+		m.daemon = nil
+		m.daemonReady = false
+	}()
 
 	m.logger.Info("Stopping IPFS daemon")
-	if err := m.daemonCmd.Process.Signal(os.Interrupt); err != nil {
+	p.stopping.Store(true)
+	if err := p.cmd.Process.Signal(os.Interrupt); err != nil {
 		return fmt.Errorf("failed to signal IPFS daemon: %w", err)
 	}
 
 	// Wait for process to exit
-	done := make(chan error, 1)
-	go func() {
-		done <- m.daemonCmd.Wait()
-	}()
-
 	select {
-	case err := <-done:
-		if err != nil {
-			return fmt.Errorf("IPFS daemon exited with error: %w", err)
+	case <-p.exited:
+		if p.err != nil {
+			return fmt.Errorf("IPFS daemon exited with error: %w", p.err)
 		}
 		m.logger.Info("IPFS daemon stopped")
 		return nil
@@ -294,14 +643,17 @@ func (m *IPFSManager) StopDaemon(ctx context.Context) error {
 	case <-time.After(10 * time.Second):
 		// Force kill if graceful shutdown fails
 		m.logger.Warn("IPFS daemon did not stop gracefully, forcing kill")
-		return m.daemonCmd.Process.Kill()
+		return p.cmd.Process.Kill()
 	}
 }
 
 // GetPeerInfo returns the peer ID and multiaddresses of the local IPFS node.
 func (m *IPFSManager) GetPeerInfo(ctx context.Context) (peerID string, multiaddrs []string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	if m.ipfsClient == nil {
-		m.ipfsClient = NewClient(m.apiURL)
+		m.ipfsClient = NewClient(m.apiURL, m.clientOpts...)
 	}
 
 	// Wait for daemon to be ready
@@ -318,10 +670,14 @@ func (m *IPFSManager) GetPeerInfo(ctx context.Context) (peerID string, multiaddr
 			case <-timeout:
 				return "", nil, fmt.Errorf("IPFS daemon not ready")
 			case <-ticker.C:
-				if _, err := m.ipfsClient.Version(ctx); err == nil {
+				_, err := m.ipfsClient.Version(ctx)
+				if err == nil {
 					m.daemonReady = true
 					break waitLoop
 				}
+				if errors.Is(err, ErrUnauthorized) {
+					return "", nil, err
+				}
 			}
 		}
 	}
@@ -329,18 +685,27 @@ func (m *IPFSManager) GetPeerInfo(ctx context.Context) (peerID string, multiaddr
 	return m.ipfsClient.ID(ctx)
 }
 
-// ConnectToPeer connects to a peer using IPFS swarm connect.
-func (m *IPFSManager) ConnectToPeer(ctx context.Context, peerAddr string) error {
-	repoPath := filepath.Join(m.dataDir, ".ipfs")
-	env := os.Environ()
-	env = append(env, fmt.Sprintf("IPFS_PATH=%s", repoPath))
+// ClientOptions returns the options applied to the manager's API clients, so other clients of the daemon
+// can be built the same way.
+func (m *IPFSManager) ClientOptions() []ClientOption {
+	return slices.Clip(m.clientOpts)
+}
 
-	cmd := exec.CommandContext(ctx, m.binaryPath, "swarm", "connect", peerAddr)
-	cmd.Env = env
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+// IsReady reports whether the daemon has been observed ready and not stopped since.
+func (m *IPFSManager) IsReady() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.daemonReady
+}
 
-	if err := cmd.Run(); err != nil {
+// ConnectToPeer connects to a peer using the IPFS swarm connect API.
+// Cancellation of ctx aborts the request and returns the context error (check with errors.Is), distinct from
+// a dial failure to an unreachable peer.
+func (m *IPFSManager) ConnectToPeer(ctx context.Context, peerAddr string) error {
+	if err := m.client().SwarmConnect(ctx, peerAddr); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
 		return fmt.Errorf("failed to connect to peer %s: %w", peerAddr, err)
 	}
 
@@ -348,31 +713,12 @@ func (m *IPFSManager) ConnectToPeer(ctx context.Context, peerAddr string) error
 	return nil
 }
 
-// downloadIPFS downloads the IPFS binary for the current platform.
-func (m *IPFSManager) downloadIPFS(ctx context.Context) error {
-	// Determine platform
-	var platform, arch string
-	switch runtime.GOOS {
-	case "linux":
-		platform = "linux"
-	case "darwin":
-		platform = "darwin"
-	case "windows":
-		platform = "windows"
-	default:
-		return fmt.Errorf("unsupported platform: %s", runtime.GOOS)
-	}
-
-	switch runtime.GOARCH {
-	case "amd64":
-		arch = "amd64"
-	case "arm64":
-		arch = "arm64"
-	default:
-		return fmt.Errorf("unsupported architecture: %s", runtime.GOARCH)
-	}
-
-	// For now, return an error asking user to install IPFS manually
-	// Full implementation would download from GitHub releases
-	return fmt.Errorf("IPFS binary not found. Please install IPFS (kubo) manually or ensure it's in your PATH. Platform: %s/%s", platform, arch)
+// client returns the API client for the managed daemon.
+func (m *IPFSManager) client() *Client {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.ipfsClient == nil {
+		m.ipfsClient = NewClient(m.apiURL, m.clientOpts...)
+	}
+	return m.ipfsClient
 }
@@ -0,0 +1,307 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Environment variables that make the test binary act as a fake IPFS daemon (see runFakeDaemon).
+const (
+	fakeDaemonAddrEnv     = "WABISABY_FAKE_IPFS_ADDR"
+	fakeDaemonLaunchesEnv = "WABISABY_FAKE_IPFS_LAUNCHES"
+	fakeDaemonStatusEnv   = "WABISABY_FAKE_IPFS_STATUS" // HTTP status answered to every API call instead of 200
+)
+
+func TestMain(m *testing.M) {
+	if addr := os.Getenv(fakeDaemonAddrEnv); addr != "" {
+		runFakeDaemon(addr, os.Getenv(fakeDaemonLaunchesEnv))
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// runFakeDaemon records its launch (its IPFS_PATH) in launches and serves /api/v0/version on addr until interrupted.
+func runFakeDaemon(addr, launches string) {
+	f, err := os.OpenFile(launches, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err == nil {
+		fmt.Fprintln(f, os.Getenv("IPFS_PATH"))
+		f.Close()
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: serveHTTPApi: manet.Listen(/ip4/127.0.0.1/tcp) failed: address already in use")
+		os.Exit(1)
+	}
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		os.Exit(0)
+	}()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v0/version", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"Version":"0.32.1"}`)
+	})
+	var handler http.Handler = mux
+	if code, err := strconv.Atoi(os.Getenv(fakeDaemonStatusEnv)); err == nil {
+		handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			http.Error(w, http.StatusText(code), code)
+		})
+	}
+	_ = http.Serve(ln, handler)
+}
+
+// newFakeDaemonManager returns a manager whose daemon binary is the test binary acting as a fake daemon
+// on a free local port, and the file the fake daemon records its launches in.
+func newFakeDaemonManager(t *testing.T) (*IPFSManager, string) {
+	t.Helper()
+	return newFakeDaemonManagerWithRepo(t, "")
+}
+
+// newFakeDaemonManagerWithRepo is newFakeDaemonManager with the repo at repoPath; empty uses the
+// default under the data dir.
+func newFakeDaemonManagerWithRepo(t *testing.T, repoPath string) (*IPFSManager, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	dir := t.TempDir()
+	repo := repoPath
+	if repo == "" {
+		repo = filepath.Join(dir, ".ipfs")
+	}
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "config"), []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	launches := filepath.Join(dir, "launches")
+	t.Setenv(fakeDaemonAddrEnv, addr)
+	t.Setenv(fakeDaemonLaunchesEnv, launches)
+
+	m := NewIPFSManager(ManagerConfig{
+		BinaryPath: os.Args[0],
+		DataDir:    dir,
+		RepoPath:   repoPath,
+		APIURL:     "http://" + addr,
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	return m, launches
+}
+
+func TestStartDaemonConcurrent(t *testing.T) {
+	m, launches := newFakeDaemonManager(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- m.StartDaemon(ctx)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	defer m.StopDaemon(context.Background())
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("StartDaemon: %v", err)
+		}
+	}
+	data, err := os.ReadFile(launches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(strings.Split(strings.TrimSpace(string(data)), "\n")); n != 1 {
+		t.Fatalf("%d daemons launched, want 1", n)
+	}
+	if !m.IsReady() {
+		t.Fatal("daemon not ready after StartDaemon")
+	}
+}
+
+// newSwarmConnectManager returns a manager whose API answers swarm/connect with handler.
+func newSwarmConnectManager(t *testing.T, handler http.HandlerFunc) *IPFSManager {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v0/swarm/connect", handler)
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return NewIPFSManager(ManagerConfig{
+		DataDir: t.TempDir(),
+		APIURL:  srv.URL,
+		Logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+}
+
+const testPeerAddr = "/ip4/203.0.113.7/tcp/4001/p2p/12D3KooWGRUVh7Yd5u7WqBNanC5nn5Kq5WGhA7ZnbKrh6ExtEkbR"
+
+func TestConnectToPeerCanceled(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	defer close(unblock)
+	m := newSwarmConnectManager(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		select {
+		case <-r.Context().Done():
+		case <-unblock:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- m.ConnectToPeer(ctx, testPeerAddr) }()
+	<-started
+	cancel()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("ConnectToPeer = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ConnectToPeer did not return after its context was canceled")
+	}
+}
+
+func TestConnectToPeerUnreachable(t *testing.T) {
+	m := newSwarmConnectManager(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, `{"Message":"connect 12D3KooW failure: failed to dial: all dials failed","Code":0,"Type":"error"}`)
+	})
+
+	err := m.ConnectToPeer(context.Background(), testPeerAddr)
+	if err == nil {
+		t.Fatal("ConnectToPeer succeeded for an unreachable peer")
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ConnectToPeer = %v, want a dial failure distinct from cancellation", err)
+	}
+	if !strings.Contains(err.Error(), "all dials failed") {
+		t.Errorf("ConnectToPeer = %v, want the daemon's dial error", err)
+	}
+}
+
+func TestCustomRepoPath(t *testing.T) {
+	repo := filepath.Join(t.TempDir(), "shared-repo")
+	m, launches := newFakeDaemonManagerWithRepo(t, repo)
+	writeRepo(t, repo)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := m.InitializeRepo(ctx); err != nil {
+		t.Fatalf("InitializeRepo: %v", err)
+	}
+	if err := m.ConfigurePrivateNetwork(ctx, "", nil); err != nil {
+		t.Fatalf("ConfigurePrivateNetwork: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(repo, "swarm.key")); err != nil {
+		t.Errorf("swarm key not written to the custom repo: %v", err)
+	}
+	if err := m.StartDaemon(ctx); err != nil {
+		t.Fatalf("StartDaemon: %v", err)
+	}
+	defer m.StopDaemon(context.Background())
+
+	data, err := os.ReadFile(launches)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != repo {
+		t.Errorf("daemon started with IPFS_PATH %q, want %q", got, repo)
+	}
+	cfg, err := readRepoConfig(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(cfg["Addresses"]), "/tcp/") {
+		t.Errorf("API address not set in the custom repo config: %s", cfg["Addresses"])
+	}
+	if _, err := os.Stat(filepath.Join(m.dataDir, ".ipfs")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("default repo under the data dir was used despite RepoPath: %v", err)
+	}
+}
+
+func TestRepoOperationsSerialized(t *testing.T) {
+	dir := t.TempDir()
+	lock := filepath.Join(dir, "cli.lock")
+	overlaps := filepath.Join(dir, "overlaps")
+	// Every invocation holds a lock directory for a moment and records when it finds it already held.
+	script := filepath.Join(dir, "ipfs")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+held=0
+mkdir "`+lock+`" 2>/dev/null && held=1 || echo "$*" >> "`+overlaps+`"
+sleep 0.02
+case "$1" in
+version) echo 0.32.1 ;;
+esac
+[ $held = 1 ] && rmdir "`+lock+`"
+exit 0
+`), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewIPFSManager(ManagerConfig{
+		BinaryPath:          script,
+		DataDir:             dir,
+		ExpectedKuboVersion: "v0.32.1",
+		Logger:              slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	writeRepo(t, m.repoPath)
+	ctx := context.Background()
+	peers := []string{"/ip4/203.0.113.7/tcp/4001/p2p/12D3KooWGRUVh7Yd5u7WqBNanC5nn5Kq5WGhA7ZnbKrh6ExtEkbR"}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 12)
+	for range 4 {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			errs <- m.EnsureInstalled(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			errs <- m.InitializeRepo(ctx)
+		}()
+		go func() {
+			defer wg.Done()
+			errs <- m.ConfigurePrivateNetwork(ctx, "", peers)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, err := os.ReadFile(overlaps); err == nil {
+		t.Fatalf("ipfs CLI invocations overlapped:\n%s", data)
+	}
+}
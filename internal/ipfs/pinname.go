@@ -0,0 +1,77 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// PinNamed pins cid under name (pin/add?name=, kubo 0.26 and later), so the daemon's pinset records why
+// the content is pinned. Daemons that reject the name option are remembered and pinned without a name;
+// see PinNamesSupported.
+func (c *Client) PinNamed(ctx context.Context, cid, name string) error {
+	if name == "" || c.noPinNames.Load() {
+		return c.pinAdd(ctx, cid, "")
+	}
+	err := c.pinAdd(ctx, cid, name)
+	if err == nil || !unknownNameOption(err) {
+		return err
+	}
+	c.noPinNames.Store(true)
+	return c.pinAdd(ctx, cid, "")
+}
+
+// PinNamesSupported reports whether the daemon has accepted (or not yet rejected) named pins.
+func (c *Client) PinNamesSupported() bool {
+	return !c.noPinNames.Load()
+}
+
+// unknownNameOption reports whether err is the daemon rejecting pin/add's name option, as versions
+// without named pins do.
+func unknownNameOption(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "name") && (strings.Contains(msg, "unknown option") || strings.Contains(msg, "unrecognized option"))
+}
+
+// PinName returns the name of cid's recursive pin, as listed by pin/ls?names=true. It is empty for
+// unnamed pins and on daemons without named pins.
+func (c *Client) PinName(ctx context.Context, cid string) (string, error) {
+	url := fmt.Sprintf("%s/api/v0/pin/ls?arg=%s&type=recursive&names=true", c.apiURL, neturl.QueryEscape(cid))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := statusError("pin ls", resp)
+		if unknownNameOption(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	var result struct {
+		Keys map[string]struct {
+			Name string `json:"Name"`
+		} `json:"Keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	for _, key := range result.Keys {
+		return key.Name, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// newNamedPinServer serves pin/add and pin/ls like kubo, recording pin names when namesSupported and
+// rejecting the name option like older versions otherwise.
+func newNamedPinServer(t *testing.T, namesSupported bool) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	pins := make(map[string]string)
+	unknownName := func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(apiError{Message: `unknown option "name"`, Type: "error"})
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v0/pin/add", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Has("name") && !namesSupported {
+			unknownName(w)
+			return
+		}
+		mu.Lock()
+		pins[q.Get("arg")] = q.Get("name")
+		mu.Unlock()
+		json.NewEncoder(w).Encode(map[string][]string{"Pins": {q.Get("arg")}})
+	})
+	mux.HandleFunc("POST /api/v0/pin/ls", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Has("names") && !namesSupported {
+			unknownName(w)
+			return
+		}
+		mu.Lock()
+		name, ok := pins[q.Get("arg")]
+		mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(apiError{Message: "path is not pinned", Type: "error"})
+			return
+		}
+		type key struct {
+			Type string
+			Name string `json:",omitempty"`
+		}
+		json.NewEncoder(w).Encode(map[string]map[string]key{"Keys": {q.Get("arg"): {Type: "recursive", Name: name}}})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+const namedCID = "bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi"
+
+func TestPinNameRoundTrip(t *testing.T) {
+	c := NewClient(newNamedPinServer(t, true).URL)
+	ctx := context.Background()
+
+	if err := c.PinNamed(ctx, namedCID, "wabisaby-task-42"); err != nil {
+		t.Fatalf("PinNamed = %v", err)
+	}
+	name, err := c.PinName(ctx, namedCID)
+	if err != nil {
+		t.Fatalf("PinName = %v", err)
+	}
+	if name != "wabisaby-task-42" {
+		t.Fatalf("PinName = %q, want %q", name, "wabisaby-task-42")
+	}
+	if !c.PinNamesSupported() {
+		t.Fatal("PinNamesSupported = false after a named pin succeeded")
+	}
+}
+
+func TestPinNameUnsupported(t *testing.T) {
+	c := NewClient(newNamedPinServer(t, false).URL)
+	ctx := context.Background()
+
+	if err := c.PinNamed(ctx, namedCID, "wabisaby-task-42"); err != nil {
+		t.Fatalf("PinNamed = %v, want the pin to fall back to no name", err)
+	}
+	if c.PinNamesSupported() {
+		t.Fatal("PinNamesSupported = true after the daemon rejected the name option")
+	}
+	name, err := c.PinName(ctx, namedCID)
+	if err != nil || name != "" {
+		t.Fatalf("PinName = %q, %v; want an empty name and no error", name, err)
+	}
+	if pinned, err := c.IsPinned(ctx, namedCID); err != nil || !pinned {
+		t.Fatalf("IsPinned = %v, %v after the unnamed fallback pin", pinned, err)
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrAPIPortInUse is returned (wrapped) when the IPFS daemon cannot be started because another process
+// is already listening on the API address.
+var ErrAPIPortInUse = errors.New("IPFS API address already in use")
+
+// portProbeTimeout bounds the connection attempt used to check whether the API port is taken.
+const portProbeTimeout = time.Second
+
+// apiHostPort returns the host:port apiURL listens on, defaulting to port 5001.
+func apiHostPort(apiURL string) (string, error) {
+	u, err := neturl.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid API URL %q", apiURL)
+	}
+	port := u.Port()
+	if port == "" {
+		port = defaultAPIPort
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// portInUse reports whether something accepts TCP connections on addr.
+func portInUse(ctx context.Context, addr string) bool {
+	dialer := net.Dialer{Timeout: portProbeTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false
+	}
+	_ = conn.Close()
+	return true
+}
+
+// checkAPIPort reports whether the API address is free for a new daemon. If it is taken, existing is
+// true when an IPFS daemon already serves the RPC API there (e.g. a stale daemon from an earlier run),
+// and err wraps ErrAPIPortInUse with a message telling the two cases apart.
+func (m *IPFSManager) checkAPIPort(ctx context.Context) (existing bool, err error) {
+	addr, err := apiHostPort(m.apiURL)
+	if err != nil {
+		return false, err
+	}
+	if !portInUse(ctx, addr) {
+		return false, nil
+	}
+	if NewClient(m.apiURL, m.clientOpts...).CheckAPI(ctx) == nil {
+		return true, fmt.Errorf("%w: an IPFS daemon is already serving the API at %s, likely left over from an earlier run; stop it, or set ipfs.adopt_daemon to use it",
+			ErrAPIPortInUse, addr)
+	}
+	return false, fmt.Errorf("%w: another process is listening on %s; stop it or point ipfs.api_url at a free port",
+		ErrAPIPortInUse, addr)
+}
+
+// maxDaemonOutput is how much of the daemon's most recent output is kept for error messages.
+const maxDaemonOutput = 4096
+
+// outputTail is an io.Writer keeping the last maxDaemonOutput bytes written to it.
+type outputTail struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (t *outputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - maxDaemonOutput; over > 0 {
+		t.buf = append(t.buf[:0], t.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the kept output with surrounding whitespace trimmed.
+func (t *outputTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(bytes.TrimSpace(t.buf))
+}
+
+// bindFailed reports whether the daemon output shows it could not bind one of its addresses.
+func (t *outputTail) bindFailed() bool {
+	return strings.Contains(strings.ToLower(t.String()), "address already in use")
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// occupyAPIAddr listens on m's API address and serves handler there, or accepts and drops connections
+// when handler is nil.
+func occupyAPIAddr(t *testing.T, m *IPFSManager, handler http.Handler) {
+	t.Helper()
+	ln, err := net.Listen("tcp", strings.TrimPrefix(m.apiURL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	if handler != nil {
+		go http.Serve(ln, handler)
+		return
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+}
+
+// versionHandler answers the version command like an IPFS daemon.
+func versionHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v0/version", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"Version":"0.32.1"}`)
+	})
+	return mux
+}
+
+func TestStartDaemonPortTakenByOtherProcess(t *testing.T) {
+	m, launches := newFakeDaemonManager(t)
+	occupyAPIAddr(t, m, nil)
+
+	err := m.StartDaemon(context.Background())
+	if !errors.Is(err, ErrAPIPortInUse) || !strings.Contains(err.Error(), "another process") {
+		t.Fatalf("StartDaemon = %v, want ErrAPIPortInUse naming another process", err)
+	}
+	if _, err := os.Stat(launches); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("daemon launched although the API port is taken")
+	}
+}
+
+func TestStartDaemonPortTakenByIPFS(t *testing.T) {
+	m, launches := newFakeDaemonManager(t)
+	occupyAPIAddr(t, m, versionHandler())
+
+	err := m.StartDaemon(context.Background())
+	if !errors.Is(err, ErrAPIPortInUse) || !strings.Contains(err.Error(), "adopt_daemon") {
+		t.Fatalf("StartDaemon = %v, want ErrAPIPortInUse suggesting adopt_daemon", err)
+	}
+	if _, err := os.Stat(launches); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("daemon launched although an IPFS daemon serves the API")
+	}
+}
+
+func TestStartDaemonAdoptsExistingDaemon(t *testing.T) {
+	m, launches := newFakeDaemonManager(t)
+	m.adopt = true
+	occupyAPIAddr(t, m, versionHandler())
+
+	if err := m.StartDaemon(context.Background()); err != nil {
+		t.Fatalf("StartDaemon: %v", err)
+	}
+	if !m.IsReady() {
+		t.Fatal("adopted daemon not ready")
+	}
+	if _, err := os.Stat(launches); !errors.Is(err, os.ErrNotExist) {
+		t.Fatal("daemon launched although an existing one was adopted")
+	}
+}
+
+func TestStartDaemonBindFailure(t *testing.T) {
+	m, _ := newFakeDaemonManager(t)
+	// The API port is free, but the daemon cannot bind its other address (as with a taken swarm port).
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer taken.Close()
+	t.Setenv(fakeDaemonAddrEnv, taken.Addr().String())
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = m.StartDaemon(ctx)
+	if !errors.Is(err, ErrAPIPortInUse) || !strings.Contains(err.Error(), "could not bind") {
+		t.Fatalf("StartDaemon = %v, want ErrAPIPortInUse from the daemon output", err)
+	}
+}
+
+func TestOutputTailBindFailed(t *testing.T) {
+	var tail outputTail
+	fmt.Fprintln(&tail, "Initializing daemon...")
+	if tail.bindFailed() {
+		t.Fatal("bindFailed without a bind error")
+	}
+	fmt.Fprintln(&tail, "Error: serveHTTPApi: manet.Listen(/ip4/127.0.0.1/tcp/5001) failed: listen tcp4 127.0.0.1:5001: bind: Address already in use")
+	if !tail.bindFailed() {
+		t.Fatal("bindFailed missed an address already in use error")
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrPartialRepo is returned (wrapped) when the IPFS repo exists but lacks parts of an initialized repo,
+// typically because an earlier init was interrupted.
+var ErrPartialRepo = errors.New("IPFS repository is partially initialized")
+
+// datastoreSpec is the part of a repo's datastore_spec naming the directories its datastores live in.
+type datastoreSpec struct {
+	Path   string          `json:"path"`
+	Mounts []datastoreSpec `json:"mounts"`
+	Child  *datastoreSpec  `json:"child"`
+}
+
+// paths returns the datastore directories named by the spec, relative to the repo.
+func (s datastoreSpec) paths() []string {
+	var paths []string
+	if s.Path != "" {
+		paths = append(paths, s.Path)
+	}
+	for _, mount := range s.Mounts {
+		paths = append(paths, mount.paths()...)
+	}
+	if s.Child != nil {
+		paths = append(paths, s.Child.paths()...)
+	}
+	return paths
+}
+
+// checkRepo returns an error wrapping ErrPartialRepo that names what is missing if repoPath does not
+// hold a complete kubo repo: the config, version and datastore_spec files, the keystore directory and
+// every datastore directory named by the spec.
+func checkRepo(repoPath string) error {
+	var missing []string
+	for _, name := range []string{"config", "version", "datastore_spec"} {
+		if info, err := os.Stat(filepath.Join(repoPath, name)); err != nil || info.IsDir() {
+			missing = append(missing, name)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(repoPath, "keystore")); err != nil || !info.IsDir() {
+		missing = append(missing, "keystore/")
+	}
+	if data, err := os.ReadFile(filepath.Join(repoPath, "datastore_spec")); err == nil {
+		var spec datastoreSpec
+		if err := json.Unmarshal(data, &spec); err != nil {
+			missing = append(missing, "valid datastore_spec")
+		}
+		for _, dir := range spec.paths() {
+			if info, err := os.Stat(filepath.Join(repoPath, dir)); err != nil || !info.IsDir() {
+				missing = append(missing, dir+"/")
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s is missing %s", ErrPartialRepo, repoPath, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// setAsidePartialRepo renames a partial repo out of the way so it can be reinitialized, keeping its
+// contents for inspection. It returns the new location.
+func setAsidePartialRepo(repoPath string) (string, error) {
+	aside := fmt.Sprintf("%s.partial-%s", repoPath, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.Rename(repoPath, aside); err != nil {
+		return "", fmt.Errorf("move partial IPFS repository aside: %w", err)
+	}
+	return aside, nil
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testDatastoreSpec = `{"mounts":[{"child":{"path":"blocks","type":"flatfs"},"mountpoint":"/blocks"},{"child":{"path":"datastore","type":"levelds"},"mountpoint":"/"}],"type":"mount"}`
+
+// writeRepo creates a complete kubo repo layout at repoPath.
+func writeRepo(t *testing.T, repoPath string) {
+	t.Helper()
+	for _, dir := range []string{"keystore", "blocks", "datastore"} {
+		if err := os.MkdirAll(filepath.Join(repoPath, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{"config": "{}", "version": "16", "datastore_spec": testDatastoreSpec} {
+		if err := os.WriteFile(filepath.Join(repoPath, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCheckRepo(t *testing.T) {
+	tests := []struct {
+		name    string
+		remove  string
+		missing string
+	}{
+		{"complete", "", ""},
+		{"no version", "version", "version"},
+		{"no datastore spec", "datastore_spec", "datastore_spec"},
+		{"no keystore", "keystore", "keystore/"},
+		{"no datastore", "datastore", "datastore/"},
+		{"no blockstore", "blocks", "blocks/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := filepath.Join(t.TempDir(), ".ipfs")
+			writeRepo(t, repo)
+			if tt.remove != "" {
+				if err := os.RemoveAll(filepath.Join(repo, tt.remove)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := checkRepo(repo)
+			if tt.missing == "" {
+				if err != nil {
+					t.Fatalf("checkRepo = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrPartialRepo) || !strings.Contains(err.Error(), tt.missing) {
+				t.Fatalf("checkRepo = %v, want ErrPartialRepo naming %s", err, tt.missing)
+			}
+		})
+	}
+}
+
+// newInitManager returns a manager whose ipfs binary is a script that writes a complete repo on init.
+func newInitManager(t *testing.T, repair bool) *IPFSManager {
+	t.Helper()
+	dir := t.TempDir()
+	script := filepath.Join(dir, "ipfs")
+	err := os.WriteFile(script, []byte(`#!/bin/sh
+mkdir -p "$IPFS_PATH/keystore" "$IPFS_PATH/blocks" "$IPFS_PATH/datastore"
+echo '{}' > "$IPFS_PATH/config"
+echo 16 > "$IPFS_PATH/version"
+echo '`+testDatastoreSpec+`' > "$IPFS_PATH/datastore_spec"
+`), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewIPFSManager(ManagerConfig{
+		BinaryPath: script,
+		DataDir:    dir,
+		RepairRepo: repair,
+		Logger:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+}
+
+// writeHalfRepo leaves the repo of m as an interrupted init would: a config and nothing else.
+func writeHalfRepo(t *testing.T, m *IPFSManager) {
+	t.Helper()
+	if err := os.MkdirAll(m.repoPath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(m.repoPath, "config"), []byte("{}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInitializeRepoPartial(t *testing.T) {
+	m := newInitManager(t, false)
+	writeHalfRepo(t, m)
+
+	err := m.InitializeRepo(context.Background())
+	if !errors.Is(err, ErrPartialRepo) {
+		t.Fatalf("InitializeRepo = %v, want ErrPartialRepo", err)
+	}
+	if !strings.Contains(err.Error(), "ipfs.repair_repo") {
+		t.Errorf("error %q does not point at ipfs.repair_repo", err)
+	}
+	if _, err := os.Stat(filepath.Join(m.repoPath, "version")); err == nil {
+		t.Error("partial repo was reinitialized without ipfs.repair_repo")
+	}
+}
+
+func TestInitializeRepoRepairsPartial(t *testing.T) {
+	m := newInitManager(t, true)
+	writeHalfRepo(t, m)
+
+	if err := m.InitializeRepo(context.Background()); err != nil {
+		t.Fatalf("InitializeRepo = %v", err)
+	}
+	if err := checkRepo(m.repoPath); err != nil {
+		t.Fatalf("repo after repair: %v", err)
+	}
+	aside, _ := filepath.Glob(m.repoPath + ".partial-*")
+	if len(aside) != 1 {
+		t.Fatalf("partial repo set aside at %v, want one location", aside)
+	}
+	if _, err := os.Stat(filepath.Join(aside[0], "config")); err != nil {
+		t.Errorf("set-aside repo lost its contents: %v", err)
+	}
+}
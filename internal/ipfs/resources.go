@@ -0,0 +1,119 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ErrResourceManagerUnavailable is returned when the daemon does not expose resource manager stats,
+// either because kubo predates swarm/resources or because the resource manager is disabled.
+var ErrResourceManagerUnavailable = errors.New("IPFS resource manager stats unavailable")
+
+// resourceKinds are the libp2p resource manager resources reported per scope; each has a limit field
+// named after the resource and a usage field with a "Usage" suffix.
+var resourceKinds = []string{
+	"Memory", "FD", "Conns", "ConnsInbound", "ConnsOutbound", "Streams", "StreamsInbound", "StreamsOutbound",
+}
+
+// ResourceLimit is the usage of one resource in one resource manager scope that has a finite limit.
+type ResourceLimit struct {
+	Scope    string // e.g. "System", "Transient", "Protocols./ipfs/bitswap/1.2.0"
+	Resource string // e.g. "Conns", "StreamsInbound", "Memory"
+	Used     int64
+	Limit    int64
+}
+
+// Exceeded reports whether the resource is at or above its limit, in which case the resource manager
+// rejects further allocations in that scope.
+func (r ResourceLimit) Exceeded() bool { return r.Limit > 0 && r.Used >= r.Limit }
+
+func (r ResourceLimit) String() string {
+	return fmt.Sprintf("%s.%s (%d/%d)", r.Scope, r.Resource, r.Used, r.Limit)
+}
+
+// SwarmResources returns the usage of every finitely limited resource in the daemon's resource manager
+// scopes, via /api/v0/swarm/resources. It returns ErrResourceManagerUnavailable when the endpoint is
+// missing or the resource manager is disabled.
+func (c *Client) SwarmResources(ctx context.Context) ([]ResourceLimit, error) {
+	url := fmt.Sprintf("%s/api/v0/swarm/resources", c.apiURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrResourceManagerUnavailable
+	}
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		// kubo answers 500 when the command is unknown or the resource manager is disabled.
+		if body := strings.ToLower(string(bodyBytes)); strings.Contains(body, "resourcemgr") || strings.Contains(body, "unknown command") {
+			return nil, ErrResourceManagerUnavailable
+		}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return nil, fmt.Errorf("IPFS swarm resources failed with status %d: %w", resp.StatusCode, ErrUnauthorized)
+		}
+		return nil, fmt.Errorf("IPFS swarm resources failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var scopes map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	var limits []ResourceLimit
+	for name, raw := range scopes {
+		switch name {
+		case "System", "Transient":
+			limits = append(limits, scopeLimits(name, raw)...)
+		default:
+			// Services, Protocols and Peers hold one scope per service/protocol/peer.
+			var sub map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &sub); err != nil {
+				continue
+			}
+			for subName, subRaw := range sub {
+				limits = append(limits, scopeLimits(name+"."+subName, subRaw)...)
+			}
+		}
+	}
+	sort.Slice(limits, func(i, j int) bool {
+		if limits[i].Scope != limits[j].Scope {
+			return limits[i].Scope < limits[j].Scope
+		}
+		return limits[i].Resource < limits[j].Resource
+	})
+	return limits, nil
+}
+
+// scopeLimits extracts the finitely limited resources of a single scope. Limits reported as
+// "unlimited", "default" or "blockAll" are skipped.
+func scopeLimits(scope string, raw json.RawMessage) []ResourceLimit {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+	var limits []ResourceLimit
+	for _, kind := range resourceKinds {
+		var limit, used int64
+		if json.Unmarshal(fields[kind], &limit) != nil || json.Unmarshal(fields[kind+"Usage"], &used) != nil {
+			continue
+		}
+		limits = append(limits, ResourceLimit{Scope: scope, Resource: kind, Used: used, Limit: limit})
+	}
+	return limits
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wabisaby/wabisaby-node/internal/fsutil"
+)
+
+// Serve priority presets, trading content ingest (pinning) against serving blocks to other peers.
+const (
+	ServePriorityBalanced = "balanced" // kubo defaults
+	ServePriorityServe    = "serve"    // More bitswap serving workers and per-peer send budget, more connections kept
+	ServePriorityIngest   = "ingest"   // Fewer serving workers and a smaller send budget, leaving bandwidth for pins
+)
+
+// servePriorityPresets holds the kubo config each preset applies. Numbers are float64 so they compare
+// equal to values decoded from the existing config.
+var servePriorityPresets = map[string]map[string]interface{}{
+	ServePriorityBalanced: servePriorityConfig(8, 8, 128, 1<<20, 32, 96),
+	ServePriorityServe:    servePriorityConfig(16, 16, 256, 4<<20, 100, 300),
+	ServePriorityIngest:   servePriorityConfig(4, 4, 64, 256<<10, 32, 96),
+}
+
+// servePriorityConfig builds the config fragment for a preset: bitswap serving workers, the bytes
+// queued to a single peer at a time, and the connection manager watermarks.
+func servePriorityConfig(taskWorkers, engineTaskWorkers, blockstoreWorkers, perPeerBytes, lowWater, highWater float64) map[string]interface{} {
+	return map[string]interface{}{
+		"Internal": map[string]interface{}{
+			"Bitswap": map[string]interface{}{
+				"TaskWorkerCount":             taskWorkers,
+				"EngineTaskWorkerCount":       engineTaskWorkers,
+				"EngineBlockstoreWorkerCount": blockstoreWorkers,
+				"MaxOutstandingBytesPerPeer":  perPeerBytes,
+			},
+		},
+		"Swarm": map[string]interface{}{
+			"ConnMgr": map[string]interface{}{
+				"LowWater":  lowWater,
+				"HighWater": highWater,
+			},
+		},
+	}
+}
+
+// ValidateServePriority returns an error unless priority is empty (leave the config untouched) or a
+// known preset.
+func ValidateServePriority(priority string) error {
+	if priority == "" {
+		return nil
+	}
+	if _, ok := servePriorityPresets[priority]; !ok {
+		names := make([]string, 0, len(servePriorityPresets))
+		for name := range servePriorityPresets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown serve priority %q: must be one of %s", priority, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// ApplyServePriority writes the preset for priority into the IPFS config and returns the dotted paths of
+// the values that changed (in which case a running daemon must be restarted to pick them up). An empty
+// priority changes nothing.
+func ApplyServePriority(repoPath, priority string) ([]string, error) {
+	if priority == "" {
+		return nil, nil
+	}
+	if err := ValidateServePriority(priority); err != nil {
+		return nil, err
+	}
+
+	configPath := filepath.Join(repoPath, "config")
+	configData, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read IPFS config: %w", err)
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(configData, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse IPFS config: %w", err)
+	}
+
+	changed := MergeConfig(config, servePriorityPresets[priority])
+	if len(changed) == 0 {
+		return nil, nil
+	}
+
+	updatedConfig, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal IPFS config: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(configPath, updatedConfig, 0o644); err != nil {
+		return nil, fmt.Errorf("failed to write IPFS config: %w", err)
+	}
+	return changed, nil
+}
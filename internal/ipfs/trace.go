@@ -0,0 +1,53 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"log/slog"
+	"net/http"
+	neturl "net/url"
+	"time"
+)
+
+// tracingTransport logs every IPFS API request at debug level with its method, URL, status code and
+// duration. Request and response bodies are never logged.
+type tracingTransport struct {
+	next       http.RoundTripper
+	logger     *slog.Logger
+	redactArgs bool
+}
+
+// RoundTrip performs the request and logs it. The duration covers the time until the response headers
+// arrive, which for most kubo commands (including pin/add) is when the operation completes.
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !t.logger.Enabled(req.Context(), slog.LevelDebug) {
+		return t.next.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	attrs := []any{"method", req.Method, "url", t.traceURL(req.URL), "duration", time.Since(start)}
+	if err != nil {
+		t.logger.Debug("IPFS API request failed", append(attrs, "error", err)...)
+		return resp, err
+	}
+	t.logger.Debug("IPFS API request", append(attrs, "status", resp.StatusCode)...)
+	return resp, nil
+}
+
+// traceURL returns u with any password removed and, when configured, the "arg" query values (CIDs,
+// multiaddrs, config values) replaced.
+func (t *tracingTransport) traceURL(u *neturl.URL) string {
+	if !t.redactArgs {
+		return u.Redacted()
+	}
+	redacted := *u
+	q := redacted.Query()
+	if args, ok := q["arg"]; ok {
+		for i := range args {
+			args[i] = "REDACTED"
+		}
+		redacted.RawQuery = q.Encode()
+	}
+	return redacted.Redacted()
+}
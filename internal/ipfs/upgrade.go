@@ -0,0 +1,225 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// UpgradeOptions describes a kubo binary upgrade.
+type UpgradeOptions struct {
+	NewBinary       string // Path to the new kubo (ipfs) binary
+	SHA256          string // Expected hex SHA-256 checksum of NewBinary
+	ExpectedVersion string // Optional version the new binary must report (ipfs version --number), e.g. "0.30.0"
+}
+
+// Upgrade replaces the installed kubo binary with opts.NewBinary without losing the pinset. It must run
+// while the node is stopped (the node's shutdown drains in-flight pins); it refuses to run while a daemon
+// answers on the API. The steps are:
+//
+//  1. verify the new binary's checksum and version
+//  2. count the recursive pins with the old binary
+//  3. back up the old binary and install the new one in its place
+//  4. run the repo migration with the new binary
+//  5. start the new daemon, wait until it is ready and check that no pins were lost
+//  6. stop the daemon again so the node can be restarted
+//
+// If any step after installing fails, the old binary is restored. A repo migration cannot be undone
+// automatically; that case is logged with the repo versions involved.
+func (m *IPFSManager) Upgrade(ctx context.Context, opts UpgradeOptions) (err error) {
+	current := m.binaryPath
+	if current == "" {
+		if current, err = exec.LookPath("ipfs"); err != nil {
+			return fmt.Errorf("no installed IPFS binary to upgrade: %w", err)
+		}
+	}
+	if current, err = filepath.EvalSymlinks(current); err != nil {
+		return fmt.Errorf("resolve installed IPFS binary: %w", err)
+	}
+
+	m.logger.Info("upgrade: verifying new binary", "binary", opts.NewBinary)
+	if err := verifyChecksum(opts.NewBinary, opts.SHA256); err != nil {
+		return err
+	}
+	newVersion, err := binaryVersion(ctx, opts.NewBinary)
+	if err != nil {
+		return fmt.Errorf("new binary is not a working kubo binary: %w", err)
+	}
+	if opts.ExpectedVersion != "" && strings.TrimPrefix(opts.ExpectedVersion, "v") != newVersion {
+		return fmt.Errorf("new binary reports version %s, expected %s", newVersion, opts.ExpectedVersion)
+	}
+	oldVersion, err := binaryVersion(ctx, current)
+	if err != nil {
+		return fmt.Errorf("installed binary %s: %w", current, err)
+	}
+	m.logger.Info("upgrade: versions", "installed", oldVersion, "new", newVersion, "path", current)
+
+	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	_, pingErr := m.client().Version(pingCtx)
+	cancel()
+	if pingErr == nil {
+		return fmt.Errorf("an IPFS daemon is running at %s; stop the node before upgrading", m.apiURL)
+	}
+
+	m.logger.Info("upgrade: counting pins with the installed binary")
+	pinsBefore, err := m.countPins(ctx, current)
+	if err != nil {
+		return fmt.Errorf("count pins before upgrade: %w", err)
+	}
+	repoVersionBefore := m.repoVersion()
+
+	backup := current + ".bak"
+	m.logger.Info("upgrade: backing up installed binary", "backup", backup)
+	if err := installBinary(current, backup); err != nil {
+		return fmt.Errorf("back up installed binary: %w", err)
+	}
+	m.logger.Info("upgrade: installing new binary", "path", current)
+	if err := installBinary(opts.NewBinary, current); err != nil {
+		return fmt.Errorf("install new binary: %w", err)
+	}
+	m.repoMu.Lock()
+	m.binaryPath = current
+	m.repoMu.Unlock()
+
+	defer func() {
+		if err == nil {
+			return
+		}
+		m.logger.Error("upgrade: failed, restoring previous binary", "error", err, "backup", backup)
+		if restoreErr := installBinary(backup, current); restoreErr != nil {
+			m.logger.Error("upgrade: failed to restore previous binary; restore it manually", "backup", backup, "error", restoreErr)
+			return
+		}
+		if after := m.repoVersion(); after != repoVersionBefore {
+			m.logger.Error("upgrade: the repo was migrated and must be reverted manually before the previous binary can use it",
+				"repo_version_before", repoVersionBefore, "repo_version_now", after)
+		}
+	}()
+
+	m.logger.Info("upgrade: running repo migration")
+	m.repoMu.Lock()
+	out, err := m.runIPFS(ctx, current, "repo", "migrate")
+	m.repoMu.Unlock()
+	if err != nil {
+		return fmt.Errorf("repo migration failed: %w: %s", err, strings.TrimSpace(out))
+	}
+
+	m.logger.Info("upgrade: starting new daemon")
+	if err := m.StartDaemon(ctx); err != nil {
+		return fmt.Errorf("new daemon did not become ready: %w", err)
+	}
+	pinsAfter, countErr := m.countPins(ctx, current)
+	m.logger.Info("upgrade: stopping daemon")
+	if stopErr := m.StopDaemon(ctx); stopErr != nil {
+		m.logger.Warn("upgrade: failed to stop daemon", "error", stopErr)
+	}
+	if countErr != nil {
+		return fmt.Errorf("count pins after upgrade: %w", countErr)
+	}
+	if pinsAfter < pinsBefore {
+		return fmt.Errorf("pinset shrank during upgrade: %d recursive pins before, %d after", pinsBefore, pinsAfter)
+	}
+
+	m.logger.Info("upgrade: completed", "version", newVersion, "pins", pinsAfter, "backup", backup)
+	return nil
+}
+
+// runIPFS runs the kubo binary against the managed repo and returns its combined output.
+func (m *IPFSManager) runIPFS(ctx context.Context, binary string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, binary, args...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("IPFS_PATH=%s", m.repoPath))
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.String(), err
+}
+
+// countPins returns the number of recursive pins in the repo. It works with or without a running daemon.
+func (m *IPFSManager) countPins(ctx context.Context, binary string) (int, error) {
+	out, err := m.runIPFS(ctx, binary, "pin", "ls", "--type=recursive", "--quiet")
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", err, strings.TrimSpace(out))
+	}
+	return len(strings.Fields(out)), nil
+}
+
+// repoVersion returns the contents of the repo's version file, or "" if it cannot be read.
+func (m *IPFSManager) repoVersion() string {
+	data, err := os.ReadFile(filepath.Join(m.repoPath, "version"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// binaryVersion returns the version reported by `<binary> version --number`.
+func binaryVersion(ctx context.Context, binary string) (string, error) {
+	out, err := exec.CommandContext(ctx, binary, "version", "--number").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// verifyChecksum checks that the file at path has the given hex SHA-256 checksum.
+func verifyChecksum(path, want string) error {
+	if want == "" {
+		return fmt.Errorf("a SHA-256 checksum of the new binary is required")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open new binary: %w", err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("read new binary: %w", err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, strings.TrimSpace(want)) {
+		return fmt.Errorf("checksum mismatch for %s: got %s, expected %s", path, got, want)
+	}
+	return nil
+}
+
+// installBinary copies src to dst as an executable, replacing dst atomically.
+func installBinary(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return writeExecutable(in, dst)
+}
+
+// writeExecutable writes r to dst as an executable, replacing dst atomically.
+func writeExecutable(r io.Reader, dst string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), "."+filepath.Base(dst)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o755); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
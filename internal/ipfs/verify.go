@@ -0,0 +1,75 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package ipfs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// ErrContentIncomplete is returned (wrapped) by VerifyPin when a block of the DAG is missing from the
+// local blockstore or cannot be read.
+var ErrContentIncomplete = errors.New("IPFS content not fully present locally")
+
+// VerifyPin checks that the whole DAG rooted at cid is present in the local blockstore, by listing its
+// references recursively with networking disabled: a pin/add that returned 200 does not prove every block
+// was stored, and blocks can later go missing or be corrupted. It returns the number of blocks
+// checked; the error wraps ErrContentIncomplete when a block is missing or unreadable.
+func (c *Client) VerifyPin(ctx context.Context, cid string) (int, error) {
+	url := fmt.Sprintf("%s/api/v0/refs?arg=%s&recursive=true&unique=true&offline=true", c.apiURL, neturl.QueryEscape(cid))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// The root block itself is missing.
+		return 0, missingBlockError(statusError("refs", resp))
+	}
+
+	blocks := 1 // The root
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var ref struct {
+			Ref string `json:"Ref"`
+			Err string `json:"Err"`
+		}
+		if err := dec.Decode(&ref); err == io.EOF {
+			break
+		} else if err != nil {
+			return blocks, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if ref.Err != "" {
+			return blocks, fmt.Errorf("IPFS refs of %s: %s: %w", cid, ref.Err, ErrContentIncomplete)
+		}
+		blocks++
+	}
+	// kubo reports errors that occur mid-stream in a trailer.
+	if streamErr := resp.Trailer.Get("X-Stream-Error"); streamErr != "" {
+		return blocks, missingBlockError(fmt.Errorf("IPFS refs of %s failed: %s", cid, streamErr))
+	}
+	return blocks, nil
+}
+
+// missingBlockError wraps err with ErrContentIncomplete when its message reports a block that is not
+// available offline; other errors (API unreachable, bad credentials) say nothing about the content.
+func missingBlockError(err error) error {
+	msg := err.Error()
+	if strings.Contains(msg, "not found") || strings.Contains(msg, "offline") {
+		return fmt.Errorf("%w: %w", err, ErrContentIncomplete)
+	}
+	return err
+}
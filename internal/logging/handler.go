@@ -0,0 +1,57 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package logging provides the node's slog handler, which stamps identifying attributes on every log
+// line, including the coordinator-assigned node ID once it is known.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+)
+
+// Handler wraps a slog.Handler and adds a node_id attribute to every record once SetNodeID has been
+// called. Loggers derived from it (With, WithGroup) share the node ID; under WithGroup it is nested in
+// the group like any other record attribute.
+type Handler struct {
+	inner  slog.Handler
+	nodeID *atomic.Pointer[string]
+}
+
+// NewHandler returns a Handler wrapping inner.
+func NewHandler(inner slog.Handler) *Handler {
+	return &Handler{inner: inner, nodeID: new(atomic.Pointer[string])}
+}
+
+// Enabled implements slog.Handler.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.inner.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if id := h.nodeID.Load(); id != nil {
+		r = r.Clone()
+		r.AddAttrs(slog.String("node_id", *id))
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+// WithAttrs implements slog.Handler.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{inner: h.inner.WithAttrs(attrs), nodeID: h.nodeID}
+}
+
+// WithGroup implements slog.Handler.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{inner: h.inner.WithGroup(name), nodeID: h.nodeID}
+}
+
+// SetNodeID makes every subsequent record logged through logger, and through every logger sharing its
+// Handler, carry node_id. It has no effect if logger does not use a Handler.
+func SetNodeID(logger *slog.Logger, nodeID string) {
+	if h, ok := logger.Handler().(*Handler); ok && nodeID != "" {
+		h.nodeID.Store(&nodeID)
+	}
+}
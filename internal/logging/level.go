@@ -0,0 +1,42 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// LevelTrace is below slog.LevelDebug, for very verbose output such as per-request tracing.
+const LevelTrace = slog.LevelDebug - 4
+
+// ParseLevel maps a log.level value (trace, debug, info, warn or warning, error; case-insensitive) to its
+// slog.Level.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(s) {
+	case "trace":
+		return LevelTrace, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q: must be trace, debug, info, warn or error", s)
+}
+
+// ReplaceLevelName is a slog.HandlerOptions.ReplaceAttr function that names LevelTrace "TRACE" instead
+// of slog's default "DEBUG-4".
+func ReplaceLevelName(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok && level == LevelTrace {
+			a.Value = slog.StringValue("TRACE")
+		}
+	}
+	return a
+}
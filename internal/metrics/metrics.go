@@ -0,0 +1,162 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package metrics exports the node's Prometheus metrics. The node metrics are read from the stats
+// collector's snapshot on every scrape, so Prometheus shows the same numbers as every other reader and
+// instrumented code records each event once, in the collector.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/wabisaby/wabisaby-node/internal/stats"
+)
+
+// Source provides the statistics exported on each scrape; *stats.Collector implements it.
+type Source interface {
+	Snapshot() stats.Snapshot
+}
+
+// Metrics holds the node's Prometheus collectors in their own registry.
+type Metrics struct {
+	registry *prometheus.Registry
+}
+
+// New registers an exporter of source's statistics and the Go runtime and process collectors in a new
+// registry.
+func New(source Source) *Metrics {
+	m := &Metrics{registry: prometheus.NewRegistry()}
+	m.registry.MustRegister(
+		newSnapshotCollector(source),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// snapshotCollector is a prometheus.Collector that turns one stats snapshot into the node metrics.
+type snapshotCollector struct {
+	source Source
+
+	pins            *prometheus.Desc
+	heartbeats      *prometheus.Desc
+	tasksReceived   *prometheus.Desc
+	tasksInflight   *prometheus.Desc
+	tasksPaused     *prometheus.Desc
+	repoSize        *prometheus.Desc
+	peers           *prometheus.Desc
+	uptime          *prometheus.Desc
+	lastHeartbeat   *prometheus.Desc
+	timeToFirstTask *prometheus.Desc
+	taskPhase       *prometheus.Desc
+	breaker         *prometheus.Desc
+	rcmgrExceeded   *prometheus.Desc
+	reachability    *prometheus.Desc
+	gateway         *prometheus.Desc
+}
+
+func newSnapshotCollector(source Source) *snapshotCollector {
+	return &snapshotCollector{
+		source: source,
+		pins: prometheus.NewDesc("wabisaby_pins_total",
+			"Pin tasks finished, by reported status (pinned, failed, abandoned).", []string{"status"}, nil),
+		heartbeats: prometheus.NewDesc("wabisaby_heartbeats_total",
+			"Heartbeats sent to the coordinator, by result (ok, error).", []string{"result"}, nil),
+		tasksReceived: prometheus.NewDesc("wabisaby_tasks_received_total",
+			"Pin tasks received from the coordinator.", nil, nil),
+		tasksInflight: prometheus.NewDesc("wabisaby_tasks_inflight",
+			"Pins currently running.", nil, nil),
+		tasksPaused: prometheus.NewDesc("wabisaby_tasks_paused",
+			"1 while task acceptance is paused (e.g. too few swarm peers, IPFS daemon down), 0 otherwise.", nil, nil),
+		repoSize: prometheus.NewDesc("wabisaby_repo_size_bytes",
+			"IPFS repository size from the last repo stat.", nil, nil),
+		peers: prometheus.NewDesc("wabisaby_peers",
+			"Coordinator-provided peers connected at the last peer refresh, by region.", []string{"region"}, nil),
+		uptime: prometheus.NewDesc("wabisaby_uptime_seconds",
+			"Time since the agent started.", nil, nil),
+		lastHeartbeat: prometheus.NewDesc("wabisaby_last_heartbeat_timestamp_seconds",
+			"Unix time of the last successful heartbeat.", nil, nil),
+		timeToFirstTask: prometheus.NewDesc("wabisaby_time_to_first_task_seconds",
+			"Delay between the start of task polling and the first pin task.", nil, nil),
+		taskPhase: prometheus.NewDesc("wabisaby_task_phase_seconds_total",
+			"Cumulative time processed pin tasks spent per phase.", []string{"phase"}, nil),
+		breaker: prometheus.NewDesc("wabisaby_ipfs_breaker_state",
+			"1 for the current circuit breaker state of each IPFS API endpoint.", []string{"endpoint", "state"}, nil),
+		rcmgrExceeded: prometheus.NewDesc("wabisaby_ipfs_resource_limits_exceeded",
+			"IPFS resource manager scopes at their limit.", nil, nil),
+		reachability: prometheus.NewDesc("wabisaby_reachability",
+			"1 for the node's current network reachability (public, private, unknown).", []string{"reachability"}, nil),
+		gateway: prometheus.NewDesc("wabisaby_gateway_status",
+			"1 for the result of the last IPFS gateway self-check.", []string{"status"}, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *snapshotCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.pins, c.heartbeats, c.tasksReceived, c.tasksInflight, c.tasksPaused, c.repoSize, c.peers, c.uptime,
+		c.lastHeartbeat, c.timeToFirstTask, c.taskPhase, c.breaker, c.rcmgrExceeded, c.reachability, c.gateway,
+	} {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector. Statistics that have not been recorded yet (no successful
+// heartbeat, no task, no gateway check) are omitted rather than exported as zero.
+func (c *snapshotCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.source.Snapshot()
+	counter := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v, labels...)
+	}
+	gauge := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v, labels...)
+	}
+
+	for status, n := range s.PinsByStatus {
+		counter(c.pins, float64(n), status)
+	}
+	counter(c.heartbeats, float64(s.HeartbeatsOK), "ok")
+	counter(c.heartbeats, float64(s.HeartbeatsFailed), "error")
+	counter(c.tasksReceived, float64(s.TasksReceived))
+	gauge(c.tasksInflight, float64(s.PinsInFlight))
+	gauge(c.tasksPaused, boolValue(s.TasksPaused))
+	gauge(c.repoSize, float64(s.RepoSizeBytes))
+	for region, n := range s.PeersByRegion {
+		gauge(c.peers, float64(n), region)
+	}
+	gauge(c.uptime, float64(s.UptimeSeconds))
+	if !s.LastHeartbeatAt.IsZero() {
+		gauge(c.lastHeartbeat, float64(s.LastHeartbeatAt.UnixNano())/1e9)
+	}
+	if s.TimeToFirstTaskSeconds > 0 {
+		gauge(c.timeToFirstTask, s.TimeToFirstTaskSeconds)
+	}
+	for phase, seconds := range s.TaskPhaseSeconds {
+		counter(c.taskPhase, seconds, phase)
+	}
+	for endpoint, state := range s.IPFSBreakers {
+		gauge(c.breaker, 1, endpoint, state)
+	}
+	gauge(c.rcmgrExceeded, float64(len(s.ResourceLimitsExceeded)))
+	if s.Reachability != "" {
+		gauge(c.reachability, 1, s.Reachability)
+	}
+	if s.GatewayStatus != "" {
+		gauge(c.gateway, 1, s.GatewayStatus)
+	}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"testing"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/wabisaby/wabisaby-node/internal/stats"
+)
+
+type fakeSource stats.Snapshot
+
+func (f fakeSource) Snapshot() stats.Snapshot { return stats.Snapshot(f) }
+
+func TestMetricsReadFromSnapshot(t *testing.T) {
+	m := New(fakeSource{
+		PinsByStatus:     map[string]uint64{"pinned": 3, "abandoned": 1},
+		HeartbeatsOK:     5,
+		HeartbeatsFailed: 2,
+		PinsInFlight:     4,
+		TasksPaused:      true,
+		PeersByRegion:    map[string]int{"eu-west": 2, "unknown": 1},
+	})
+	families, err := m.registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+
+	want := []struct {
+		name   string
+		labels map[string]string
+		value  float64
+	}{
+		{"wabisaby_pins_total", map[string]string{"status": "pinned"}, 3},
+		{"wabisaby_pins_total", map[string]string{"status": "abandoned"}, 1},
+		{"wabisaby_heartbeats_total", map[string]string{"result": "ok"}, 5},
+		{"wabisaby_heartbeats_total", map[string]string{"result": "error"}, 2},
+		{"wabisaby_tasks_inflight", nil, 4},
+		{"wabisaby_tasks_paused", nil, 1},
+		{"wabisaby_peers", map[string]string{"region": "eu-west"}, 2},
+		{"wabisaby_peers", map[string]string{"region": "unknown"}, 1},
+	}
+	for _, w := range want {
+		got, ok := find(families, w.name, w.labels)
+		if !ok {
+			t.Errorf("%s%v not exported", w.name, w.labels)
+			continue
+		}
+		if got != w.value {
+			t.Errorf("%s%v = %v, want %v", w.name, w.labels, got, w.value)
+		}
+	}
+	if _, ok := find(families, "wabisaby_last_heartbeat_timestamp_seconds", nil); ok {
+		t.Error("last heartbeat timestamp exported before any heartbeat succeeded")
+	}
+}
+
+// find returns the value of the metric named name whose labels include labels.
+func find(families []*dto.MetricFamily, name string, labels map[string]string) (float64, bool) {
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+	metrics:
+		for _, m := range f.GetMetric() {
+			got := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				got[l.GetName()] = l.GetValue()
+			}
+			for k, v := range labels {
+				if got[k] != v {
+					continue metrics
+				}
+			}
+			if m.GetCounter() != nil {
+				return m.GetCounter().GetValue(), true
+			}
+			return m.GetGauge().GetValue(), true
+		}
+	}
+	return 0, false
+}
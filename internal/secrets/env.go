@@ -0,0 +1,24 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// resolveEnv resolves "env://VAR" to the value of the environment variable VAR, which must be set.
+func resolveEnv(_ context.Context, ref *url.URL) (string, error) {
+	name := ref.Host + ref.Path
+	if name == "" {
+		return "", fmt.Errorf("missing variable name (use env://VAR)")
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
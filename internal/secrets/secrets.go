@@ -0,0 +1,91 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package secrets resolves secret references in configuration values, so tokens and keys can be kept
+// out of config files. A reference is a URL whose scheme names the backend, e.g. "env://VAR" or
+// "vault://secret/data/wabisaby#token". Additional backends are added with Register.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Resolver fetches the secret a reference points to. ref is the parsed reference; its scheme has
+// already been matched to the resolver.
+type Resolver interface {
+	Resolve(ctx context.Context, ref *url.URL) (string, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface.
+type ResolverFunc func(ctx context.Context, ref *url.URL) (string, error)
+
+// Resolve calls f.
+func (f ResolverFunc) Resolve(ctx context.Context, ref *url.URL) (string, error) { return f(ctx, ref) }
+
+var (
+	mu        sync.RWMutex
+	resolvers = map[string]Resolver{
+		"env":   ResolverFunc(resolveEnv),
+		"vault": ResolverFunc(resolveVaultFromEnv),
+	}
+)
+
+// Register installs r as the resolver for scheme, replacing any existing one.
+func Register(scheme string, r Resolver) {
+	mu.Lock()
+	defer mu.Unlock()
+	resolvers[strings.ToLower(scheme)] = r
+}
+
+// Schemes returns the registered reference schemes in sorted order.
+func Schemes() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	schemes := make([]string, 0, len(resolvers))
+	for s := range resolvers {
+		schemes = append(schemes, s)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// lookup returns the resolver for value's scheme, if value is a secret reference.
+func lookup(value string) (Resolver, bool) {
+	scheme, _, ok := strings.Cut(value, "://")
+	if !ok {
+		return nil, false
+	}
+	mu.RLock()
+	defer mu.RUnlock()
+	r, ok := resolvers[strings.ToLower(scheme)]
+	return r, ok
+}
+
+// IsReference reports whether value uses a registered secret scheme. Other values, including plain
+// http(s) URLs, are returned unchanged by Resolve.
+func IsReference(value string) bool {
+	_, ok := lookup(value)
+	return ok
+}
+
+// Resolve returns the secret value referenced by value, or value itself if it is not a reference.
+func Resolve(ctx context.Context, value string) (string, error) {
+	r, ok := lookup(value)
+	if !ok {
+		return value, nil
+	}
+	ref, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret reference: %w", err)
+	}
+	secret, err := r.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("resolve %s reference: %w", ref.Scheme, err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultConfig holds the connection settings for HashiCorp Vault.
+type VaultConfig struct {
+	Address   string // Vault server address, e.g. https://vault.example.com:8200
+	Token     string // Vault token used for reads
+	Namespace string // Optional Vault Enterprise namespace
+	Timeout   time.Duration
+}
+
+// VaultConfigFromEnv reads the standard Vault client environment variables (VAULT_ADDR, VAULT_TOKEN,
+// VAULT_NAMESPACE).
+func VaultConfigFromEnv() VaultConfig {
+	return VaultConfig{
+		Address:   os.Getenv("VAULT_ADDR"),
+		Token:     os.Getenv("VAULT_TOKEN"),
+		Namespace: os.Getenv("VAULT_NAMESPACE"),
+		Timeout:   10 * time.Second,
+	}
+}
+
+// VaultResolver resolves "vault://<path>#<field>" references by reading <path> from Vault's HTTP API
+// and returning <field>. Both KV version 1 and version 2 (paths containing "/data/") are supported.
+type VaultResolver struct {
+	cfg        VaultConfig
+	httpClient *http.Client
+}
+
+// NewVaultResolver creates a Vault resolver. The configuration is only checked when a reference is resolved.
+func NewVaultResolver(cfg VaultConfig) *VaultResolver {
+	return &VaultResolver{cfg: cfg, httpClient: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// resolveVaultFromEnv is the default vault:// resolver, configured from the environment at resolution time.
+func resolveVaultFromEnv(ctx context.Context, ref *url.URL) (string, error) {
+	return NewVaultResolver(VaultConfigFromEnv()).Resolve(ctx, ref)
+}
+
+// Resolve reads the referenced secret field from Vault.
+func (v *VaultResolver) Resolve(ctx context.Context, ref *url.URL) (string, error) {
+	if v.cfg.Address == "" || v.cfg.Token == "" {
+		return "", fmt.Errorf("VAULT_ADDR and VAULT_TOKEN must be set to resolve vault:// references")
+	}
+	path := strings.Trim(ref.Host+ref.Path, "/")
+	field := ref.Fragment
+	if path == "" || field == "" {
+		return "", fmt.Errorf("reference must name a path and field (vault://<path>#<field>)")
+	}
+
+	endpoint := strings.TrimRight(v.cfg.Address, "/") + "/v1/" + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.cfg.Token)
+	if v.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.Namespace)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault returned %d for %s", resp.StatusCode, path)
+	}
+
+	var result struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to parse vault response: %w", err)
+	}
+	data := result.Data
+	// KV v2 nests the secret under data.data.
+	if nested, ok := data["data"]; ok && len(data["metadata"]) > 0 {
+		data = nil
+		if err := json.Unmarshal(nested, &data); err != nil {
+			return "", fmt.Errorf("failed to parse vault KV v2 data: %w", err)
+		}
+	}
+	raw, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("field %q not found at %s", field, path)
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", fmt.Errorf("field %q at %s is not a string", field, path)
+	}
+	return value, nil
+}
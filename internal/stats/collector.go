@@ -0,0 +1,301 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package stats
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot is a point-in-time copy of the node's runtime statistics. It is the single representation
+// shared by every reader (the admin API's /stats endpoint and the Prometheus exporter).
+type Snapshot struct {
+	NodeID           string         `json:"node_id"`
+	PeerID           string         `json:"peer_id"`
+	Group            string         `json:"group,omitempty"`
+	StartedAt        time.Time      `json:"started_at"`
+	UptimeSeconds    int64          `json:"uptime_seconds"`
+	TasksReceived    uint64         `json:"tasks_received"`
+	PinsSucceeded    uint64         `json:"pins_succeeded"`
+	PinsFailed       uint64         `json:"pins_failed"`
+	PinsInFlight     int64          `json:"pins_in_flight"`
+	HeartbeatsOK     uint64         `json:"heartbeats_ok"`
+	HeartbeatsFailed uint64         `json:"heartbeats_failed"`
+	LastHeartbeatAt  time.Time      `json:"last_heartbeat_at"`
+	RepoSizeBytes    uint64         `json:"repo_size_bytes"`
+	PeersConnected   int            `json:"peers_connected"`
+	PeersByRegion    map[string]int `json:"peers_by_region"`
+	Reachability     string         `json:"reachability"`
+	GatewayStatus    string         `json:"gateway_status,omitempty"`
+	TasksPaused      bool           `json:"tasks_paused"`
+	TasksPausedWhy   string         `json:"tasks_paused_reason,omitempty"` // Active pause reasons, comma-separated
+	// TimeToFirstTaskSeconds is how long after task polling started the first pin task arrived; 0 until then.
+	TimeToFirstTaskSeconds float64 `json:"time_to_first_task_seconds,omitempty"`
+	// IPFSBreakers maps IPFS API endpoints (e.g. "pin/add") to their circuit breaker state.
+	IPFSBreakers map[string]string `json:"ipfs_breakers,omitempty"`
+	// TaskPhaseSeconds is the cumulative time processed pin tasks spent per phase (checks, pin, verify, report).
+	TaskPhaseSeconds map[string]float64 `json:"task_phase_seconds,omitempty"`
+	// ResourceLimitsExceeded lists IPFS resource manager scopes at their limit, e.g. "System.Conns (900/900)".
+	ResourceLimitsExceeded []string `json:"resource_limits_exceeded,omitempty"`
+	// PinsByStatus counts finished pin tasks by the status reported for them (pinned, failed, abandoned).
+	PinsByStatus map[string]uint64 `json:"pins_by_status,omitempty"`
+}
+
+// Collector accumulates runtime statistics from multiple goroutines. Counters are atomic; the remaining
+// fields are guarded by a mutex. All methods are safe for concurrent use.
+type Collector struct {
+	tasksReceived    atomic.Uint64
+	pinsSucceeded    atomic.Uint64
+	pinsFailed       atomic.Uint64
+	pinsInFlight     atomic.Int64
+	heartbeatsOK     atomic.Uint64
+	heartbeatsFailed atomic.Uint64
+	repoSizeBytes    atomic.Uint64
+
+	mu              sync.RWMutex
+	nodeID          string
+	peerID          string
+	group           string
+	startedAt       time.Time
+	lastHeartbeatAt time.Time
+	timeToFirstTask time.Duration
+	peersByRegion   map[string]int
+	reachability    string
+	gatewayStatus   string
+	pauseReasons    map[string]bool // Active reasons task acceptance is paused
+	rcmgrExceeded   []string
+	ipfsBreakers    map[string]string
+	taskTiming      TaskTiming        // Cumulative per-phase task time
+	pinsByStatus    map[string]uint64 // Finished pin tasks by reported status
+}
+
+// NewCollector creates an empty collector.
+func NewCollector() *Collector {
+	return &Collector{peersByRegion: make(map[string]int)}
+}
+
+// SetIdentity records the coordinator-assigned node ID, IPFS peer ID and start time.
+func (c *Collector) SetIdentity(nodeID, peerID string, startedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nodeID = nodeID
+	c.peerID = peerID
+	c.startedAt = startedAt
+}
+
+// SetGroup records the node group reported to the coordinator.
+func (c *Collector) SetGroup(group string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.group = group
+}
+
+// TaskReceived counts a pin task received from the coordinator.
+func (c *Collector) TaskReceived() { c.tasksReceived.Add(1) }
+
+// SetTimeToFirstTask records the delay between the start of task polling and the first pin task. Only the first call
+// has an effect.
+func (c *Collector) SetTimeToFirstTask(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timeToFirstTask == 0 {
+		c.timeToFirstTask = d
+	}
+}
+
+// PinStarted marks a pin as in flight.
+func (c *Collector) PinStarted() { c.pinsInFlight.Add(1) }
+
+// PinFinished marks an in-flight pin as done and counts its outcome.
+func (c *Collector) PinFinished(success bool) {
+	c.pinsInFlight.Add(-1)
+	if success {
+		c.pinsSucceeded.Add(1)
+	} else {
+		c.pinsFailed.Add(1)
+	}
+}
+
+// PinReported counts a finished pin task by the status reported for it, e.g. "pinned" or "abandoned".
+func (c *Collector) PinReported(status string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pinsByStatus == nil {
+		c.pinsByStatus = make(map[string]uint64)
+	}
+	c.pinsByStatus[status]++
+}
+
+// HeartbeatSent counts a heartbeat attempt and records the time of successful ones.
+func (c *Collector) HeartbeatSent(success bool) {
+	if !success {
+		c.heartbeatsFailed.Add(1)
+		return
+	}
+	c.heartbeatsOK.Add(1)
+	c.mu.Lock()
+	c.lastHeartbeatAt = time.Now()
+	c.mu.Unlock()
+}
+
+// LastHeartbeat returns the time of the last successful heartbeat; zero if none succeeded yet.
+func (c *Collector) LastHeartbeat() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.lastHeartbeatAt
+}
+
+// SetRepoSize records the latest IPFS repository size.
+func (c *Collector) SetRepoSize(bytes uint64) { c.repoSizeBytes.Store(bytes) }
+
+// SetPeersByRegion replaces the connected peer counts per region.
+func (c *Collector) SetPeersByRegion(counts map[string]int) {
+	copied := make(map[string]int, len(counts))
+	for region, n := range counts {
+		copied[region] = n
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.peersByRegion = copied
+}
+
+// SetReachability records the node's network reachability and returns the previous value.
+func (c *Collector) SetReachability(reachability string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.reachability
+	c.reachability = reachability
+	return previous
+}
+
+// Reachability returns the last recorded network reachability.
+func (c *Collector) Reachability() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.reachability
+}
+
+// SetGatewayStatus records the IPFS gateway self-check result and returns the previous one.
+func (c *Collector) SetGatewayStatus(status string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.gatewayStatus
+	c.gatewayStatus = status
+	return previous
+}
+
+// GatewayStatus returns the last recorded IPFS gateway self-check result.
+func (c *Collector) GatewayStatus() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.gatewayStatus
+}
+
+// PauseTasks records that task acceptance is paused for reason (the node is degraded). Tasks are
+// reported paused while any reason is active, so independent conditions can pause and resume without
+// clearing each other.
+func (c *Collector) PauseTasks(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pauseReasons == nil {
+		c.pauseReasons = make(map[string]bool)
+	}
+	c.pauseReasons[reason] = true
+}
+
+// ResumeTasks clears a reason recorded by PauseTasks.
+func (c *Collector) ResumeTasks(reason string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.pauseReasons, reason)
+}
+
+// SetResourceLimitsExceeded records the IPFS resource manager scopes currently at their limit and returns
+// the previously recorded set.
+func (c *Collector) SetResourceLimitsExceeded(scopes []string) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.rcmgrExceeded
+	c.rcmgrExceeded = append([]string(nil), scopes...)
+	return previous
+}
+
+// SetIPFSBreaker records the circuit breaker state of an IPFS API endpoint.
+func (c *Collector) SetIPFSBreaker(endpoint, state string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ipfsBreakers == nil {
+		c.ipfsBreakers = make(map[string]string)
+	}
+	c.ipfsBreakers[endpoint] = state
+}
+
+// TaskTimed adds a processed task's phase timing to the cumulative totals.
+func (c *Collector) TaskTimed(t TaskTiming) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.taskTiming.add(t)
+}
+
+// Snapshot returns a consistent copy of all statistics.
+func (c *Collector) Snapshot() Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	snap := Snapshot{
+		NodeID:           c.nodeID,
+		PeerID:           c.peerID,
+		Group:            c.group,
+		StartedAt:        c.startedAt,
+		TasksReceived:    c.tasksReceived.Load(),
+		PinsSucceeded:    c.pinsSucceeded.Load(),
+		PinsFailed:       c.pinsFailed.Load(),
+		PinsInFlight:     c.pinsInFlight.Load(),
+		HeartbeatsOK:     c.heartbeatsOK.Load(),
+		HeartbeatsFailed: c.heartbeatsFailed.Load(),
+		LastHeartbeatAt:  c.lastHeartbeatAt,
+		RepoSizeBytes:    c.repoSizeBytes.Load(),
+		Reachability:     c.reachability,
+		GatewayStatus:    c.gatewayStatus,
+		TasksPaused:      len(c.pauseReasons) > 0,
+
+		TimeToFirstTaskSeconds: c.timeToFirstTask.Seconds(),
+		ResourceLimitsExceeded: append([]string(nil), c.rcmgrExceeded...),
+		PeersByRegion:          make(map[string]int, len(c.peersByRegion)),
+	}
+	if !c.startedAt.IsZero() {
+		snap.UptimeSeconds = int64(time.Since(c.startedAt).Seconds())
+	}
+	if len(c.ipfsBreakers) > 0 {
+		snap.IPFSBreakers = make(map[string]string, len(c.ipfsBreakers))
+		for endpoint, state := range c.ipfsBreakers {
+			snap.IPFSBreakers[endpoint] = state
+		}
+	}
+	if len(c.pauseReasons) > 0 {
+		reasons := make([]string, 0, len(c.pauseReasons))
+		for reason := range c.pauseReasons {
+			reasons = append(reasons, reason)
+		}
+		sort.Strings(reasons)
+		snap.TasksPausedWhy = strings.Join(reasons, ", ")
+	}
+	if len(c.pinsByStatus) > 0 {
+		snap.PinsByStatus = make(map[string]uint64, len(c.pinsByStatus))
+		for status, n := range c.pinsByStatus {
+			snap.PinsByStatus[status] = n
+		}
+	}
+	if c.taskTiming.Total() > 0 {
+		snap.TaskPhaseSeconds = c.taskTiming.Phases()
+	}
+	for region, n := range c.peersByRegion {
+		snap.PeersByRegion[region] = n
+		snap.PeersConnected += n
+	}
+	return snap
+}
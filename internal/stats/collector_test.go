@@ -0,0 +1,26 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package stats
+
+import "testing"
+
+func TestPauseReasons(t *testing.T) {
+	c := NewCollector()
+	c.PauseTasks("insufficient swarm peers")
+	c.PauseTasks("IPFS daemon down")
+	if s := c.Snapshot(); !s.TasksPaused || s.TasksPausedWhy != "IPFS daemon down, insufficient swarm peers" {
+		t.Fatalf("paused = %v, reason %q; want both reasons", s.TasksPaused, s.TasksPausedWhy)
+	}
+
+	// The daemon restarting must not clear the swarm gate's pause.
+	c.ResumeTasks("IPFS daemon down")
+	if s := c.Snapshot(); !s.TasksPaused || s.TasksPausedWhy != "insufficient swarm peers" {
+		t.Fatalf("paused = %v, reason %q; want paused for insufficient swarm peers", s.TasksPaused, s.TasksPausedWhy)
+	}
+
+	c.ResumeTasks("insufficient swarm peers")
+	if s := c.Snapshot(); s.TasksPaused || s.TasksPausedWhy != "" {
+		t.Fatalf("paused = %v, reason %q; want resumed", s.TasksPaused, s.TasksPausedWhy)
+	}
+}
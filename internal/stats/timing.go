@@ -0,0 +1,71 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package stats
+
+import (
+	"log/slog"
+	"time"
+)
+
+// TaskTiming is the time a pin task spent in each phase of processing, to tell slow content discovery
+// and transfer apart from local work and slow coordinator reports.
+type TaskTiming struct {
+	Checks time.Duration // Blocklist, acceptance and attempt-limit checks
+	Pin    time.Duration // Pinning, including the already-pinned check: block discovery and transfer
+	Verify time.Duration // Post-pin size lookup and inventory update, or failure diagnostics
+	Report time.Duration // Status report round trip to the coordinator
+
+	last time.Time
+}
+
+// now is the clock used by StartTaskTiming and Lap; tests replace it.
+var now = time.Now
+
+// StartTaskTiming returns a timing whose first Lap measures from now.
+func StartTaskTiming() TaskTiming {
+	return TaskTiming{last: now()}
+}
+
+// Lap returns the time since the previous Lap (or StartTaskTiming) and starts the next phase, e.g.
+// t.Pin = t.Lap().
+func (t *TaskTiming) Lap() time.Duration {
+	at := now()
+	d := at.Sub(t.last)
+	t.last = at
+	return d
+}
+
+// Total returns the sum of all phases.
+func (t TaskTiming) Total() time.Duration {
+	return t.Checks + t.Pin + t.Verify + t.Report
+}
+
+// Phases returns the phase durations in seconds keyed by phase name.
+func (t TaskTiming) Phases() map[string]float64 {
+	return map[string]float64{
+		"checks": t.Checks.Seconds(),
+		"pin":    t.Pin.Seconds(),
+		"verify": t.Verify.Seconds(),
+		"report": t.Report.Seconds(),
+	}
+}
+
+// LogValue logs the phases in milliseconds.
+func (t TaskTiming) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int64("checks_ms", t.Checks.Milliseconds()),
+		slog.Int64("pin_ms", t.Pin.Milliseconds()),
+		slog.Int64("verify_ms", t.Verify.Milliseconds()),
+		slog.Int64("report_ms", t.Report.Milliseconds()),
+		slog.Int64("total_ms", t.Total().Milliseconds()),
+	)
+}
+
+// add accumulates o's phases into t.
+func (t *TaskTiming) add(o TaskTiming) {
+	t.Checks += o.Checks
+	t.Pin += o.Pin
+	t.Verify += o.Verify
+	t.Report += o.Report
+}
@@ -0,0 +1,71 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package stats
+
+import (
+	"maps"
+	"testing"
+	"time"
+)
+
+// fakeClock replaces the package clock for the duration of the test and returns a function that
+// advances it.
+func fakeClock(t *testing.T) (advance func(time.Duration)) {
+	t.Helper()
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	orig := now
+	now = func() time.Time { return at }
+	t.Cleanup(func() { now = orig })
+	return func(d time.Duration) { at = at.Add(d) }
+}
+
+func TestTaskTimingLap(t *testing.T) {
+	advance := fakeClock(t)
+	timing := StartTaskTiming()
+
+	advance(10 * time.Millisecond)
+	timing.Checks = timing.Lap()
+	advance(2 * time.Second)
+	timing.Pin = timing.Lap()
+	timing.Verify = timing.Lap() // no time passed
+	advance(300 * time.Millisecond)
+	timing.Report = timing.Lap()
+
+	want := TaskTiming{Checks: 10 * time.Millisecond, Pin: 2 * time.Second, Report: 300 * time.Millisecond}
+	if timing.Checks != want.Checks || timing.Pin != want.Pin || timing.Verify != want.Verify || timing.Report != want.Report {
+		t.Errorf("phases = %+v, want %+v", timing, want)
+	}
+	if got := timing.Total(); got != 2310*time.Millisecond {
+		t.Errorf("Total() = %v, want 2.31s", got)
+	}
+}
+
+func TestTaskTimingPhases(t *testing.T) {
+	timing := TaskTiming{Checks: 250 * time.Millisecond, Pin: 3 * time.Second, Verify: time.Second, Report: 500 * time.Millisecond}
+	want := map[string]float64{"checks": 0.25, "pin": 3, "verify": 1, "report": 0.5}
+	if got := timing.Phases(); !maps.Equal(got, want) {
+		t.Errorf("Phases() = %v, want %v", got, want)
+	}
+	if got := (TaskTiming{}).Total(); got != 0 {
+		t.Errorf("zero timing Total() = %v, want 0", got)
+	}
+}
+
+func TestTaskTimingAdd(t *testing.T) {
+	var sum TaskTiming
+	sum.add(TaskTiming{Checks: time.Millisecond, Pin: time.Second, Verify: 2 * time.Millisecond, Report: 3 * time.Millisecond})
+	sum.add(TaskTiming{Checks: time.Millisecond, Pin: 4 * time.Second, Report: 7 * time.Millisecond})
+
+	want := TaskTiming{Checks: 2 * time.Millisecond, Pin: 5 * time.Second, Verify: 2 * time.Millisecond, Report: 10 * time.Millisecond}
+	if sum != want {
+		t.Errorf("sum = %+v, want %+v", sum, want)
+	}
+
+	c := NewCollector()
+	c.TaskTimed(sum)
+	c.TaskTimed(TaskTiming{Pin: time.Second})
+	if got := c.Snapshot().TaskPhaseSeconds["pin"]; got != 6 {
+		t.Errorf("snapshot pin seconds = %v, want 6", got)
+	}
+}
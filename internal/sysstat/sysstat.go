@@ -0,0 +1,16 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package sysstat reports host CPU load and memory usage in a platform-independent way.
+package sysstat
+
+import "errors"
+
+// ErrUnsupported is returned on platforms where a statistic is not implemented.
+var ErrUnsupported = errors.New("system statistic not supported on this platform")
+
+// Memory holds host memory usage in bytes.
+type Memory struct {
+	Total     uint64 // Physical memory
+	Available uint64 // Memory available for new allocations without swapping
+}
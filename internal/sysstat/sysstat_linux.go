@@ -0,0 +1,67 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+//go:build linux
+
+package sysstat
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadAverage returns the one-minute load average from /proc/loadavg.
+func LoadAverage() (float64, error) {
+	data, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("empty /proc/loadavg")
+	}
+	return strconv.ParseFloat(fields[0], 64)
+}
+
+// ReadMemory returns the total and available memory from /proc/meminfo.
+func ReadMemory() (Memory, error) {
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return Memory{}, err
+	}
+	defer f.Close()
+
+	var mem Memory
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// Lines look like "MemTotal:       16318360 kB".
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		var dst *uint64
+		switch fields[0] {
+		case "MemTotal:":
+			dst = &mem.Total
+		case "MemAvailable:":
+			dst = &mem.Available
+		default:
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return Memory{}, fmt.Errorf("parse /proc/meminfo %s: %w", fields[0], err)
+		}
+		*dst = kb * 1024
+	}
+	if err := scanner.Err(); err != nil {
+		return Memory{}, err
+	}
+	if mem.Total == 0 {
+		return Memory{}, fmt.Errorf("MemTotal missing from /proc/meminfo")
+	}
+	return mem, nil
+}
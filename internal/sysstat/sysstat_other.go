@@ -0,0 +1,16 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+//go:build !linux
+
+package sysstat
+
+// LoadAverage is only implemented on Linux; elsewhere it returns ErrUnsupported.
+func LoadAverage() (float64, error) {
+	return 0, ErrUnsupported
+}
+
+// ReadMemory is only implemented on Linux; elsewhere it returns ErrUnsupported.
+func ReadMemory() (Memory, error) {
+	return Memory{}, ErrUnsupported
+}
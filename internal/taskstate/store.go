@@ -0,0 +1,157 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package taskstate persists the state of pin tasks (the last status reported for each, or that a pin
+// was started) so a restarted node knows what it already did.
+package taskstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wabisaby/wabisaby-node/internal/fsutil"
+)
+
+// StatusPinning marks a task whose pin was started but not yet reported. Other statuses are the names of
+// the pin statuses reported to the coordinator.
+const StatusPinning = "pinning"
+
+// maxEntryAge is how long an entry is kept after its last update.
+const maxEntryAge = 7 * 24 * time.Hour
+
+// Entry is the recorded state of one pin task.
+type Entry struct {
+	TaskID    string    `json:"task_id"`
+	CID       string    `json:"cid"`
+	Status    string    `json:"status"` // StatusPinning or the last reported pin status
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Store records pin task state.
+type Store interface {
+	// Get returns the entry for taskID, if any.
+	Get(taskID string) (Entry, bool)
+	// Put records e, replacing any entry for the same task.
+	Put(e Entry) error
+	// Delete removes the entry for taskID, if any.
+	Delete(taskID string) error
+	// Entries returns every entry, ordered by task ID.
+	Entries() []Entry
+}
+
+// FileStore is a Store kept in a JSON file. Every change is written through atomically; entries not
+// updated for maxEntryAge are dropped so the file does not grow without bound.
+type FileStore struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]Entry
+	now     func() time.Time
+}
+
+var _ Store = (*FileStore)(nil)
+
+// Open loads the store at path. A missing file yields an empty store; an empty path yields an in-memory
+// store that is never persisted.
+func Open(path string) (*FileStore, error) {
+	s := &FileStore{path: path, entries: make(map[string]Entry), now: time.Now}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read task state: %w", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse task state %s: %w", path, err)
+	}
+	for _, e := range entries {
+		s.entries[e.TaskID] = e
+	}
+	s.pruneLocked()
+	return s, nil
+}
+
+// Get returns the entry for taskID, if any.
+func (s *FileStore) Get(taskID string) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[taskID]
+	return e, ok
+}
+
+// Put records e, stamping UpdatedAt when unset, and persists the store.
+func (s *FileStore) Put(e Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e.UpdatedAt.IsZero() {
+		e.UpdatedAt = s.now()
+	}
+	s.entries[e.TaskID] = e
+	s.pruneLocked()
+	return s.saveLocked()
+}
+
+// Delete removes the entry for taskID, if any, and persists the store.
+func (s *FileStore) Delete(taskID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.entries[taskID]; !ok {
+		return nil
+	}
+	delete(s.entries, taskID)
+	return s.saveLocked()
+}
+
+// Entries returns every entry, ordered by task ID.
+func (s *FileStore) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedLocked()
+}
+
+func (s *FileStore) sortedLocked() []Entry {
+	out := make([]Entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].TaskID < out[j].TaskID })
+	return out
+}
+
+// pruneLocked drops entries not updated for maxEntryAge. s.mu must be held.
+func (s *FileStore) pruneLocked() {
+	cutoff := s.now().Add(-maxEntryAge)
+	for id, e := range s.entries {
+		if e.UpdatedAt.Before(cutoff) {
+			delete(s.entries, id)
+		}
+	}
+}
+
+// saveLocked writes the store to its file. s.mu must be held.
+func (s *FileStore) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(s.sortedLocked(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode task state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("create task state directory: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("write task state: %w", err)
+	}
+	return nil
+}
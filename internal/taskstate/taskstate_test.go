@@ -0,0 +1,60 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+package taskstate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenTruncatedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Put(Entry{TaskID: "task-1", CID: "bafy1", Status: StatusPinning}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[:len(data)/2], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path); err == nil {
+		t.Fatal("Open accepted a truncated task state file")
+	}
+}
+
+func TestPutLeavesNoTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(filepath.Join(dir, "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"task-1", "task-2"} {
+		if err := s.Put(Entry{TaskID: id, CID: "bafy1", Status: StatusPinning}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("directory holds %d files, want only tasks.json", len(entries))
+	}
+
+	reopened, err := Open(filepath.Join(dir, "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(reopened.Entries()); got != 2 {
+		t.Fatalf("reopened store has %d entries, want 2", got)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2026 WabiSaby
+// SPDX-License-Identifier: MIT
+
+// Package tlsconfig builds hardened *tls.Config values shared by the coordinator client and the node's
+// own network-facing endpoints.
+package tlsconfig
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Options holds operator-configurable TLS parameters.
+type Options struct {
+	MinVersion   string   // "1.2" (default) or "1.3"
+	CipherSuites []string // Optional allowlist of IANA cipher suite names; Go defaults if empty
+
+	CAFile     string // PEM CA bundle used to verify the peer instead of the system roots (optional)
+	CertFile   string // PEM certificate presented to the peer: client certificate for mutual TLS, or a server's certificate (optional; requires KeyFile)
+	KeyFile    string // PEM private key for CertFile
+	ServerName string // Overrides the host name the server certificate is verified against (optional)
+}
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Build returns a *tls.Config enforcing opts. Minimum versions below TLS 1.2 and cipher suites that Go
+// classifies as insecure are rejected.
+func Build(opts Options) (*tls.Config, error) {
+	minVersion := uint16(tls.VersionTLS12)
+	if opts.MinVersion != "" {
+		v, ok := versions[strings.TrimPrefix(strings.ToLower(opts.MinVersion), "tls")]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS min_version %q (use 1.2 or 1.3)", opts.MinVersion)
+		}
+		if v < tls.VersionTLS12 {
+			return nil, fmt.Errorf("TLS min_version %s is too weak; 1.2 or higher is required", opts.MinVersion)
+		}
+		minVersion = v
+	}
+
+	cfg := &tls.Config{MinVersion: minVersion, ServerName: opts.ServerName}
+	if err := loadFiles(cfg, opts); err != nil {
+		return nil, err
+	}
+	if len(opts.CipherSuites) == 0 {
+		return cfg, nil
+	}
+
+	secure := make(map[string]uint16)
+	for _, cs := range tls.CipherSuites() {
+		secure[cs.Name] = cs.ID
+	}
+	insecure := make(map[string]bool)
+	for _, cs := range tls.InsecureCipherSuites() {
+		insecure[cs.Name] = true
+	}
+	for _, name := range opts.CipherSuites {
+		if insecure[name] {
+			return nil, fmt.Errorf("TLS cipher suite %s is insecure", name)
+		}
+		id, ok := secure[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown TLS cipher suite %q", name)
+		}
+		cfg.CipherSuites = append(cfg.CipherSuites, id)
+	}
+	// Cipher suites only apply up to TLS 1.2; TLS 1.3 suites are not configurable in Go.
+	return cfg, nil
+}
+
+// loadFiles adds the CA bundle and key pair named in opts to cfg.
+func loadFiles(cfg *tls.Config, opts Options) error {
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return fmt.Errorf("read TLS CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("TLS CA file %s contains no PEM certificates", opts.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return fmt.Errorf("TLS certificate and key must be set together")
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return fmt.Errorf("load TLS certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return nil
+}